@@ -0,0 +1,53 @@
+package rqe
+
+// Expr is a node of a parsed filter, either a *Condition, a *Logical, a *Not, a *Tuple or an *AsOf
+type Expr interface {
+	expr()
+}
+
+// Condition compares a column against one or more values, e.g. `age gte 25`
+type Condition struct {
+	Column string
+	// Operator is the canonical operation keyword (`eq`, `gte` ... etc) even if an alias was used
+	Operator string
+	// Values are the resolved values, a single nil value means the `null` keyword was used
+	Values []any
+	Line   int
+	Pos    int
+}
+
+// Logical joins two or more expressions with `and` / `or`
+type Logical struct {
+	Operator string
+	Exprs    []Expr
+}
+
+// Not negates an expression, e.g. `not (a eq 1 or b gt 2)`
+type Not struct {
+	Expr Expr
+}
+
+func (*Condition) expr() {}
+func (*Logical) expr()   {}
+func (*Not) expr()       {}
+
+// IsNull reports whether the condition compares against the `null` keyword
+func (c *Condition) IsNull() bool {
+	return len(c.Values) == 1 && c.Values[0] == nil
+}
+
+// Walk calls fn for every condition of the expression from left to right
+func Walk(expr Expr, fn func(c *Condition)) {
+	switch e := expr.(type) {
+	case *Condition:
+		fn(e)
+	case *Logical:
+		for _, child := range e.Exprs {
+			Walk(child, fn)
+		}
+	case *Not:
+		Walk(e.Expr, fn)
+	case *Tuple:
+		Walk(e.Expanded, fn)
+	}
+}
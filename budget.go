@@ -0,0 +1,84 @@
+package rqe
+
+import (
+	"time"
+
+	"github.com/bzick/tokenizer"
+)
+
+// Approximate costs in bytes of what parsing a filter allocates, they do not need to be exact
+// to stop pathological inputs
+const (
+	tokenCost     = 128 // a lexed token, its value and list pointers
+	conditionCost = 96  // a Condition node and its values slice
+	valueCost     = 16  // an interface value
+)
+
+// memoryBudget accounts the approximate memory used while parsing one filter, see WithMemoryBudget.
+// A nil budget never runs out.
+type memoryBudget struct {
+	limit int
+	used  int
+}
+
+// newBudget returns the budget of one parse, nil when the parser has none
+func (p *Parser) newBudget() *memoryBudget {
+	if p.memoryBudget <= 0 {
+		return nil
+	}
+	return &memoryBudget{limit: p.memoryBudget}
+}
+
+// charge adds n bytes, failing once the budget is exceeded
+func (b *memoryBudget) charge(n int) error {
+	if b == nil {
+		return nil
+	}
+	b.used += n
+	if b.used > b.limit {
+		return MemoryBudgetError{Limit: b.limit, Used: b.used}
+	}
+	return nil
+}
+
+// chargeTokens charges the filter and its tokens, the stream must be on its first token and is left there
+func (b *memoryBudget) chargeTokens(filter string, stream *tokenizer.Stream) error {
+	if b == nil {
+		return nil
+	}
+	// stop on the last token, the stream cannot go back once past the end
+	head := stream.CurrentToken().ID()
+	for stream.NextToken().IsValid() {
+		stream.GoNext()
+	}
+	tokens := stream.CurrentToken().ID() - head + 1
+	stream.GoTo(head)
+	return b.charge(len(filter) + tokens*tokenCost)
+}
+
+// chargeCondition charges a condition and its values
+func (b *memoryBudget) chargeCondition(vals []any) error {
+	n := conditionCost
+	for _, v := range vals {
+		n += valueSize(v)
+	}
+	return b.charge(n)
+}
+
+// valueSize approximates the memory held by a decoded value
+func valueSize(v any) int {
+	switch val := v.(type) {
+	case string:
+		return valueCost + len(val)
+	case []any:
+		n := valueCost + 8
+		for _, elem := range val {
+			n += valueSize(elem)
+		}
+		return n
+	case time.Time:
+		return valueCost + 24
+	default:
+		return valueCost + 8
+	}
+}
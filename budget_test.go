@@ -0,0 +1,36 @@
+package rqe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	parser := NewParser(WithMemoryBudget(4096))
+
+	_, err := parser.Parse(`name eq "john" and age in [1, 2, 3]`, validateColumn)
+	assert.NoError(t, err)
+
+	long := `name eq "` + strings.Repeat("x", 4096) + `"`
+	_, err = parser.Parse(long, validateColumn)
+	assert.IsType(t, MemoryBudgetError{}, err)
+
+	many := strings.Repeat(`age eq 1 or `, 100) + `age eq 1`
+	_, err = parser.Parse(many, validateColumn)
+	assert.IsType(t, MemoryBudgetError{}, err)
+	_, err = NewParser().Parse(many, validateColumn)
+	assert.NoError(t, err)
+
+	// values decoded from a single token are charged as well
+	array := `tags in [` + strings.Repeat(`"aaaaaaaa", `, 150) + `"a"]`
+	_, err = NewParser(WithMemoryBudget(len(array)+2*tokenCost+1024)).Parse(array, validateColumn)
+	assert.IsType(t, MemoryBudgetError{}, err)
+
+	_, err = parser.ParseOData(strings.Repeat(`age eq 1 or `, 100)+`age eq 1`, validateColumn)
+	assert.IsType(t, MemoryBudgetError{}, err)
+
+	_, err = NewParser(WithMemoryBudget(64)).ParseJSON([]byte(`{"name": "`+strings.Repeat("x", 64)+`"}`), validateColumn)
+	assert.IsType(t, MemoryBudgetError{}, err)
+}
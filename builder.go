@@ -0,0 +1,276 @@
+package rqe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sort is a single ORDER BY entry
+type Sort struct {
+	Column string
+	Desc   bool
+}
+
+// BuiltQuery is the result of a Builder, every part is ready to be embedded in a SELECT
+type BuiltQuery struct {
+	// From is the quoted table or view, empty unless set with Builder.From
+	From string
+	// Where is the combined condition of all filters and server side conditions
+	Where string
+	Args  []interface{}
+	// ArgInfo describes each argument of Args, at the same index
+	ArgInfo []ArgInfo
+	// OrderBy is the ORDER BY list without the keyword (e.g. `name ASC, age DESC`)
+	OrderBy string
+	Limit   int
+	Offset  int
+	// Columns referenced by the client filters
+	Columns []string
+	// Fields is the validated projection as SQL columns, empty when none was requested
+	Fields []string
+	// IndexHint is the `USE INDEX (...)` clause of the column dominating the filters,
+	// empty unless the parser has index hints (see WithIndexHint)
+	IndexHint string
+	// NamedArgs are the arguments by parameter name when WithNamedArgs is used,
+	// arguments of server side conditions are named `arg_N`
+	NamedArgs map[string]interface{}
+	// Having is the combined HAVING clause of the filters' conditions on aggregate columns,
+	// see ParsedQuery.Having. HavingArgs are its arguments, also the last ones of Args.
+	Having     string
+	HavingArgs []interface{}
+	// AsOf is the point in time requested with `asof(...)` by the filters on a system versioned
+	// table, see ParsedQuery.AsOf. nil when not requested.
+	AsOf *time.Time
+}
+
+// Builder collects filter fragments, server side conditions, sorting and pagination
+// in any order. Nothing is validated until Finish is called.
+type Builder struct {
+	parser      *Parser
+	ctx         context.Context
+	validateCol func(col string) bool
+
+	table            string
+	filters          []string
+	conditions       []ParsedQuery
+	fragments        []string
+	sorts            []Sort
+	fields           []string
+	limit            int
+	offset           int
+	sortFilteredOnly bool
+}
+
+// Begin starts building a query incrementally, validateCol is used for filters and sort columns
+func (p *Parser) Begin(validateCol func(col string) bool) *Builder {
+	return p.BeginContext(context.Background(), validateCol)
+}
+
+// BeginContext is Begin parsing the filters with the request's context, which the scopes
+// (see WithScopes) are built from. The scopes are ANDed once to the query, filters or not.
+func (p *Parser) BeginContext(ctx context.Context, validateCol func(col string) bool) *Builder {
+	return &Builder{parser: p, ctx: ctx, validateCol: validateCol}
+}
+
+// From sets the table or view to select from, checked with the parser's table validator
+// (see WithTableValidator) and quoted like the columns
+func (b *Builder) From(table string) *Builder {
+	b.table = table
+	return b
+}
+
+// Filter adds a client supplied filter fragment, fragments are ANDed together
+func (b *Builder) Filter(filter string) *Builder {
+	b.filters = append(b.filters, filter)
+	return b
+}
+
+// Where adds a trusted server side condition with `?` placeholders, they are rewritten
+// with the rest of the query for the parser's dialect. The SQL must never contain client input.
+func (b *Builder) Where(sql string, args ...interface{}) *Builder {
+	b.conditions = append(b.conditions, ParsedQuery{SQL: sql, Args: args})
+	return b
+}
+
+// Fragment adds server side conditions registered on the parser with WithFragment
+func (b *Builder) Fragment(names ...string) *Builder {
+	b.fragments = append(b.fragments, names...)
+	return b
+}
+
+// OrderBy adds a sort column
+func (b *Builder) OrderBy(col string, desc bool) *Builder {
+	b.sorts = append(b.sorts, Sort{Column: col, Desc: desc})
+	return b
+}
+
+// Select adds projected columns
+func (b *Builder) Select(fields ...string) *Builder {
+	b.fields = append(b.fields, fields...)
+	return b
+}
+
+// Page sets the pagination, a limit of 0 means no limit
+func (b *Builder) Page(limit, offset int) *Builder {
+	b.limit, b.offset = limit, offset
+	return b
+}
+
+// SortOnFilteredOnly only allows sorting on columns the client filters also reference
+func (b *Builder) SortOnFilteredOnly() *Builder {
+	b.sortFilteredOnly = true
+	return b
+}
+
+// Finish parses the filter fragments and validates the cross cutting constraints
+func (b *Builder) Finish() (BuiltQuery, error) {
+	out := BuiltQuery{Args: make([]interface{}, 0), ArgInfo: make([]ArgInfo, 0), Columns: make([]string, 0), Limit: b.limit, Offset: b.offset}
+	parts := make([]string, 0, len(b.conditions)+len(b.filters))
+	if b.table != "" {
+		table, err := b.parser.table(b.table)
+		if err != nil {
+			return BuiltQuery{}, err
+		}
+		out.From = table
+	}
+	for _, c := range b.conditions {
+		parts = append(parts, fmt.Sprintf("(%s)", c.SQL))
+		out.Args = append(out.Args, c.Args...)
+		for _, arg := range c.Args {
+			out.ArgInfo = append(out.ArgInfo, ArgInfo{GoType: reflect.TypeOf(arg)})
+		}
+	}
+
+	// server side fragments and scopes are trusted, they skip the rules and the hardened checks
+	server := make([]Expr, 0, 2)
+	if len(b.fragments) > 0 {
+		expr, err := b.parser.AndFragments(nil, b.fragments...)
+		if err != nil {
+			return BuiltQuery{}, err
+		}
+		server = append(server, expr)
+	}
+	scope, err := b.parser.applyScopes(b.ctx, nil)
+	if err != nil {
+		return BuiltQuery{}, err
+	}
+	if scope != nil {
+		server = append(server, scope)
+	}
+
+	// the rules apply to the filters as a whole, a condition may be split across fragments
+	filtered := &Logical{Operator: "and"}
+	conditions := b.parser.newConditionCounter()
+	for _, filter := range b.filters {
+		expr, err := b.parser.parseFilter(b.ctx, filter, b.validateCol, conditions)
+		if err != nil {
+			return BuiltQuery{}, err
+		}
+		if expr != nil {
+			filtered.Exprs = append(filtered.Exprs, expr)
+		}
+	}
+	if _, err := b.parser.checked(b.ctx, andExprs(filtered.Exprs)); err != nil {
+		return BuiltQuery{}, err
+	}
+
+	// every expression is split between WHERE and HAVING the same way, whoever wrote it
+	having := make([]string, 0)
+	havingInfo := make([]ArgInfo, 0)
+	out.HavingArgs = make([]interface{}, 0)
+	add := func(q ParsedQuery) error {
+		if q.AsOf != nil && out.AsOf != nil {
+			return UnexpectedTokenError{Token: asOfKeyword + " can only be used once"}
+		}
+		if q.AsOf != nil {
+			out.AsOf = q.AsOf
+		}
+		whereArgs := len(q.Args) - len(q.HavingArgs)
+		if q.SQL != "" {
+			parts = append(parts, fmt.Sprintf("(%s)", q.SQL))
+		}
+		out.Args = append(out.Args, q.Args[:whereArgs]...)
+		out.ArgInfo = append(out.ArgInfo, q.ArgInfo[:whereArgs]...)
+		if q.Having != "" {
+			having = append(having, fmt.Sprintf("(%s)", q.Having))
+			out.HavingArgs = append(out.HavingArgs, q.HavingArgs...)
+			havingInfo = append(havingInfo, q.ArgInfo[whereArgs:]...)
+		}
+		return nil
+	}
+	for _, expr := range server {
+		if err := add(b.parser.compile(expr)); err != nil {
+			return BuiltQuery{}, err
+		}
+	}
+	for _, expr := range filtered.Exprs {
+		q := b.parser.compile(expr)
+		if b.parser.hardened {
+			if err := b.parser.assertSafe(q, expr); err != nil {
+				return BuiltQuery{}, err
+			}
+		}
+		if err := add(q); err != nil {
+			return BuiltQuery{}, err
+		}
+		for _, col := range q.Columns {
+			if !slices.Contains(out.Columns, col) {
+				out.Columns = append(out.Columns, col)
+			}
+		}
+	}
+	out.Args = append(out.Args, out.HavingArgs...)
+	out.ArgInfo = append(out.ArgInfo, havingInfo...)
+	out.Where, out.Having, out.NamedArgs = b.parser.bindHaving(strings.Join(parts, " AND "), strings.Join(having, " AND "), out.Args, out.ArgInfo)
+	if hint, ok := b.parser.IndexHintFor(filtered); ok {
+		out.IndexHint = hint.String()
+	}
+
+	orderBy := make([]string, 0, len(b.sorts))
+	for _, s := range b.sorts {
+		if !b.allowed(s.Column, Sortable) {
+			return BuiltQuery{}, SortColumnError{Column: s.Column, Reason: "column is not allowed"}
+		}
+		if b.sortFilteredOnly && !slices.Contains(out.Columns, s.Column) {
+			return BuiltQuery{}, SortColumnError{Column: s.Column, Reason: "column must also be filtered on"}
+		}
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", b.parser.column(s.Column), dir))
+	}
+	out.OrderBy = strings.Join(orderBy, ", ")
+
+	out.Fields = make([]string, 0, len(b.fields))
+	for _, field := range b.fields {
+		if !b.allowed(field, Projectable) {
+			return BuiltQuery{}, FieldColumnError{Column: field, Reason: "column is not allowed"}
+		}
+		if b.parser.schema[field].Expression != "" {
+			out.Fields = append(out.Fields, fmt.Sprintf("%s AS %s", b.parser.column(field), b.parser.dialect.ident(field)))
+			continue
+		}
+		out.Fields = append(out.Fields, b.parser.column(field))
+	}
+
+	if b.limit < 0 || b.offset < 0 {
+		return BuiltQuery{}, InvalidPaginationError{Limit: b.limit, Offset: b.offset}
+	}
+	return out, nil
+}
+
+// allowed checks a sort or projected column against the parser's schema and validateCol
+func (b *Builder) allowed(col string, capability ColumnCapability) bool {
+	if b.parser.denied(col) {
+		return false
+	}
+	if b.parser.schema != nil {
+		return b.parser.schema.Can(col, capability) && (b.validateCol == nil || b.validateCol(col))
+	}
+	return b.validateCol(col)
+}
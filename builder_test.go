@@ -0,0 +1,70 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	q, err := NewParser().Begin(validateColumn).
+		OrderBy("age", true).
+		Filter(`age gte 18`).
+		Page(10, 20).
+		Where("tenant_id = ?", 7).
+		Filter(`name eq "john" or name eq "jane"`).
+		Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = ?) AND (age >= ?) AND (name = ? or name = ?)", q.Where)
+	assert.Equal(t, []interface{}{7, int64(18), "john", "jane"}, q.Args)
+	assert.Equal(t, "age DESC", q.OrderBy)
+	assert.Equal(t, []string{"age", "name"}, q.Columns)
+	assert.Equal(t, 10, q.Limit)
+	assert.Equal(t, 20, q.Offset)
+}
+
+func TestBuilderConstraints(t *testing.T) {
+	_, err := NewParser().Begin(validateColumn).SortOnFilteredOnly().OrderBy("age", false).Filter(`name eq "x"`).Finish()
+	assert.IsType(t, SortColumnError{}, err)
+
+	_, err = NewParser().Begin(func(col string) bool { return col != "secret" }).OrderBy("secret", false).Finish()
+	assert.IsType(t, SortColumnError{}, err)
+
+	_, err = NewParser().Begin(validateColumn).Page(-1, 0).Finish()
+	assert.IsType(t, InvalidPaginationError{}, err)
+
+	_, err = NewParser().Begin(validateColumn).Filter(`age gte`).Finish()
+	assert.IsType(t, MissingValueError{}, err)
+}
+
+func TestBuilderIndexHint(t *testing.T) {
+	parser := NewParser(
+		WithIndexHint("created_at", "idx_created_at", false),
+		WithIndexHint("email", "idx_email", true),
+	)
+
+	q, err := parser.Begin(validateColumn).
+		Filter(`created_at gte "2024-01-01" and name eq "x"`).
+		Filter(`email eq "a@b.c"`).
+		Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "FORCE INDEX (idx_email)", q.IndexHint)
+
+	q, err = parser.Begin(validateColumn).Filter(`created_at gte "2024-01-01" and email eq null`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "USE INDEX (idx_created_at)", q.IndexHint)
+
+	for _, filter := range []string{`name eq "x"`, `created_at gte "2024-01-01" or name eq "x"`} {
+		q, err = parser.Begin(validateColumn).Filter(filter).Finish()
+		assert.NoError(t, err)
+		assert.Empty(t, q.IndexHint, filter)
+	}
+
+	// only MySQL style databases understand the hint
+	q, err = NewParser(Postgres, WithIndexHint("email", "idx_email", false)).Begin(validateColumn).Filter(`email eq "a@b.c"`).Finish()
+	assert.NoError(t, err)
+	assert.Empty(t, q.IndexHint)
+	q, err = NewParser(MySQL, WithIndexHint("email", "idx_email", false)).Begin(validateColumn).Filter(`email eq "a@b.c"`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "USE INDEX (idx_email)", q.IndexHint)
+}
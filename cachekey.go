@@ -0,0 +1,82 @@
+package rqe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CacheKeyRequest is the logical query a cache key is derived from
+type CacheKeyRequest struct {
+	Filter Expr
+	Sort   []Sort
+	Limit  int
+	Offset int
+	// Fields is the projection, order does not matter
+	Fields []string
+}
+
+// CacheKey returns a stable key for the logical query so caching layers do not depend
+// on the raw url. Filters that only differ in operand order or grouping of the same
+// logical operation share a key.
+func CacheKey(req CacheKeyRequest) string {
+	fields := slices.Clone(req.Fields)
+	slices.Sort(fields)
+
+	sorts := make([]string, 0, len(req.Sort))
+	for _, s := range req.Sort {
+		dir := "asc"
+		if s.Desc {
+			dir = "desc"
+		}
+		sorts = append(sorts, s.Column+" "+dir)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "filter:%s\nsort:%s\nlimit:%d\noffset:%d\nfields:%s",
+		Canonical(req.Filter), strings.Join(sorts, ","), req.Limit, req.Offset, strings.Join(fields, ","))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Canonical renders the expression in a deterministic form : nested logical operations
+// of the same kind are flattened and their operands sorted.
+func Canonical(expr Expr) string {
+	switch e := expr.(type) {
+	case *Condition:
+		vals, _ := json.Marshal(e.Values)
+		return fmt.Sprintf("%s %s %s", e.Column, e.Operator, vals)
+	case *Logical:
+		operands := make([]string, 0, len(e.Exprs))
+		for _, child := range flatten(e) {
+			operands = append(operands, Canonical(child))
+		}
+		slices.Sort(operands)
+		return "(" + strings.Join(operands, " "+e.Operator+" ") + ")"
+	case *Not:
+		return notKeyword + " (" + Canonical(e.Expr) + ")"
+	case *Tuple:
+		vals, _ := json.Marshal(e.Values)
+		return fmt.Sprintf("(%s) %s %s", strings.Join(e.Columns, ", "), e.Operator, vals)
+	case *AsOf:
+		return asOfKeyword + "(" + e.Time.UTC().Format(time.RFC3339Nano) + ")"
+	default:
+		return ""
+	}
+}
+
+// flatten returns the operands of l, inlining nested logical operations of the same kind
+func flatten(l *Logical) []Expr {
+	exprs := make([]Expr, 0, len(l.Exprs))
+	for _, child := range l.Exprs {
+		if nested, ok := child.(*Logical); ok && nested.Operator == l.Operator {
+			exprs = append(exprs, flatten(nested)...)
+			continue
+		}
+		exprs = append(exprs, child)
+	}
+	return exprs
+}
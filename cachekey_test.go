@@ -0,0 +1,30 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	key := func(filter string, fields ...string) string {
+		expr, err := ParseExpr(filter, validateColumn)
+		assert.NoError(t, err)
+		return CacheKey(CacheKeyRequest{Filter: expr, Sort: []Sort{{Column: "age", Desc: true}}, Limit: 10, Fields: fields})
+	}
+
+	base := key(`a eq 1 and (b eq 2 or c eq 3)`, "id", "name")
+	assert.Equal(t, base, key(`(c eq 3 or b eq 2) and a eq 1`, "name", "id"))
+	assert.Equal(t, base, key(`a = 1 and (b = 2 or c = 3)`, "id", "name"))
+	assert.NotEqual(t, base, key(`a eq 1 and (b eq 2 or c eq 4)`, "id", "name"))
+	assert.NotEqual(t, base, key(`a eq 1 and (b eq 2 or c eq 3)`, "id"))
+	assert.NotEqual(t, base, key(`a eq 1 or (b eq 2 and c eq 3)`, "id", "name"))
+
+	assert.Equal(t, Canonical(mustParse(t, `a eq 1 and (b eq 2 and c eq "x")`)), Canonical(mustParse(t, `c eq "x" and b eq 2 and a eq 1`)))
+}
+
+func mustParse(t *testing.T, filter string) Expr {
+	expr, err := ParseExpr(filter, validateColumn)
+	assert.NoError(t, err)
+	return expr
+}
@@ -0,0 +1,13 @@
+package rqe
+
+// CapabilityFuzzyStrMatch is the postgres fuzzystrmatch extension, needed by `within_edits`
+const CapabilityFuzzyStrMatch = "fuzzystrmatch"
+
+// hasCapability reports whether the capability was enabled, an empty one is always available
+func (p *Parser) hasCapability(capability string) bool {
+	if capability == "" {
+		return true
+	}
+	_, ok := p.caps[capability]
+	return ok
+}
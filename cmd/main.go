@@ -1,3 +1,5 @@
+//go:build rqe_debug
+
 package main
 
 import (
@@ -0,0 +1,129 @@
+package rqe
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// coerceValues converts the values to the column's declared Type, e.g. `created_at gte "2024-01-02"`
+// binds a time.Time and `age eq "abc"` is rejected, then checks them against its Enum. Null values, untyped columns and the pattern
+// operations, whose values are patterns rather than column values, are left untouched.
+func (p *Parser) coerceValues(col, opValue, opName string, vals []any, line, column int) ([]any, error) {
+	typ := p.schema[col].Type
+	if _, search := searchOperations[opName]; typ == TypeAny && len(p.schema[col].Enum) == 0 || search {
+		return vals, nil
+	}
+	for i, v := range vals {
+		if v == nil {
+			continue
+		}
+		coerced, ok := p.coerce(p.schema[col], v)
+		if !ok {
+			reason := fmt.Sprintf("%v is not a valid %s", v, typ)
+			if layouts := p.schema[col].Layouts; typ == TypeDate && len(layouts) > 0 {
+				reason = fmt.Sprintf("%v does not match the layouts [%s]", v, strings.Join(layouts, ", "))
+			}
+			return nil, InvalidValueError{Column: col, Operation: opValue, Reason: reason, Line: line, Pos: column}
+		}
+		if enum := p.schema[col].Enum; len(enum) > 0 && !slices.Contains(enum, fmt.Sprint(coerced)) {
+			return nil, InvalidEnumValueError{Column: col, Value: fmt.Sprint(coerced), Allowed: enum, Line: line, Pos: column}
+		}
+		vals[i] = coerced
+	}
+	return vals, nil
+}
+
+// coerce converts a single value to the column's type, false when it cannot represent it
+func (p *Parser) coerce(c Column, v any) (any, bool) {
+	switch c.Type {
+	case TypeInt:
+		switch val := v.(type) {
+		case int64:
+			return val, true
+		case float64:
+			// array elements are decoded as float64
+			return int64(val), val == math.Trunc(val) && math.Abs(val) < 1<<63
+		case string:
+			n, err := strconv.ParseInt(val, 10, 64)
+			return n, err == nil
+		}
+	case TypeFloat:
+		switch val := v.(type) {
+		case float64:
+			return val, true
+		case int64:
+			return float64(val), true
+		case string:
+			f, err := strconv.ParseFloat(val, 64)
+			return f, err == nil
+		}
+	case TypeString, TypeEnum:
+		switch val := v.(type) {
+		case string:
+			return val, true
+		case int64:
+			return strconv.FormatInt(val, 10), true
+		case float64:
+			return strconv.FormatFloat(val, 'f', -1, 64), true
+		}
+	case TypeBool:
+		switch val := v.(type) {
+		case bool:
+			return val, true
+		case string:
+			b, err := strconv.ParseBool(val)
+			return b, err == nil
+		}
+	case TypeDate:
+		client := p.clientZone
+		if client == nil {
+			client = time.UTC
+		}
+		if len(c.Layouts) > 0 {
+			return parseLayouts(c.Layouts, v, client)
+		}
+		switch val := v.(type) {
+		case time.Time:
+			return val, true
+		case string:
+			return parseInZone(val, client)
+		}
+	default:
+		return v, true
+	}
+	return nil, false
+}
+
+// parseLayouts parses the date with the first of the layouts it matches, layouts without an
+// offset being read in loc, and normalizes it to UTC so every layout a column accepts binds the
+// same representation. LayoutUnix accepts integers and integer strings of seconds since the epoch.
+func parseLayouts(layouts []string, v any, loc *time.Location) (any, bool) {
+	for _, layout := range layouts {
+		switch val := v.(type) {
+		case time.Time:
+			return val.UTC(), true
+		case int64:
+			if layout == LayoutUnix {
+				return time.Unix(val, 0).UTC(), true
+			}
+		case float64:
+			// array elements are decoded as float64
+			if layout == LayoutUnix && val == math.Trunc(val) {
+				return time.Unix(int64(val), 0).UTC(), true
+			}
+		case string:
+			if layout == LayoutUnix {
+				if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+					return time.Unix(n, 0).UTC(), true
+				}
+			} else if t, err := time.ParseInLocation(layout, val, loc); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,96 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var typedSchema = Schema{
+	"age":        {Capabilities: Filterable, Type: TypeInt},
+	"score":      {Capabilities: Filterable, Type: TypeFloat},
+	"zip":        {Capabilities: Filterable | Searchable, Type: TypeString},
+	"active":     {Capabilities: Filterable, Type: TypeBool},
+	"created_at": {Capabilities: Filterable | Searchable, Type: TypeDate},
+}
+
+func TestCoerceValues(t *testing.T) {
+	p := NewParser(WithSchema(typedSchema))
+
+	q, err := p.Parse(`age in [18, 21] and score gt 3 and zip eq 2134 and active eq "true" and created_at gte "2024-01-02"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{int64(18), int64(21), float64(3), "2134", true, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, q.Args)
+
+	_, err = p.Parse(`age eq "abc"`, nil)
+	assert.Equal(t, InvalidValueError{Column: "age", Operation: "eq", Reason: "abc is not a valid int", Line: 1, Pos: 0}, err)
+	_, err = p.Parse(`age in [1.5]`, nil)
+	assert.IsType(t, InvalidValueError{}, err)
+	_, err = p.Parse(`created_at lt "yesterday"`, nil)
+	assert.IsType(t, InvalidValueError{}, err)
+
+	// patterns and nulls are not column values
+	q, err = p.Parse(`created_at like "2024-%" or age eq null`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"2024-%"}, q.Args)
+
+	// dates are read in the client's zone
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	q, err = NewParser(WithSchema(typedSchema), WithClientTimeZone(loc)).ParseJSON([]byte(`{"created_at": {"gte": "2024-01-02"}}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{time.Date(2024, 1, 2, 0, 0, 0, 0, loc)}, q.Args)
+}
+
+func TestEnumValues(t *testing.T) {
+	p := NewParser(WithSchema(Schema{
+		"status": {Capabilities: Filterable | Searchable, Type: TypeEnum, Enum: []string{"active", "pending", "banned"}},
+		"level":  {Capabilities: Filterable, Enum: []string{"1", "2"}},
+	}))
+
+	q, err := p.Parse(`status in ["active", "pending"] and level eq 2 and status prefix "act"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"active", "pending", int64(2), "act%"}, q.Args)
+
+	filter := `status eq "deleted"`
+	_, err = p.Parse(filter, nil)
+	assert.Equal(t, InvalidEnumValueError{Column: "status", Value: "deleted", Allowed: []string{"active", "pending", "banned"}, Line: 1, Pos: 0}, err)
+	assert.EqualError(t, err, "value 'deleted' of column 'status' must be one of [active, pending, banned] at line 1, offset 0")
+	assert.Contains(t, err.(InvalidEnumValueError).Pretty(filter), "use one of active, pending, banned")
+
+	_, err = p.ParseJSON([]byte(`{"level": {"in": [1, 3]}}`), nil)
+	assert.IsType(t, InvalidEnumValueError{}, err)
+
+	type account struct {
+		Status string `json:"status" rqe:"filterable,enum=active|banned"`
+	}
+	schema, err := SchemaFromStruct(account{})
+	assert.NoError(t, err)
+	assert.Equal(t, Column{Capabilities: Filterable, Type: TypeEnum, Enum: []string{"active", "banned"}}, schema["status"])
+}
+
+func TestDateLayouts(t *testing.T) {
+	p := NewParser(WithSchema(Schema{
+		"created_at": {Capabilities: Filterable, Type: TypeDate, Layouts: []string{time.RFC3339, time.DateOnly, LayoutUnix}},
+	}), WithClientTimeZone(time.FixedZone("UTC+2", 2*60*60)))
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	q, err := p.Parse(`created_at in ["2024-01-02T02:00:00+02:00", 1704153600, "1704153600"] or created_at eq "2024-01-02"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{day, day, day, day.Add(-2 * time.Hour)}, q.Args)
+
+	_, err = p.Parse(`created_at eq "2024-01-02 10:00:00"`, nil)
+	assert.Equal(t, InvalidValueError{
+		Column:    "created_at",
+		Operation: "eq",
+		Reason:    "2024-01-02 10:00:00 does not match the layouts [2006-01-02T15:04:05Z07:00, 2006-01-02, unix]",
+		Line:      1,
+		Pos:       0,
+	}, err)
+
+	type event struct {
+		At time.Time `json:"at" rqe:"filterable,layouts=2006-01-02|unix"`
+	}
+	schema, err := SchemaFromStruct(event{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{time.DateOnly, LayoutUnix}, schema["at"].Layouts)
+}
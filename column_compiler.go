@@ -0,0 +1,30 @@
+package rqe
+
+// ColumnCompiler takes over the SQL of the conditions on a column, see WithColumnCompiler.
+// column is the quoted SQL column (qualified and mapped like any other), operator the canonical
+// operation and values its resolved values. It returns the SQL fragment with `?` placeholders
+// and the arguments they bind, e.g. `ST_DWithin(geom, ST_MakePoint(?, ?), ?)` for PostGIS or a
+// comparison against the encrypted form of the values.
+//
+// Tuples touching the column are compiled from their Expanded single column conditions.
+// The fragment is trusted SQL, hardened mode does not scan it. It must be deterministic.
+type ColumnCompiler interface {
+	CompileColumn(column, operator string, values []any) (string, []any)
+}
+
+// ColumnCompilerFunc adapts a function to the ColumnCompiler interface
+type ColumnCompilerFunc func(column, operator string, values []any) (string, []any)
+
+func (f ColumnCompilerFunc) CompileColumn(column, operator string, values []any) (string, []any) {
+	return f(column, operator, values)
+}
+
+// compileWith compiles the condition with the column's ColumnCompiler, false when it has none
+func (p *Parser) compileWith(c *Condition) (string, []any, bool) {
+	cc, ok := p.columnCompilers[c.Column]
+	if !ok {
+		return "", nil, false
+	}
+	sql, vals := cc.CompileColumn(p.column(c.Column), c.Operator, append([]any(nil), c.Values...))
+	return sql, vals, true
+}
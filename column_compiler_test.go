@@ -0,0 +1,40 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnCompiler(t *testing.T) {
+	near := ColumnCompilerFunc(func(column, operator string, values []any) (string, []any) {
+		return fmt.Sprintf("ST_DWithin(%s, ST_MakePoint(?, ?), ?)", column), values
+	})
+	encrypted := ColumnCompilerFunc(func(column, operator string, values []any) (string, []any) {
+		for i, v := range values {
+			values[i] = "enc:" + v.(string)
+		}
+		return fmt.Sprintf("%s %s (%s)", column, map[string]string{"eq": "=", "in": "IN"}[operator], strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")), values
+	})
+	p := NewParser(Postgres, WithHardened(), WithTableAlias("u"),
+		WithColumnCompiler("location", near), WithColumnCompiler("ssn", encrypted))
+
+	q, err := p.Parse(`location in [1.5, 2.5, 500] and ssn in ["a", "b"] and age gt 3 or ssn eq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(ST_DWithin(u.location, ST_MakePoint($1, $2), $3) and u.ssn IN ($4, $5) and u.age > $6) or u.ssn IS NULL", q.SQL)
+	assert.Equal(t, []any{1.5, 2.5, float64(500), "enc:a", "enc:b", int64(3)}, q.Args)
+	assert.Equal(t, "ssn", q.ArgInfo[3].Column)
+
+	q, err = p.Parse(`ssn eq "c"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "u.ssn = ($1)", q.SQL)
+	assert.Equal(t, []any{"enc:c"}, q.Args)
+
+	// tuples touching the column are compiled from their single column form
+	q, err = p.Parse(`(ssn, id) eq ["123", 2]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "u.ssn = ($1) and u.id = $2", q.SQL)
+	assert.Equal(t, []any{"enc:123", float64(2)}, q.Args)
+}
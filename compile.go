@@ -0,0 +1,275 @@
+package rqe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Compile turns an expression tree into SQL with the dialect's placeholders and its arguments.
+// A nil expression compiles to an empty query.
+func (p *Parser) Compile(expr Expr) ParsedQuery {
+	out := p.compile(expr)
+	out.SQL, out.Having, out.NamedArgs = p.bindHaving(out.SQL, out.Having, out.Args, out.ArgInfo)
+	return out
+}
+
+// havingSeparator joins the WHERE and HAVING clauses while binding, so placeholders are numbered across both
+const havingSeparator = "\x00"
+
+// bindHaving binds the WHERE and HAVING clauses as one statement, see bind
+func (p *Parser) bindHaving(where, having string, args []interface{}, infos []ArgInfo) (string, string, map[string]interface{}) {
+	sql, named := p.bind(where+havingSeparator+having, args, infos)
+	where, having, _ = strings.Cut(sql, havingSeparator)
+	return where, having, named
+}
+
+// bind rewrites the `?` placeholders with the dialect's, or with named parameters when
+// WithNamedArgs is used, named after the column of each argument or `arg` for server side conditions
+func (p *Parser) bind(sql string, args []interface{}, infos []ArgInfo) (string, map[string]interface{}) {
+	if !p.namedArgs {
+		return p.dialect.bind(sql), nil
+	}
+	named := make(map[string]interface{}, len(args))
+	n := 0
+	sql = Dialect{Placeholder: func(_ int) string {
+		col := infos[n].Column
+		if col == "" {
+			col = "arg"
+		}
+		name := fmt.Sprintf("%s_%d", paramName(col), n)
+		named[name] = args[n]
+		n++
+		return ":" + name
+	}}.bind(sql)
+	return sql, named
+}
+
+// paramName keeps the letters, digits and underscores of a column so it can name a parameter
+func paramName(col string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, col)
+}
+
+// compile turns an expression tree into SQL with `?` placeholders, whatever the dialect
+func (p *Parser) compile(expr Expr) ParsedQuery {
+	var sb strings.Builder
+	out := ParsedQuery{Args: make([]interface{}, 0), Columns: make([]string, 0), ArgInfo: make([]ArgInfo, 0)}
+	if expr == nil {
+		return out
+	}
+
+	expr, out.AsOf = extractAsOf(expr)
+	where, having := p.splitHaving(expr)
+	if where != nil {
+		p.compileExpr(&sb, where, &out, false)
+	}
+	out.SQL = sb.String()
+	if having != nil {
+		var hb strings.Builder
+		whereArgs := len(out.Args)
+		p.compileExpr(&hb, having, &out, false)
+		out.Having = hb.String()
+		out.HavingArgs = out.Args[whereArgs:]
+	}
+
+	Walk(expr, func(c *Condition) {
+		if !slices.Contains(out.Columns, c.Column) {
+			out.Columns = append(out.Columns, c.Column)
+		}
+	})
+	if p.shardKey != "" {
+		out.ShardKeys = ShardKeys(expr, p.shardKey)
+	}
+	return out
+}
+
+func (p *Parser) compileExpr(sb *strings.Builder, expr Expr, out *ParsedQuery, nested bool) {
+	switch e := expr.(type) {
+	case *Condition:
+		sql, vals := p.compileCondition(e)
+		sb.WriteString(sql)
+		out.Args = append(out.Args, vals...)
+		for _, v := range vals {
+			out.ArgInfo = append(out.ArgInfo, ArgInfo{Column: e.Column, Operator: e.Operator, GoType: reflect.TypeOf(v)})
+		}
+	case *Logical:
+		if nested {
+			sb.WriteString("(")
+		}
+		for i, child := range e.Exprs {
+			if i > 0 {
+				sb.WriteString(" " + e.Operator + " ")
+			}
+			p.compileExpr(sb, child, out, true)
+		}
+		if nested {
+			sb.WriteString(")")
+		}
+	case *Not:
+		sb.WriteString("NOT (")
+		p.compileExpr(sb, e.Expr, out, false)
+		sb.WriteString(")")
+	case *Tuple:
+		if !p.rowValue(e) {
+			p.compileExpr(sb, e.Expanded, out, nested)
+			return
+		}
+		placeholders := make([]string, len(e.Values))
+		for i := range placeholders {
+			placeholders[i] = "?"
+		}
+		columns := make([]string, len(e.Columns))
+		for i, col := range e.Columns {
+			columns[i] = p.column(col)
+		}
+		fmt.Fprintf(sb, "(%s) %s (%s)", strings.Join(columns, ", "), tupleOperators[e.Operator], strings.Join(placeholders, ", "))
+		out.Args = append(out.Args, e.Values...)
+		for i, v := range e.Values {
+			out.ArgInfo = append(out.ArgInfo, ArgInfo{Column: e.Columns[i%len(e.Columns)], Operator: e.Operator, GoType: reflect.TypeOf(v)})
+		}
+	}
+}
+
+// compileCondition renders a single comparison and the values it binds
+func (p *Parser) compileCondition(c *Condition) (string, []any) {
+	op := operationsMapped[c.Operator]
+	if c.IsNull() {
+		return fmt.Sprintf("%s %s", p.column(c.Column), op.NullValue), nil
+	}
+	if sql, vals, ok := p.compileWith(c); ok {
+		return sql, vals
+	}
+
+	if _, ok := p.digests[c.Column]; ok {
+		if _, equality := digestOperations[c.Operator]; equality {
+			vals := digestValues(c.Values)
+			return p.dialect.operationSQL(c.Operator, p.digestColumn(c.Column), len(vals)), vals
+		}
+	}
+
+	vals := slices.Clone(c.Values)
+	render := func(col string) string { return p.dialect.operationSQL(c.Operator, col, len(vals)) }
+	if op.Like != nil {
+		vals = []any{p.likeArg(op.Like, vals[0])}
+		render = func(col string) string { return p.likeSQL(col, op.Like) }
+	}
+
+	col := p.column(c.Column)
+	if collation := p.schema[c.Column].Collation; collation != "" {
+		col += " COLLATE " + collation
+	}
+	expr := render(col)
+	if _, ok := p.folded[c.Column]; ok {
+		if collation, ok := foldCollations[p.dialect.Name]; ok && p.schema[c.Column].Collation == "" {
+			expr = render(col + " COLLATE " + collation)
+		} else {
+			expr = strings.ReplaceAll(render(foldExpr(col)), "?", foldExpr("?"))
+		}
+	} else if p.schema[c.Column].CaseInsensitive {
+		expr = strings.ReplaceAll(render(lowerExpr(col)), "?", lowerExpr("?"))
+	}
+	if n := strings.Count(expr, "?"); len(vals) == 1 && n > 1 {
+		for range n - 1 {
+			vals = append(vals, vals[0])
+		}
+	}
+	if allowed, ok := p.inlineEnums[c.Column]; ok {
+		expr, vals = inlineEnumArgs(expr, vals, allowed)
+	}
+	return expr, vals
+}
+
+// column is the quoted SQL column of an API column, each part of a qualified
+// name (`users.first_name`) is quoted on its own. Virtual columns are their expression.
+func (p *Parser) column(col string) string {
+	if expr := p.schema[col].Expression; expr != "" {
+		return expr
+	}
+	return p.quoteName(p.qualifiedName(col))
+}
+
+// digestColumn is the quoted `<column>_md5` companion of a digest column, named after the
+// column's mapped and qualified name, see WithDigestColumns
+func (p *Parser) digestColumn(col string) string {
+	return p.quoteName(p.qualifiedName(col) + "_md5")
+}
+
+// qualifiedName is the database name of an API column, qualified with its table or the alias
+func (p *Parser) qualifiedName(col string) string {
+	name := p.schema.dbName(col)
+	if !strings.Contains(name, ".") {
+		if table := p.columnTables[col]; table != "" {
+			name = table + "." + name
+		} else if p.tableAlias != "" {
+			name = p.tableAlias + "." + name
+		}
+	}
+	return name
+}
+
+// quoteName quotes each part of a qualified name on its own
+func (p *Parser) quoteName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = p.dialect.ident(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// foldCollations are the case and accent insensitive collations folded columns are compared
+// with on the dialects without `unaccent`. An empty collation means the dialect cannot fold.
+var foldCollations = map[string]string{
+	"mysql":    "utf8mb4_0900_ai_ci",
+	"mssql":    "Latin1_General_CI_AI",
+	"oracle":   "BINARY_AI",
+	"bigquery": "",
+}
+
+// folds reports whether the dialect can compare folded columns
+func (d Dialect) folds() bool {
+	collation, ok := foldCollations[d.Name]
+	return !ok || collation != ""
+}
+
+// foldExpr wraps a column or placeholder so comparisons ignore case and accents
+func foldExpr(s string) string {
+	return fmt.Sprintf("LOWER(unaccent(%s))", s)
+}
+
+// lowerExpr wraps a column or placeholder so comparisons ignore case
+func lowerExpr(s string) string {
+	return fmt.Sprintf("LOWER(%s)", s)
+}
+
+// inlineEnumArgs replaces the placeholders of allowed int64 values with their literal.
+// Anything that is not an int64 from the allowed set stays bound.
+func inlineEnumArgs(expr string, vals []any, allowed map[int64]struct{}) (string, []any) {
+	var sb strings.Builder
+	kept := make([]any, 0, len(vals))
+	argIndex := 0
+	for i := 0; i < len(expr); i++ {
+		if expr[i] != '?' || argIndex >= len(vals) {
+			sb.WriteByte(expr[i])
+			continue
+		}
+		v := vals[argIndex]
+		argIndex++
+		if n, ok := v.(int64); ok {
+			if _, isEnum := allowed[n]; isEnum {
+				sb.WriteString(strconv.FormatInt(n, 10))
+				continue
+			}
+		}
+		sb.WriteByte('?')
+		kept = append(kept, v)
+	}
+	return sb.String(), kept
+}
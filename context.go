@@ -0,0 +1,24 @@
+package rqe
+
+import "context"
+
+// ContextRule is a Rule also reading the context the filter is parsed with, for checks
+// depending on the request such as the current user's role, see WithContextRules
+type ContextRule func(ctx context.Context, expr Expr) []Violation
+
+// ParseContext is Parse honoring the context's cancellation and deadline. The context is
+// handed to macros implementing macros.ContextMacro and to the ContextRules so they can read
+// request scoped values (current user, tenant ...) stored in it.
+func ParseContext(ctx context.Context, filter string, validateCol func(col string) bool, opts ...Option) (ParsedQuery, error) {
+	return withDefaults(opts).ParseContext(ctx, filter, validateCol)
+}
+
+// ParseContext converts the filter into a ParsedQuery using the parser's configuration, see the
+// package level ParseContext
+func (p *Parser) ParseContext(ctx context.Context, filter string, validateCol func(col string) bool) (ParsedQuery, error) {
+	expr, err := p.ParseExprContext(ctx, filter, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	return p.compileChecked(expr)
+}
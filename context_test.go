@@ -0,0 +1,66 @@
+package rqe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/baderkha/rqe/macros"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+type tenantMacro struct{}
+
+func (tenantMacro) RunMacro(col string, args ...any) ([]any, error) {
+	return nil, &macros.InvalidMacroValueError{Column: col, Detail: "no tenant"}
+}
+
+func (tenantMacro) RunMacroContext(ctx context.Context, col string, args ...any) ([]any, error) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	if !ok {
+		return tenantMacro{}.RunMacro(col, args...)
+	}
+	return []any{args[0].(string) + tenant}, nil
+}
+
+func TestParseContext(t *testing.T) {
+	macros.Handlers["tenant"] = tenantMacro{}
+	supported := macros.Supported
+	macros.Supported = append(macros.Supported, "tenant")
+	defer func() {
+		delete(macros.Handlers, "tenant")
+		macros.Supported = supported
+	}()
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	q, err := ParseContext(ctx, `name eq tenant("team-")`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", q.SQL)
+	assert.Equal(t, []any{"team-acme"}, q.Args)
+
+	// Parse runs the macro with an empty context
+	_, err = Parse(`name eq tenant("team-")`, validateColumn)
+	assert.Equal(t, &macros.InvalidMacroValueError{Column: "name", Detail: "no tenant"}, err)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ParseContext(canceled, `age gt 18`, validateColumn)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestContextRules(t *testing.T) {
+	adminOnly := func(ctx context.Context, expr Expr) []Violation {
+		if ctx.Value(tenantKey{}) != "admin" && references(expr, "age") {
+			return []Violation{{Rule: "admin_only", Column: "age", Message: "only admins filter on 'age'"}}
+		}
+		return nil
+	}
+	p := NewParser(WithContextRules(adminOnly))
+
+	_, err := p.ParseContext(context.Background(), `age gt 18`, validateColumn)
+	assert.Equal(t, RuleViolationError{Violations: []Violation{{Rule: "admin_only", Column: "age", Message: "only admins filter on 'age'"}}}, err)
+
+	_, err = p.ParseContext(context.WithValue(context.Background(), tenantKey{}, "admin"), `age gt 18`, validateColumn)
+	assert.NoError(t, err)
+}
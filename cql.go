@@ -0,0 +1,187 @@
+package rqe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// cqlComparisons are the operations CQL can run, ranges only on clustering columns
+var cqlComparisons = map[string]string{
+	"eq":  "=",
+	"lt":  "<",
+	"lte": "<=",
+	"gt":  ">",
+	"gte": ">=",
+}
+
+// CQLTable describes the primary key and indexes of the Cassandra table a filter runs against
+type CQLTable struct {
+	PartitionKeys     []string
+	ClusteringColumns []string
+	// Indexed are the columns with a secondary index
+	Indexed []string
+}
+
+// CQLQuery is the WHERE clause of a CQL SELECT with its `?` bound values
+type CQLQuery struct {
+	Where string
+	Args  []any
+	// AllowFiltering is set when Cassandra only runs the query with `ALLOW FILTERING`,
+	// i.e. it cannot be answered from the partition and clustering keys or an index alone
+	AllowFiltering bool
+}
+
+// ParseToCQL parses the filter into a CQL WHERE clause for the table, see ToCQL
+func ParseToCQL(filter string, validateCol func(col string) bool, table CQLTable, opts ...Option) (CQLQuery, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return CQLQuery{}, err
+	}
+	return ToCQL(expr, table)
+}
+
+// ToCQL converts the expression into a CQL WHERE clause. CQL only has conjunctions, so `or`,
+// `not`, `ne`, null checks and pattern operations return an UnsupportedOperationError, as do
+// ranges on columns that are not clustering columns.
+func ToCQL(expr Expr, table CQLTable) (CQLQuery, error) {
+	out := CQLQuery{Args: make([]any, 0)}
+	if expr == nil {
+		return out, nil
+	}
+	conds, err := cqlConjuncts(expr)
+	if err != nil {
+		return CQLQuery{}, err
+	}
+
+	parts := make([]string, 0, len(conds))
+	for _, c := range conds {
+		part, args, err := cqlCondition(c, table)
+		if err != nil {
+			return CQLQuery{}, err
+		}
+		parts = append(parts, part)
+		out.Args = append(out.Args, args...)
+	}
+	out.Where = strings.Join(parts, " AND ")
+	out.AllowFiltering = cqlNeedsFiltering(conds, table)
+	return out, nil
+}
+
+// cqlConjuncts flattens the expression into its `and`ed conditions
+func cqlConjuncts(expr Expr) ([]Expr, error) {
+	switch e := expr.(type) {
+	case *Condition:
+		return []Expr{e}, nil
+	case *Tuple:
+		return []Expr{e}, nil
+	case *Logical:
+		if e.Operator != "and" {
+			return nil, UnsupportedOperationError{Backend: "cassandra", Operation: e.Operator}
+		}
+		var conds []Expr
+		for _, child := range e.Exprs {
+			childConds, err := cqlConjuncts(child)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, childConds...)
+		}
+		return conds, nil
+	case *Not:
+		return nil, UnsupportedOperationError{Backend: "cassandra", Operation: notKeyword}
+	default:
+		return nil, UnsupportedOperationError{Backend: "cassandra", Operation: asOfKeyword}
+	}
+}
+
+func cqlCondition(expr Expr, table CQLTable) (string, []any, error) {
+	if t, ok := expr.(*Tuple); ok {
+		op, supported := cqlComparisons[t.Operator]
+		for _, col := range t.Columns {
+			supported = supported && slices.Contains(table.ClusteringColumns, col)
+		}
+		if !supported {
+			return "", nil, UnsupportedOperationError{Backend: "cassandra", Operation: t.Operator, Column: strings.Join(t.Columns, ", "), Line: t.Line, Pos: t.Pos}
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.Values)), ", ")
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(t.Columns, ", "), op, placeholders), t.Values, nil
+	}
+
+	c := expr.(*Condition)
+	unsupported := UnsupportedOperationError{Backend: "cassandra", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+	if c.IsNull() {
+		return "", nil, unsupported
+	}
+	ranged := c.Operator != "eq" && c.Operator != "in"
+	if ranged && !slices.Contains(table.ClusteringColumns, c.Column) {
+		return "", nil, unsupported
+	}
+	switch c.Operator {
+	case "in":
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(c.Values)), ", ")
+		return fmt.Sprintf("%s IN (%s)", c.Column, placeholders), c.Values, nil
+	case "between":
+		return fmt.Sprintf("%s >= ? AND %s <= ?", c.Column, c.Column), c.Values, nil
+	case "from_until":
+		return fmt.Sprintf("%s >= ? AND %s < ?", c.Column, c.Column), c.Values, nil
+	}
+	op, ok := cqlComparisons[c.Operator]
+	if !ok {
+		return "", nil, unsupported
+	}
+	return fmt.Sprintf("%s %s ?", c.Column, op), c.Values, nil
+}
+
+// cqlNeedsFiltering reports whether the restrictions cannot be served by the primary key or a
+// secondary index : a partially restricted partition key, clustering columns restricted without
+// the whole partition key or out of order, or equality on a regular column without an index
+func cqlNeedsFiltering(conds []Expr, table CQLTable) bool {
+	equal := make(map[string]bool)
+	ranged := make(map[string]bool)
+	for _, expr := range conds {
+		switch e := expr.(type) {
+		case *Condition:
+			if e.Operator == "eq" || e.Operator == "in" {
+				equal[e.Column] = true
+			} else {
+				ranged[e.Column] = true
+			}
+		case *Tuple:
+			for _, col := range e.Columns {
+				ranged[col] = true
+			}
+		}
+	}
+
+	partition := 0
+	for _, col := range table.PartitionKeys {
+		if equal[col] {
+			partition++
+		}
+	}
+	if partition > 0 && partition < len(table.PartitionKeys) {
+		return true
+	}
+
+	// clustering restrictions must be a prefix of the clustering columns, ending with at most one range
+	clustered, ended := false, false
+	for _, col := range table.ClusteringColumns {
+		restricted := equal[col] || ranged[col]
+		if restricted && ended {
+			return true
+		}
+		clustered = clustered || restricted
+		ended = ended || !restricted || ranged[col]
+	}
+	if clustered && partition == 0 && len(table.PartitionKeys) > 0 {
+		return true
+	}
+
+	for col := range equal {
+		if !slices.Contains(table.PartitionKeys, col) && !slices.Contains(table.ClusteringColumns, col) && !slices.Contains(table.Indexed, col) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToCQL(t *testing.T) {
+	table := CQLTable{
+		PartitionKeys:     []string{"tenant", "day"},
+		ClusteringColumns: []string{"created_at", "id"},
+		Indexed:           []string{"status"},
+	}
+
+	q, err := ParseToCQL(`tenant eq "acme" and (day eq "2024-01-01" and created_at between [1, 5])`, validateColumn, table)
+	assert.NoError(t, err)
+	assert.Equal(t, `tenant = ? AND day = ? AND created_at >= ? AND created_at <= ?`, q.Where)
+	assert.Equal(t, []any{"acme", "2024-01-01", float64(1), float64(5)}, q.Args)
+	assert.False(t, q.AllowFiltering)
+
+	q, err = ParseToCQL(`status in ["paid", "sent"]`, validateColumn, table)
+	assert.NoError(t, err)
+	assert.Equal(t, `status IN (?, ?)`, q.Where)
+	assert.Equal(t, []any{"paid", "sent"}, q.Args)
+	assert.False(t, q.AllowFiltering)
+
+	filtering := []string{
+		`tenant eq "acme"`,                         // partially restricted partition key
+		`created_at gt 3`,                          // clustering without partition key
+		`tenant eq "a" and day eq "b" and id eq 3`, // skips a clustering column
+		`tenant eq "a" and day eq "b" and created_at gt 1 and id eq 3`, // range before a restricted column
+		`tenant eq "a" and day eq "b" and name eq "bob"`,               // regular column without index
+	}
+	for _, filter := range filtering {
+		q, err = ParseToCQL(filter, validateColumn, table)
+		assert.NoError(t, err, filter)
+		assert.True(t, q.AllowFiltering, filter)
+	}
+
+	q, err = ParseToCQL(``, validateColumn, table)
+	assert.NoError(t, err)
+	assert.Empty(t, q.Where)
+
+	_, err = ParseToCQL(`tenant eq "a" or tenant eq "b"`, validateColumn, table)
+	assert.Equal(t, UnsupportedOperationError{Backend: "cassandra", Operation: "or"}, err)
+
+	_, err = ParseToCQL(`age gt 3`, validateColumn, table)
+	assert.Equal(t, UnsupportedOperationError{Backend: "cassandra", Operation: "gt", Column: "age", Line: 1, Pos: 0}, err)
+
+	for _, filter := range []string{`not (tenant eq "a")`, `tenant ne "a"`, `name like "bo%"`, `deleted_at eq null`} {
+		_, err = ParseToCQL(filter, validateColumn, table)
+		assert.IsType(t, UnsupportedOperationError{}, err, filter)
+	}
+}
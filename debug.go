@@ -1,10 +1,16 @@
+//go:build rqe_debug
+
 package rqe
 
 import (
 	"fmt"
 	"strings"
+
+	"github.com/davecgh/go-spew/spew"
 )
 
+// DANGEROUS_DEBUG_COMPILE_SQL inlines the arguments into the query for reading it while developing.
+// The result is not escaped and must never reach a database, it only exists in `rqe_debug` builds.
 func DANGEROUS_DEBUG_COMPILE_SQL(query string, args []interface{}) string {
 	var sb strings.Builder
 	argIndex := 0
@@ -33,3 +39,8 @@ func DANGEROUS_DEBUG_COMPILE_SQL(query string, args []interface{}) string {
 
 	return sb.String()
 }
+
+// debugDump prints the values to stdout
+func debugDump(vals ...any) {
+	spew.Dump(vals...)
+}
@@ -0,0 +1,6 @@
+//go:build !rqe_debug
+
+package rqe
+
+// debugDump is a no-op outside of `rqe_debug` builds, see debug.go
+func debugDump(...any) {}
@@ -0,0 +1,477 @@
+package rqe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sqlComparisons maps the SQL comparison operators onto the rqe operations
+var sqlComparisons = map[string]string{
+	"=":  "eq",
+	"<>": "ne",
+	"!=": "ne",
+	"<":  "lt",
+	"<=": "lte",
+	">":  "gt",
+	">=": "gte",
+}
+
+type sqlKind int
+
+const (
+	sqlEOF sqlKind = iota
+	sqlIdent
+	sqlString
+	sqlNumber
+	sqlOperator
+	sqlParenOpen
+	sqlParenClose
+	sqlComma
+)
+
+type sqlToken struct {
+	Kind sqlKind
+	Text string
+	// Quoted identifiers are never keywords
+	Quoted bool
+	Value  any
+	Line   int
+	Pos    int
+}
+
+// Decompile converts a simple SQL WHERE clause into the equivalent rqe filter, to help migrating
+// hand written query endpoints to the rqe language:
+//
+//	Decompile(`age >= 18 AND status IN ('active', 'pending') AND deleted_at IS NULL`)
+//	// age gte 18 and status in ["active", "pending"] and deleted_at eq null
+//
+// It is best effort and supports the comparison operators, `[NOT] IN`, `[NOT] BETWEEN`,
+// `[NOT] LIKE`, `ILIKE`, `IS [NOT] NULL`, `AND`, `OR`, `NOT` and parentheses over columns and
+// literals. Table qualifiers are dropped (`u.name` becomes `name`) and booleans become the
+// strings `"true"` / `"false"`. Anything else, functions and placeholders included, fails with
+// an UnexpectedTokenError pointing into the SQL.
+func Decompile(where string) (string, error) {
+	toks, err := lexSQL(where)
+	if err != nil {
+		return "", err
+	}
+	if len(toks) == 1 {
+		return "", nil
+	}
+	dp := &decompiler{toks: toks}
+	expr, err := dp.parseOr()
+	if err != nil {
+		return "", err
+	}
+	if tok := dp.current(); tok.Kind != sqlEOF {
+		if tok.Kind == sqlParenClose {
+			return "", UnmatchedParenthesisError{Type: "closing", Line: tok.Line, Pos: tok.Pos}
+		}
+		return "", UnexpectedTokenError{Token: tok.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+	return formatFilter(expr, false)
+}
+
+// lexSQL splits the WHERE clause into tokens, the last one is always sqlEOF
+func lexSQL(sql string) ([]sqlToken, error) {
+	toks := make([]sqlToken, 0)
+	line := 1
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		tok := sqlToken{Line: line, Pos: i}
+		switch {
+		case c == '\n':
+			line++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '(':
+			tok.Kind, tok.Text = sqlParenOpen, "("
+			i++
+		case c == ')':
+			tok.Kind, tok.Text = sqlParenClose, ")"
+			i++
+		case c == ',':
+			tok.Kind, tok.Text = sqlComma, ","
+			i++
+		case strings.IndexByte("=<>!", c) >= 0:
+			j := i + 1
+			if j < len(sql) && strings.IndexByte("=>", sql[j]) >= 0 {
+				j++
+			}
+			tok.Kind, tok.Text = sqlOperator, sql[i:j]
+			if _, ok := sqlComparisons[tok.Text]; !ok {
+				return nil, UnexpectedTokenError{Token: tok.Text, Line: line, Pos: i}
+			}
+			i = j
+		case c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if sql[j] != '\'' {
+					sb.WriteByte(sql[j])
+					continue
+				}
+				if j+1 < len(sql) && sql[j+1] == '\'' {
+					sb.WriteByte('\'')
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(sql) {
+				return nil, UnexpectedTokenError{Token: "unterminated string", Line: line, Pos: i}
+			}
+			tok.Kind, tok.Text, tok.Value = sqlString, sql[i:j+1], sb.String()
+			i = j + 1
+		case c == '"' || c == '`' || c == '[':
+			end := map[byte]byte{'"': '"', '`': '`', '[': ']'}[c]
+			j := strings.IndexByte(sql[i+1:], end)
+			if j < 0 {
+				return nil, UnexpectedTokenError{Token: "unterminated identifier", Line: line, Pos: i}
+			}
+			tok.Kind, tok.Text, tok.Quoted = sqlIdent, sql[i+1:i+1+j], true
+			i += j + 2
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(sql) && (sql[j] == '_' || unicode.IsLetter(rune(sql[j])) || unicode.IsDigit(rune(sql[j]))) {
+				j++
+			}
+			tok.Kind, tok.Text = sqlIdent, sql[i:j]
+			i = j
+		case unicode.IsDigit(rune(c)) || c == '-' && i+1 < len(sql) && unicode.IsDigit(rune(sql[i+1])):
+			j := i + 1
+			for j < len(sql) && strings.IndexByte("0123456789.eE", sql[j]) >= 0 {
+				j++
+			}
+			tok.Kind, tok.Text = sqlNumber, sql[i:j]
+			if n, err := strconv.ParseInt(tok.Text, 10, 64); err == nil {
+				tok.Value = n
+			} else if f, err := strconv.ParseFloat(tok.Text, 64); err == nil {
+				tok.Value = f
+			} else {
+				return nil, UnexpectedTokenError{Token: tok.Text, Line: line, Pos: i}
+			}
+			i = j
+		case c == '.' && len(toks) > 0 && toks[len(toks)-1].Kind == sqlIdent:
+			// `table.column`, the qualifier is dropped
+			toks = toks[:len(toks)-1]
+			i++
+			continue
+		default:
+			return nil, UnexpectedTokenError{Token: string(c), Line: line, Pos: i}
+		}
+		toks = append(toks, tok)
+	}
+	return append(toks, sqlToken{Kind: sqlEOF, Line: line, Pos: len(sql)}), nil
+}
+
+// decompiler is a recursive descent parser over the tokens of a SQL WHERE clause.
+//
+//	or        = and { "OR" and }
+//	and       = unary { "AND" unary }
+//	unary     = "NOT" unary | primary
+//	primary   = "(" or ")" | column predicate
+//	predicate = comparison literal | ["NOT"] "IN" "(" literals ")" | ["NOT"] "BETWEEN" literal "AND" literal
+//	          | ["NOT"] ("LIKE" | "ILIKE") string | "IS" ["NOT"] "NULL"
+type decompiler struct {
+	toks []sqlToken
+	i    int
+}
+
+func (dp *decompiler) current() sqlToken {
+	return dp.toks[dp.i]
+}
+
+// next returns the current token and moves past it, the stream stays on sqlEOF
+func (dp *decompiler) next() sqlToken {
+	tok := dp.toks[dp.i]
+	if tok.Kind != sqlEOF {
+		dp.i++
+	}
+	return tok
+}
+
+// isWord reports whether the current token is the SQL keyword, whatever its case
+func (dp *decompiler) isWord(word string) bool {
+	tok := dp.current()
+	return tok.Kind == sqlIdent && !tok.Quoted && strings.EqualFold(tok.Text, word)
+}
+
+// expect consumes a token of the kind, failing with an UnexpectedTokenError naming what was expected
+func (dp *decompiler) expect(kind sqlKind, expected string) (sqlToken, error) {
+	tok := dp.next()
+	if tok.Kind != kind {
+		if kind == sqlParenClose && tok.Kind == sqlEOF {
+			return tok, UnmatchedParenthesisError{Type: "opening", Line: tok.Line, Pos: tok.Pos}
+		}
+		return tok, UnexpectedTokenError{Token: expected, Line: tok.Line, Pos: tok.Pos}
+	}
+	return tok, nil
+}
+
+// expectWord consumes the SQL keyword
+func (dp *decompiler) expectWord(word string) error {
+	if !dp.isWord(word) {
+		tok := dp.current()
+		return UnexpectedTokenError{Token: word, Line: tok.Line, Pos: tok.Pos}
+	}
+	dp.next()
+	return nil
+}
+
+func (dp *decompiler) parseOr() (Expr, error) {
+	return dp.parseLogical("or", dp.parseAnd)
+}
+
+func (dp *decompiler) parseAnd() (Expr, error) {
+	return dp.parseLogical("and", dp.parseUnary)
+}
+
+func (dp *decompiler) parseLogical(operator string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []Expr{first}
+	for dp.isWord(operator) {
+		tok := dp.next()
+		if dp.current().Kind == sqlEOF {
+			return nil, &LogicalTokenError{Reason: "cannot end with a logical operation", Line: tok.Line, Pos: tok.Pos}
+		}
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, next)
+	}
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &Logical{Operator: operator, Exprs: exprs}, nil
+}
+
+func (dp *decompiler) parseUnary() (Expr, error) {
+	if !dp.isWord(notKeyword) {
+		return dp.parsePrimary()
+	}
+	dp.next()
+	expr, err := dp.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Not{Expr: expr}, nil
+}
+
+func (dp *decompiler) parsePrimary() (Expr, error) {
+	tok := dp.next()
+	switch {
+	case tok.Kind == sqlParenOpen:
+		expr, err := dp.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := dp.expect(sqlParenClose, ")"); err != nil {
+			if _, unmatched := err.(UnmatchedParenthesisError); unmatched {
+				return nil, UnmatchedParenthesisError{Type: "opening", Line: tok.Line, Pos: tok.Pos}
+			}
+			return nil, err
+		}
+		return expr, nil
+	case tok.Kind == sqlIdent && dp.current().Kind == sqlParenOpen:
+		return nil, UnexpectedTokenError{Token: "function " + tok.Text, Line: tok.Line, Pos: tok.Pos}
+	case tok.Kind == sqlIdent:
+		return dp.parsePredicate(tok)
+	case tok.Kind == sqlParenClose:
+		return nil, UnmatchedParenthesisError{Type: "closing", Line: tok.Line, Pos: tok.Pos}
+	case tok.Kind == sqlEOF:
+		return nil, UnexpectedTokenError{Token: "expression", Line: tok.Line, Pos: tok.Pos}
+	default:
+		return nil, UnexpectedTokenError{Token: tok.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+}
+
+// parsePredicate parses what follows the column of a comparison
+func (dp *decompiler) parsePredicate(col sqlToken) (Expr, error) {
+	cond := &Condition{Column: col.Text, Line: col.Line, Pos: col.Pos}
+	if tok := dp.current(); tok.Kind == sqlOperator {
+		dp.next()
+		v, err := dp.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		cond.Operator, cond.Values = sqlComparisons[tok.Text], []any{v}
+		return cond, nil
+	}
+
+	if dp.isWord("is") {
+		dp.next()
+		cond.Operator = "eq"
+		if dp.isWord(notKeyword) {
+			dp.next()
+			cond.Operator = "ne"
+		}
+		if err := dp.expectWord(nullKeyword); err != nil {
+			return nil, err
+		}
+		cond.Values = []any{nil}
+		return cond, nil
+	}
+
+	negated := dp.isWord(notKeyword)
+	if negated {
+		dp.next()
+	}
+	var err error
+	switch {
+	case dp.isWord("in"):
+		dp.next()
+		cond.Operator = map[bool]string{false: "in", true: "nin"}[negated]
+		cond.Values, err = dp.parseList()
+	case dp.isWord("between"):
+		dp.next()
+		cond.Operator = map[bool]string{false: "between", true: "nbetween"}[negated]
+		cond.Values, err = dp.parseRange()
+	case dp.isWord("like"), dp.isWord("ilike"):
+		cond.Operator = strings.ToLower(dp.next().Text)
+		var pattern sqlToken
+		if pattern, err = dp.expect(sqlString, "string"); err == nil {
+			cond.Values = []any{pattern.Value}
+		}
+		if negated {
+			return &Not{Expr: cond}, err
+		}
+	default:
+		tok := dp.current()
+		return nil, UnexpectedTokenError{Token: "comparison", Line: tok.Line, Pos: tok.Pos}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cond, nil
+}
+
+// parseList parses the parenthesized literals of an IN
+func (dp *decompiler) parseList() ([]any, error) {
+	if _, err := dp.expect(sqlParenOpen, "("); err != nil {
+		return nil, err
+	}
+	var vals []any
+	for {
+		v, err := dp.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+		if dp.current().Kind != sqlComma {
+			break
+		}
+		dp.next()
+	}
+	if _, err := dp.expect(sqlParenClose, ")"); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// parseRange parses the `low AND high` bounds of a BETWEEN
+func (dp *decompiler) parseRange() ([]any, error) {
+	low, err := dp.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if err := dp.expectWord("and"); err != nil {
+		return nil, err
+	}
+	high, err := dp.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return []any{low, high}, nil
+}
+
+// parseLiteral parses a string, number or boolean
+func (dp *decompiler) parseLiteral() (any, error) {
+	tok := dp.next()
+	switch {
+	case tok.Kind == sqlString || tok.Kind == sqlNumber:
+		return tok.Value, nil
+	case tok.Kind == sqlIdent && !tok.Quoted && (strings.EqualFold(tok.Text, "true") || strings.EqualFold(tok.Text, "false")):
+		return strings.ToLower(tok.Text), nil
+	case tok.Kind == sqlEOF:
+		return nil, UnexpectedTokenError{Token: "value", Line: tok.Line, Pos: tok.Pos}
+	default:
+		return nil, UnexpectedTokenError{Token: tok.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+}
+
+// formatFilter writes the expression in the rqe grammar, nested groups are parenthesized
+func formatFilter(expr Expr, nested bool) (string, error) {
+	switch e := expr.(type) {
+	case *Condition:
+		return formatCondition(e)
+	case *Logical:
+		parts := make([]string, len(e.Exprs))
+		for i, child := range e.Exprs {
+			part, err := formatFilter(child, true)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		s := strings.Join(parts, " "+e.Operator+" ")
+		if nested {
+			s = "(" + s + ")"
+		}
+		return s, nil
+	case *Not:
+		s, err := formatFilter(e.Expr, false)
+		if err != nil {
+			return "", err
+		}
+		return notKeyword + " (" + s + ")", nil
+	}
+	return "", fmt.Errorf("cannot format %T", expr)
+}
+
+// formatCondition writes `column operation value`, multi-value operations take a JSON array
+func formatCondition(c *Condition) (string, error) {
+	if c.IsNull() {
+		return fmt.Sprintf("%s %s %s", c.Column, c.Operator, nullKeyword), nil
+	}
+	if operationsMapped[c.Operator].IsMultiValue {
+		elems := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err != nil {
+				return "", err
+			}
+			elems[i] = strings.TrimSpace(buf.String())
+		}
+		return fmt.Sprintf("%s %s [%s]", c.Column, c.Operator, strings.Join(elems, ", ")), nil
+	}
+	switch v := c.Values[0].(type) {
+	case float64:
+		return fmt.Sprintf("%s %s %s", c.Column, c.Operator, strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case string:
+		// strings are not unescaped, they are quoted with a quote they do not hold
+		switch {
+		case strings.HasSuffix(v, `\`):
+		case !strings.Contains(v, `"`):
+			return fmt.Sprintf(`%s %s "%s"`, c.Column, c.Operator, v), nil
+		case !strings.Contains(v, `'`):
+			return fmt.Sprintf(`%s %s '%s'`, c.Column, c.Operator, v), nil
+		}
+		return "", UnexpectedTokenError{Token: "string the rqe grammar cannot quote", Line: c.Line, Pos: c.Pos}
+	default:
+		return fmt.Sprintf("%s %s %v", c.Column, c.Operator, v), nil
+	}
+}
@@ -0,0 +1,41 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecompile(t *testing.T) {
+	cases := map[string]string{
+		`age >= 18 AND status IN ('active', 'pending') AND deleted_at IS NULL`:       `age gte 18 and status in ["active", "pending"] and deleted_at eq null`,
+		`u.name = 'O''Brien' or ("u"."score" BETWEEN -1.5 AND 2e3 and flag <> TRUE)`: `name eq "O'Brien" or (score between [-1.5, 2000] and flag ne "true")`,
+		`NOT (email LIKE '%@x.io') AND email is not null and id not in (1, 2)`:       `not (email like "%@x.io") and email ne null and id nin [1, 2]`,
+		"title NOT ILIKE 'say \"hi\"%'\nAND [order] != 3":                            `not (title ilike 'say "hi"%') and order ne 3`,
+		`   `: ``,
+	}
+	for sql, filter := range cases {
+		got, err := Decompile(sql)
+		assert.NoError(t, err, sql)
+		assert.Equal(t, filter, got, sql)
+	}
+
+	// the filter compiles back to the same conditions
+	filter, err := Decompile(`(age < 30 OR age > 60) AND city IN ('Paris', 'Oslo, NO')`)
+	assert.NoError(t, err)
+	q, err := Parse(filter, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(age < ? or age > ?) and city IN (?, ?)", q.SQL)
+	assert.Equal(t, []any{int64(30), int64(60), "Paris", "Oslo, NO"}, q.Args)
+
+	_, err = Decompile(`lower(name) = 'x'`)
+	assert.Equal(t, UnexpectedTokenError{Token: "function lower", Line: 1, Pos: 0}, err)
+	_, err = Decompile(`name = ?`)
+	assert.Equal(t, UnexpectedTokenError{Token: "?", Line: 1, Pos: 7}, err)
+	_, err = Decompile(`(age = 1`)
+	assert.Equal(t, UnmatchedParenthesisError{Type: "opening", Line: 1, Pos: 0}, err)
+	_, err = Decompile(`age = 1 AND`)
+	assert.IsType(t, &LogicalTokenError{}, err)
+	_, err = Decompile(`name = 'it''s "x"'`)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+}
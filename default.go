@@ -0,0 +1,39 @@
+package rqe
+
+import (
+	"slices"
+	"sync"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultOpts   []Option
+	defaultParser = NewParser()
+)
+
+// Configure sets the options of the Default parser used by the package level functions
+// (Parse, ParseExpr, Report). It replaces any previous configuration and is safe to call
+// while other goroutines parse, typically once at startup.
+func Configure(opts ...Option) {
+	p := NewParser(opts...)
+	defaultMu.Lock()
+	defaultOpts, defaultParser = slices.Clone(opts), p
+	defaultMu.Unlock()
+}
+
+// Default returns the parser used by the package level functions, see Configure
+func Default() *Parser {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultParser
+}
+
+// withDefaults returns the Default parser, extended with opts when there are any
+func withDefaults(opts []Option) *Parser {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if len(opts) == 0 {
+		return defaultParser
+	}
+	return NewParser(append(slices.Clone(defaultOpts), opts...)...)
+}
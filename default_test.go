@@ -0,0 +1,43 @@
+package rqe
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	defer Configure()
+
+	Configure(Postgres)
+	q, err := Parse(`a eq 1 and b eq 2`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = $1 and b = $2", q.SQL)
+
+	// call options extend the default configuration
+	q, err = Parse(`a eq 1 and b eq 2`, validateColumn, WithInlineEnum("a", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, "a = 1 and b = $1", q.SQL)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%5 == 0 {
+				Configure(Postgres)
+				return
+			}
+			_, err := Parse(`a eq 1`, validateColumn)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	Configure()
+	q, err = Parse(`a eq 1`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = ?", q.SQL)
+	assert.Same(t, Default(), withDefaults(nil))
+}
@@ -0,0 +1,24 @@
+package rqe
+
+// maskedColumn replaces the column name of InvalidColumnError once WithMaskedColumnErrors is set
+const maskedColumn = "***"
+
+// denied reports whether the column is on the deny-list, see WithDeniedColumns
+func (p *Parser) denied(col string) bool {
+	_, ok := p.deniedColumns[col]
+	return ok
+}
+
+// columnError is the error returned for a column the filter cannot use. Denied columns get a
+// DeniedColumnError unless errors are masked, in which case every column error looks the same
+// and does not echo the column, so clients probing for sensitive columns learn nothing.
+func (p *Parser) columnError(col string, line, column int) error {
+	switch {
+	case p.maskColumns:
+		return InvalidColumnError{Column: maskedColumn, Line: line, Pos: column}
+	case p.denied(col):
+		return DeniedColumnError{Column: col, Line: line, Pos: column}
+	default:
+		return InvalidColumnError{Column: col, Line: line, Pos: column}
+	}
+}
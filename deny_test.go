@@ -0,0 +1,32 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeniedColumns(t *testing.T) {
+	allowAll := func(string) bool { return true }
+	p := NewParser(WithDeniedColumns("password_hash", "ssn"))
+
+	_, err := p.Parse(`name eq "Jo" and ssn eq "123"`, allowAll)
+	assert.Equal(t, DeniedColumnError{Column: "ssn", Line: 1, Pos: 17}, err)
+	_, err = p.ParseJSON([]byte(`{"password_hash": "x"}`), allowAll)
+	assert.IsType(t, DeniedColumnError{}, err)
+	_, err = p.Parse(`(ssn, name) gt ["1", "a"]`, allowAll)
+	assert.IsType(t, DeniedColumnError{}, err)
+
+	_, err = p.Begin(allowAll).OrderBy("ssn", false).Finish()
+	assert.IsType(t, SortColumnError{}, err)
+	_, err = p.Begin(allowAll).Select("password_hash").Finish()
+	assert.IsType(t, FieldColumnError{}, err)
+
+	// masked errors look the same for denied and unknown columns
+	masked := NewParser(WithSchema(Schema{"name": {Capabilities: Filterable}, "ssn": {Capabilities: Filterable}}), WithDeniedColumns("ssn"), WithMaskedColumnErrors())
+	_, denied := masked.Parse(`ssn eq "123"`, nil)
+	_, unknown := masked.Parse(`salary eq 1`, nil)
+	assert.Equal(t, InvalidColumnError{Column: "***", Line: 1, Pos: 0}, denied)
+	assert.Equal(t, denied, unknown)
+	assert.NotContains(t, denied.Error(), "ssn")
+}
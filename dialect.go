@@ -0,0 +1,149 @@
+package rqe
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect describes how a database spells bind parameters and operations.
+// The zero value is the generic dialect, binding with `?` and the default operation SQL.
+type Dialect struct {
+	Name string
+	// Placeholder renders the n-th bind parameter (starting at 1), nil keeps `?`
+	Placeholder func(n int) string
+	// Operators overrides the SQL of operations for the dialect, rendered like OperationMeta.Format.
+	// It takes precedence over OperationMeta.Dialects and is meant for custom dialects.
+	Operators map[string]func(col string, quotes int) string
+	// Quote, when set, quotes column names (e.g. `[name]`)
+	Quote func(ident string) string
+	// Concat joins SQL expressions into a string, nil uses CONCAT(...)
+	Concat func(parts []string) string
+}
+
+var (
+	// PostgresDialect numbers its parameters `$1, $2 ...` as expected by pgx and lib/pq
+	PostgresDialect = Dialect{
+		Name:        "postgres",
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	}
+	// MySQLDialect binds with `?`
+	MySQLDialect = Dialect{
+		Name: "mysql",
+	}
+	// MSSQLDialect binds with `@p1, @p2 ...` and quotes columns with brackets,
+	// pass the arguments through MSSQLArgs
+	MSSQLDialect = Dialect{
+		Name:        "mssql",
+		Placeholder: func(n int) string { return fmt.Sprintf("@p%d", n) },
+		Quote:       func(ident string) string { return "[" + strings.ReplaceAll(ident, "]", "]]") + "]" },
+	}
+	// OracleDialect binds with `:1, :2 ...` as expected by godror. Columns are quoted upper cased,
+	// which matches unquoted (case insensitive) names while protecting reserved words like `level`.
+	OracleDialect = Dialect{
+		Name:        "oracle",
+		Placeholder: func(n int) string { return fmt.Sprintf(":%d", n) },
+		Quote:       func(ident string) string { return `"` + strings.ToUpper(strings.ReplaceAll(ident, `"`, `""`)) + `"` },
+		// CONCAT only takes two arguments on oracle
+		Concat: func(parts []string) string { return strings.Join(parts, " || ") },
+	}
+	// BigQueryDialect binds with the named parameters `@p1, @p2 ...` and quotes columns with backticks,
+	// pass the arguments through rqebigquery.Params
+	BigQueryDialect = Dialect{
+		Name:        "bigquery",
+		Placeholder: func(n int) string { return fmt.Sprintf("@p%d", n) },
+		Quote:       func(ident string) string { return "`" + strings.ReplaceAll(ident, "`", "\\`") + "`" },
+	}
+)
+
+var (
+	// Postgres is a shortcut for WithDialect(PostgresDialect), e.g. `rqe.Parse(filter, validateCol, rqe.Postgres)`
+	Postgres = WithDialect(PostgresDialect)
+	// MySQL is a shortcut for WithDialect(MySQLDialect)
+	MySQL = WithDialect(MySQLDialect)
+	// MSSQL is a shortcut for WithDialect(MSSQLDialect)
+	MSSQL = WithDialect(MSSQLDialect)
+	// Oracle is a shortcut for WithDialect(OracleDialect)
+	Oracle = WithDialect(OracleDialect)
+	// BigQuery is a shortcut for WithDialect(BigQueryDialect)
+	BigQuery = WithDialect(BigQueryDialect)
+)
+
+// MSSQLArgs wraps the arguments in sql.Named values matching the `@pN` placeholders of MSSQLDialect
+func MSSQLArgs(args []interface{}) []interface{} {
+	named := make([]interface{}, len(args))
+	for i, arg := range args {
+		named[i] = sql.Named(fmt.Sprintf("p%d", i+1), arg)
+	}
+	return named
+}
+
+// BigQueryParams names the arguments after the `@pN` parameters of BigQueryDialect as sql.NamedArg values.
+//
+// Deprecated: use rqebigquery.Params, which returns the client's []bigquery.QueryParameter.
+func BigQueryParams(args []interface{}) []sql.NamedArg {
+	params := make([]sql.NamedArg, len(args))
+	for i, arg := range args {
+		params[i] = sql.Named(fmt.Sprintf("p%d", i+1), arg)
+	}
+	return params
+}
+
+// concat joins the SQL expressions into a string
+func (d Dialect) concat(parts []string) string {
+	if d.Concat != nil {
+		return d.Concat(parts)
+	}
+	return fmt.Sprintf("CONCAT(%s)", strings.Join(parts, ", "))
+}
+
+// ident quotes the column name when the dialect requires it
+func (d Dialect) ident(col string) string {
+	if d.Quote == nil {
+		return col
+	}
+	return d.Quote(col)
+}
+
+// operationSQL renders the comparison of the operation against the column in the dialect
+func (d Dialect) operationSQL(name, col string, quotes int) string {
+	if format, ok := d.Operators[name]; ok {
+		return format(col, quotes)
+	}
+	op := operationsMapped[name]
+	if format := op.Dialects[d.Name]; format != nil {
+		return format(col, quotes)
+	}
+	return op.sql(col, quotes)
+}
+
+// supports reports whether the dialect can compile the operation
+func (d Dialect) supports(name string) bool {
+	if _, ok := d.Operators[name]; ok {
+		return true
+	}
+	format, ok := operationsMapped[name].Dialects[d.Name]
+	return !ok || format != nil
+}
+
+// bind rewrites the `?` placeholders of the SQL with the dialect's own, in order.
+// Question marks inside single quoted literals are left untouched.
+func (d Dialect) bind(sql string) string {
+	if d.Placeholder == nil {
+		return sql
+	}
+	var sb strings.Builder
+	n, quoted := 0, false
+	for _, r := range sql {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			sb.WriteString(d.Placeholder(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
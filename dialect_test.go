@@ -0,0 +1,104 @@
+package rqe
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresDialect(t *testing.T) {
+	q, err := Parse(`(name eq "john" and age between [18, 30]) or status in ["a", "b"]`, validateColumn, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "(name = $1 and age BETWEEN $2 AND $3) or status IN ($4, $5)", q.SQL)
+	assert.Len(t, q.Args, 5)
+
+	q, err = Parse(`flags bor 6 and manager_id nseq 3`, validateColumn, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "(flags | $1) = $2 and manager_id IS NOT DISTINCT FROM $3", q.SQL)
+
+	b, err := NewParser(Postgres).Begin(validateColumn).
+		Where("tenant_id = ?", 7).
+		Filter(`age gte 18`).
+		Filter(`name eq "x"`).
+		Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = $1) AND (age >= $2) AND (name = $3)", b.Where)
+}
+
+func TestMySQLDialect(t *testing.T) {
+	q, err := Parse(`manager_id nseq 3 and age gt 1`, validateColumn, MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "manager_id <=> ? and age > ?", q.SQL)
+}
+
+func TestDialectBind(t *testing.T) {
+	assert.Equal(t, "a = $1 and b LIKE '?%' and c = $2", PostgresDialect.bind("a = ? and b LIKE '?%' and c = ?"))
+	assert.Equal(t, "a = ?", Dialect{}.bind("a = ?"))
+}
+
+func TestMSSQLDialect(t *testing.T) {
+	q, err := Parse(`(name eq "john" or deleted_at eq null) and manager_id nseq 3 and (a, b) gt [1, 2]`, validateColumn, MSSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "([name] = @p1 or [deleted_at] IS NULL) and EXISTS (SELECT [manager_id] INTERSECT SELECT @p2) and ([a] > @p3 or ([a] = @p4 and [b] > @p5))", q.SQL)
+	assert.Equal(t, []interface{}{
+		sql.Named("p1", "john"),
+		sql.Named("p2", int64(3)),
+		sql.Named("p3", float64(1)),
+		sql.Named("p4", float64(1)),
+		sql.Named("p5", float64(2)),
+	}, MSSQLArgs(q.Args))
+}
+
+func TestOracleDialect(t *testing.T) {
+	q, err := Parse(`level gte 3 and flags band 4 and flags bor 6 and manager_id nseq null and boss nseq 1`, validateColumn, Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, `"LEVEL" >= :1 and BITAND("FLAGS", :2) <> 0 and ("FLAGS" + :3 - BITAND("FLAGS", :4)) = :5 and "MANAGER_ID" IS NULL and DECODE("BOSS", :6, 1, 0) = 1`, q.SQL)
+	assert.Equal(t, []interface{}{int64(3), int64(4), int64(6), int64(6), int64(6), int64(1)}, q.Args)
+}
+
+func TestDialectOperations(t *testing.T) {
+	filter := `name ilike "jo%" and code regex "^[A-Z]+$"`
+	cases := []struct {
+		opt      Option
+		expected string
+	}{
+		{WithDialect(Dialect{}), "LOWER(name) LIKE LOWER(?) and code REGEXP ?"},
+		{Postgres, "name ILIKE $1 and code ~ $2"},
+		{MySQL, "LOWER(name) LIKE LOWER(?) and code REGEXP ?"},
+		{Oracle, `LOWER("NAME") LIKE LOWER(:1) and REGEXP_LIKE("CODE", :2)`},
+	}
+	for _, c := range cases {
+		q, err := Parse(filter, validateColumn, c.opt)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, q.SQL)
+	}
+
+	_, err := Parse(`code regex "x"`, validateColumn, MSSQL)
+	assert.IsType(t, InvalidOperationError{}, err)
+	_, err = Parse(`ip in_subnet "10.0.0.0/8"`, validateColumn, MySQL)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	custom := Dialect{Name: "mssql", Operators: map[string]func(col string, quotes int) string{
+		"regex": func(col string, _ int) string { return "dbo.RegexMatch(" + col + ", ?) = 1" },
+	}}
+	q, err := Parse(`code regex "x"`, validateColumn, WithDialect(custom))
+	assert.NoError(t, err)
+	assert.Equal(t, "dbo.RegexMatch(code, ?) = 1", q.SQL)
+}
+
+func TestBigQueryDialect(t *testing.T) {
+	q, err := Parse("name eq \"x\" and code regex \"^A\" and (a, b) gt [1, 2]", validateColumn, BigQuery)
+	assert.NoError(t, err)
+	assert.Equal(t, "`name` = @p1 and REGEXP_CONTAINS(`code`, @p2) and (`a` > @p3 or (`a` = @p4 and `b` > @p5))", q.SQL)
+	assert.Equal(t, []sql.NamedArg{
+		sql.Named("p1", "x"),
+		sql.Named("p2", "^A"),
+		sql.Named("p3", float64(1)),
+		sql.Named("p4", float64(1)),
+		sql.Named("p5", float64(2)),
+	}, BigQueryParams(q.Args))
+
+	_, err = Parse(`ip in_subnet "10.0.0.0/8"`, validateColumn, BigQuery)
+	assert.IsType(t, InvalidOperationError{}, err)
+}
@@ -0,0 +1,31 @@
+package rqe
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+)
+
+// digestOperations are the equality operations that can be answered by comparing digests
+var digestOperations = map[string]struct{}{
+	"eq":  {},
+	"ne":  {},
+	"in":  {},
+	"nin": {},
+}
+
+// digestValues are the md5 of every value, the digest column is compared against them instead,
+// e.g. `body eq "..."` is `body_md5 = ?` bound to the hex digest
+func digestValues(values []any) []any {
+	vals := make([]any, len(values))
+	for i, v := range values {
+		vals[i] = Digest(v)
+	}
+	return vals
+}
+
+// Digest is the lower case hex md5 of the value as stored in digest columns, see WithDigestColumns
+func Digest(val any) string {
+	sum := md5.Sum([]byte(fmt.Sprint(val)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,141 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dynamoComparisons are the operations with a DynamoDB comparator
+var dynamoComparisons = map[string]string{
+	"eq":   "=",
+	"nseq": "=",
+	"ne":   "<>",
+	"lt":   "<",
+	"lte":  "<=",
+	"gt":   ">",
+	"gte":  ">=",
+}
+
+// DynamoFilter is a DynamoDB filter (or condition) expression with its placeholders
+type DynamoFilter struct {
+	Expression string
+	// Names are the ExpressionAttributeNames, every attribute is aliased so reserved words
+	// (`name`, `status`, `size` ...) never clash
+	Names map[string]string
+	// Values are the ExpressionAttributeValues, marshal them with attributevalue.MarshalMap
+	Values map[string]any
+}
+
+// ParseToDynamo parses the filter into a DynamoDB filter expression, see ToDynamo
+func ParseToDynamo(filter string, validateCol func(col string) bool, opts ...Option) (DynamoFilter, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return DynamoFilter{}, err
+	}
+	return ToDynamo(expr)
+}
+
+// ToDynamo converts the expression into a DynamoDB FilterExpression. `null` checks compile to
+// attribute_not_exists / attribute_exists, operations DynamoDB cannot express return an
+// UnsupportedOperationError. A nil expression gives an empty expression.
+func ToDynamo(expr Expr) (DynamoFilter, error) {
+	d := &dynamoCompiler{out: DynamoFilter{Names: make(map[string]string), Values: make(map[string]any)}}
+	var sb strings.Builder
+	if expr != nil {
+		if err := d.compile(&sb, expr, false); err != nil {
+			return DynamoFilter{}, err
+		}
+	}
+	d.out.Expression = sb.String()
+	return d.out, nil
+}
+
+type dynamoCompiler struct {
+	out DynamoFilter
+}
+
+func (d *dynamoCompiler) compile(sb *strings.Builder, expr Expr, nested bool) error {
+	switch e := expr.(type) {
+	case *Condition:
+		cond, err := d.condition(e)
+		sb.WriteString(cond)
+		return err
+	case *Logical:
+		if nested {
+			sb.WriteString("(")
+		}
+		for i, child := range e.Exprs {
+			if i > 0 {
+				sb.WriteString(" " + strings.ToUpper(e.Operator) + " ")
+			}
+			if err := d.compile(sb, child, true); err != nil {
+				return err
+			}
+		}
+		if nested {
+			sb.WriteString(")")
+		}
+		return nil
+	case *Not:
+		sb.WriteString("NOT (")
+		if err := d.compile(sb, e.Expr, false); err != nil {
+			return err
+		}
+		sb.WriteString(")")
+		return nil
+	case *Tuple:
+		return d.compile(sb, e.Expanded, nested)
+	default:
+		return UnsupportedOperationError{Backend: "dynamodb", Operation: asOfKeyword}
+	}
+}
+
+func (d *dynamoCompiler) condition(c *Condition) (string, error) {
+	name := d.name(c.Column)
+	if c.IsNull() {
+		if c.Operator == "ne" {
+			return fmt.Sprintf("attribute_exists(%s)", name), nil
+		}
+		return fmt.Sprintf("attribute_not_exists(%s)", name), nil
+	}
+	if comparator, ok := dynamoComparisons[c.Operator]; ok {
+		return fmt.Sprintf("%s %s %s", name, comparator, d.value(c.Column, c.Values[0])), nil
+	}
+
+	values := make([]string, len(c.Values))
+	for i, v := range c.Values {
+		values[i] = d.value(c.Column, v)
+	}
+	switch c.Operator {
+	case "in":
+		return fmt.Sprintf("%s IN (%s)", name, strings.Join(values, ", ")), nil
+	case "nin":
+		return fmt.Sprintf("NOT (%s IN (%s))", name, strings.Join(values, ", ")), nil
+	case "between":
+		return fmt.Sprintf("%s BETWEEN %s AND %s", name, values[0], values[1]), nil
+	case "nbetween":
+		return fmt.Sprintf("NOT (%s BETWEEN %s AND %s)", name, values[0], values[1]), nil
+	case "from_until":
+		return fmt.Sprintf("%s >= %s AND %s < %s", name, values[0], name, values[1]), nil
+	case "contains":
+		return fmt.Sprintf("contains(%s, %s)", name, values[0]), nil
+	case "prefix":
+		return fmt.Sprintf("begins_with(%s, %s)", name, values[0]), nil
+	default:
+		return "", UnsupportedOperationError{Backend: "dynamodb", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+	}
+}
+
+// name aliases the attribute as `#column`
+func (d *dynamoCompiler) name(col string) string {
+	alias := "#" + paramName(col)
+	d.out.Names[alias] = col
+	return alias
+}
+
+// value binds the value as `:column_N`
+func (d *dynamoCompiler) value(col string, v any) string {
+	placeholder := fmt.Sprintf(":%s_%d", paramName(col), len(d.out.Values))
+	d.out.Values[placeholder] = v
+	return placeholder
+}
@@ -0,0 +1,26 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToDynamo(t *testing.T) {
+	f, err := ParseToDynamo(`status eq "paid" and (size between [1, 3] or name prefix "jo") and deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "#status = :status_0 AND (#size BETWEEN :size_1 AND :size_2 OR begins_with(#name, :name_3)) AND attribute_not_exists(#deleted_at)", f.Expression)
+	assert.Equal(t, map[string]string{"#status": "status", "#size": "size", "#name": "name", "#deleted_at": "deleted_at"}, f.Names)
+	assert.Equal(t, map[string]any{":status_0": "paid", ":size_1": float64(1), ":size_2": float64(3), ":name_3": "jo"}, f.Values)
+
+	f, err = ParseToDynamo(`not (tags contains "x") and kind nin ["a"]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (contains(#tags, :tags_0)) AND NOT (#kind IN (:kind_1))", f.Expression)
+
+	f, err = ParseToDynamo(``, validateColumn)
+	assert.NoError(t, err)
+	assert.Empty(t, f.Expression)
+
+	_, err = ParseToDynamo(`name like "x%"`, validateColumn)
+	assert.IsType(t, UnsupportedOperationError{}, err)
+}
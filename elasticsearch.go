@@ -0,0 +1,135 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// esRanges are the operations mapping to a single range bound
+var esRanges = map[string]string{
+	"lt":  "lt",
+	"lte": "lte",
+	"gt":  "gt",
+	"gte": "gte",
+}
+
+// esMaxFuzziness is the largest edit distance elasticsearch fuzzy queries accept
+const esMaxFuzziness = 2
+
+// esWildcardEscaper escapes the wildcard query metacharacters
+var esWildcardEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`)
+
+// ElasticsearchBackend compiles federated filters with ToElasticsearch
+var ElasticsearchBackend Backend = BackendFunc(func(expr Expr) (any, error) {
+	return ToElasticsearch(expr)
+})
+
+// ParseToElasticsearch parses the filter into an Elasticsearch query, see ToElasticsearch
+func ParseToElasticsearch(filter string, validateCol func(col string) bool, opts ...Option) (map[string]any, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return nil, err
+	}
+	return ToElasticsearch(expr)
+}
+
+// ToElasticsearch converts the expression into an Elasticsearch Query DSL clause built from
+// bool (must / should / must_not), term(s), range, wildcard ... queries, ready to be marshalled
+// as the `query` of a search request. A nil expression matches every document.
+// Operations elasticsearch cannot express return an UnsupportedOperationError.
+func ToElasticsearch(expr Expr) (map[string]any, error) {
+	switch e := expr.(type) {
+	case nil:
+		return map[string]any{"match_all": map[string]any{}}, nil
+	case *Condition:
+		return esCondition(e)
+	case *Logical:
+		clauses := make([]any, len(e.Exprs))
+		for i, child := range e.Exprs {
+			clause, err := ToElasticsearch(child)
+			if err != nil {
+				return nil, err
+			}
+			clauses[i] = clause
+		}
+		if e.Operator == "and" {
+			return esBool("must", clauses...), nil
+		}
+		query := esBool("should", clauses...)
+		query["bool"].(map[string]any)["minimum_should_match"] = 1
+		return query, nil
+	case *Not:
+		clause, err := ToElasticsearch(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return esBool("must_not", clause), nil
+	case *Tuple:
+		return ToElasticsearch(e.Expanded)
+	default:
+		return nil, UnsupportedOperationError{Backend: "elasticsearch", Operation: asOfKeyword}
+	}
+}
+
+func esCondition(c *Condition) (map[string]any, error) {
+	exists := map[string]any{"exists": map[string]any{"field": c.Column}}
+	if c.IsNull() {
+		if c.Operator == "ne" {
+			return exists, nil
+		}
+		return esBool("must_not", exists), nil
+	}
+	if bound, ok := esRanges[c.Operator]; ok {
+		return esRange(c.Column, map[string]any{bound: c.Values[0]}), nil
+	}
+
+	term := map[string]any{"term": map[string]any{c.Column: c.Values[0]}}
+	terms := map[string]any{"terms": map[string]any{c.Column: c.Values}}
+	between := esRange(c.Column, map[string]any{"gte": c.Values[0], "lte": c.Values[len(c.Values)-1]})
+	switch c.Operator {
+	case "eq", "nseq":
+		return term, nil
+	case "ne":
+		return esBool("must_not", term), nil
+	case "in":
+		return terms, nil
+	case "nin":
+		return esBool("must_not", terms), nil
+	case "between":
+		return between, nil
+	case "nbetween":
+		return esBool("must_not", between), nil
+	case "from_until":
+		return esRange(c.Column, map[string]any{"gte": c.Values[0], "lt": c.Values[1]}), nil
+	case "like", "ilike":
+		pattern := strings.NewReplacer("%", "*", "_", "?").Replace(esWildcardEscaper.Replace(fmt.Sprint(c.Values[0])))
+		return esWildcard(c.Column, pattern, c.Operator == "ilike"), nil
+	case "contains":
+		return esWildcard(c.Column, "*"+esWildcardEscaper.Replace(fmt.Sprint(c.Values[0]))+"*", false), nil
+	case "prefix":
+		return map[string]any{"prefix": map[string]any{c.Column: c.Values[0]}}, nil
+	case "regex":
+		return map[string]any{"regexp": map[string]any{c.Column: c.Values[0]}}, nil
+	case "within_edits":
+		if c.Values[1].(int64) <= esMaxFuzziness {
+			return map[string]any{"fuzzy": map[string]any{c.Column: map[string]any{"value": c.Values[0], "fuzziness": c.Values[1]}}}, nil
+		}
+	}
+	return nil, UnsupportedOperationError{Backend: "elasticsearch", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+}
+
+func esBool(occur string, clauses ...any) map[string]any {
+	return map[string]any{"bool": map[string]any{occur: clauses}}
+}
+
+func esRange(col string, bounds map[string]any) map[string]any {
+	return map[string]any{"range": map[string]any{col: bounds}}
+}
+
+func esWildcard(col, pattern string, caseInsensitive bool) map[string]any {
+	query := map[string]any{"value": pattern}
+	if caseInsensitive {
+		query["case_insensitive"] = true
+	}
+	return map[string]any{"wildcard": map[string]any{col: query}}
+}
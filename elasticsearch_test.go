@@ -0,0 +1,45 @@
+package rqe
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToElasticsearch(t *testing.T) {
+	query, err := ParseToElasticsearch(`name eq "john" and (age between [18, 30] or status nin ["a", "b"]) and deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	out, err := json.Marshal(query)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"bool": {"must": [
+		{"term": {"name": "john"}},
+		{"bool": {"should": [
+			{"range": {"age": {"gte": 18, "lte": 30}}},
+			{"bool": {"must_not": [{"terms": {"status": ["a", "b"]}}]}}
+		], "minimum_should_match": 1}},
+		{"bool": {"must_not": [{"exists": {"field": "deleted_at"}}]}}
+	]}}`, string(out))
+
+	fuzzy := WithCapabilities(CapabilityFuzzyStrMatch)
+	for filter, expected := range map[string]string{
+		`age gt 3`:                     `{"range": {"age": {"gt": 3}}}`,
+		`name ilike "jo*%n_"`:          `{"wildcard": {"name": {"value": "jo\\**n?", "case_insensitive": true}}}`,
+		`name contains "a?"`:           `{"wildcard": {"name": {"value": "*a\\?*"}}}`,
+		`name prefix "jo"`:             `{"prefix": {"name": "jo"}}`,
+		`name within_edits ["jon", 2]`: `{"fuzzy": {"name": {"value": "jon", "fuzziness": 2}}}`,
+		`not (a ne null)`:              `{"bool": {"must_not": [{"exists": {"field": "a"}}]}}`,
+		`(a, b) overlaps [1, 2]`:       `{"bool": {"must": [{"range": {"a": {"lt": 2}}}, {"range": {"b": {"gt": 1}}}]}}`,
+		``:                             `{"match_all": {}}`,
+	} {
+		query, err := ParseToElasticsearch(filter, validateColumn, fuzzy)
+		assert.NoError(t, err, filter)
+		out, _ := json.Marshal(query)
+		assert.JSONEq(t, expected, string(out), filter)
+	}
+
+	for _, filter := range []string{`name within_edits ["jon", 3]`, `flags band 1`, `ip in_subnet "10.0.0.0/8"`} {
+		_, err = ParseToElasticsearch(filter, validateColumn, fuzzy, Postgres)
+		assert.IsType(t, UnsupportedOperationError{}, err, filter)
+	}
+}
@@ -0,0 +1,28 @@
+package rqe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ETag returns a weak ETag for a filtered collection built from the query cache key and
+// a caller supplied data version (last updated timestamp, row version ... etc)
+func ETag(req CacheKeyRequest, version string) string {
+	sum := sha256.Sum256([]byte(CacheKey(req) + ":" + version))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ETagMatches reports whether an If-None-Match header value matches the etag using the
+// weak comparison, in which case the handler can answer 304 Not Modified
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package rqe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	req := CacheKeyRequest{Filter: mustParse(t, `a eq 1 and b eq 2`), Limit: 10}
+	same := CacheKeyRequest{Filter: mustParse(t, `b eq 2 and a eq 1`), Limit: 10}
+
+	etag := ETag(req, "v1")
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+	assert.Equal(t, etag, ETag(same, "v1"))
+	assert.NotEqual(t, etag, ETag(req, "v2"))
+
+	assert.True(t, ETagMatches(etag, etag))
+	assert.True(t, ETagMatches(`"other", `+strings.TrimPrefix(etag, "W/"), etag))
+	assert.True(t, ETagMatches("*", etag))
+	assert.False(t, ETagMatches(ETag(req, "v2"), etag))
+	assert.False(t, ETagMatches("", etag))
+}
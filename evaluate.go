@@ -0,0 +1,473 @@
+package rqe
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Predicate is a filter compiled for evaluation against in-memory records, e.g. to filter a
+// cache or assert on fixtures with the same filter strings the API accepts.
+// It is safe for concurrent use.
+type Predicate struct {
+	expr     Expr
+	folds    map[string]func(string) string
+	patterns map[*Condition]*regexp.Regexp
+}
+
+// Evaluate reports whether the record matches the filter, every column is allowed.
+// See Predicate.Match for the semantics.
+func Evaluate(filter string, record map[string]any, opts ...Option) (bool, error) {
+	pred, err := NewPredicate(filter, func(string) bool { return true }, opts...)
+	if err != nil {
+		return false, err
+	}
+	return pred.Match(record)
+}
+
+// NewPredicate parses the filter into a Predicate
+func NewPredicate(filter string, validateCol func(col string) bool, opts ...Option) (*Predicate, error) {
+	return withDefaults(opts).Predicate(filter, validateCol)
+}
+
+// Predicate parses the filter into a Predicate using the parser's configuration, patterns
+// (`like`, `regex` ...) are compiled once here
+func (p *Parser) Predicate(filter string, validateCol func(col string) bool) (*Predicate, error) {
+	expr, err := p.ParseExpr(filter, validateCol)
+	if err != nil {
+		return nil, err
+	}
+	// folded columns ignore case and accents, CaseInsensitive ones only case, like in SQL
+	pred := &Predicate{expr: expr, folds: make(map[string]func(string) string), patterns: make(map[*Condition]*regexp.Regexp)}
+	for col, c := range p.schema {
+		if c.CaseInsensitive {
+			pred.folds[col] = strings.ToLower
+		}
+	}
+	for col := range p.folded {
+		pred.folds[col] = foldString
+	}
+
+	Walk(expr, func(c *Condition) {
+		if err != nil || c.IsNull() {
+			return
+		}
+		var pattern string
+		switch c.Operator {
+		case "like":
+			pattern = "^" + likeToRegexp(pred.fold(c.Column, fmt.Sprint(c.Values[0]))) + "$"
+		case "ilike":
+			pattern = "(?i)^" + likeToRegexp(pred.fold(c.Column, fmt.Sprint(c.Values[0]))) + "$"
+		case "regex":
+			// lowering the expression would change its escapes (`\D`), it ignores case instead
+			pattern = fmt.Sprint(c.Values[0])
+			if _, ok := p.folded[c.Column]; ok {
+				pattern = "(?i)" + unaccent(pattern)
+			} else if _, ok := pred.folds[c.Column]; ok {
+				pattern = "(?i)" + pattern
+			}
+		default:
+			return
+		}
+		re, compileErr := regexp.Compile("(?s)" + pattern)
+		if compileErr != nil {
+			err = InvalidValueError{Column: c.Column, Operation: c.Operator, Reason: compileErr.Error(), Line: c.Line, Pos: c.Pos}
+			return
+		}
+		pred.patterns[c] = re
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pred, nil
+}
+
+// Match reports whether the record matches. Missing keys are null : they only match
+// `eq null`, like in SQL any other comparison against them is unknown, which `not`, `and` and
+// `or` propagate with SQL's three valued logic so `not (a eq 1)` does not match either.
+// Operations that cannot run in memory (`asof`) return an UnsupportedOperationError.
+func (pr *Predicate) Match(record map[string]any) (bool, error) {
+	if pr.expr == nil {
+		return true, nil
+	}
+	t, err := pr.match(pr.expr, record)
+	return t == truthTrue, err
+}
+
+// truth is a value of SQL's three valued logic
+type truth uint8
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (pr *Predicate) match(expr Expr, record map[string]any) (truth, error) {
+	switch e := expr.(type) {
+	case *Condition:
+		actual := record[e.Column]
+		// comparing null is unknown, except for the null checks and null safe equality
+		if actual == nil && !e.IsNull() && e.Operator != "nseq" {
+			return truthUnknown, nil
+		}
+		ok, err := pr.matchCondition(e, actual)
+		return truthOf(ok), err
+	case *Logical:
+		// false decides an and, true an or, unknown wins over the other value
+		decisive := truthOf(e.Operator == "or")
+		result := truthOf(e.Operator == "and")
+		for _, child := range e.Exprs {
+			t, err := pr.match(child, record)
+			if err != nil {
+				return truthFalse, err
+			}
+			if t == decisive {
+				return t, nil
+			}
+			if t == truthUnknown {
+				result = truthUnknown
+			}
+		}
+		return result, nil
+	case *Not:
+		t, err := pr.match(e.Expr, record)
+		switch t {
+		case truthTrue:
+			return truthFalse, err
+		case truthFalse:
+			return truthTrue, err
+		}
+		return t, err
+	case *Tuple:
+		return pr.match(e.Expanded, record)
+	default:
+		return truthFalse, UnsupportedOperationError{Backend: "memory", Operation: asOfKeyword}
+	}
+}
+
+// fold folds the string like the column is in SQL, it is unchanged when the column is not folded
+func (pr *Predicate) fold(col, s string) string {
+	if fold, ok := pr.folds[col]; ok {
+		return fold(s)
+	}
+	return s
+}
+
+// foldString is the in memory `LOWER(unaccent(s))`
+func foldString(s string) string {
+	return strings.ToLower(unaccent(s))
+}
+
+// unaccent strips the combining marks of the decomposed string, `José` is `Jose`
+func unaccent(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		return s
+	}
+	return folded
+}
+
+func (pr *Predicate) matchCondition(c *Condition, actual any) (bool, error) {
+	if c.IsNull() {
+		return (actual == nil) == (c.Operator != "ne"), nil
+	}
+	if actual == nil {
+		return false, nil
+	}
+	// both sides of a folded column are folded, patterns already were in Predicate
+	values := c.Values
+	if fold, ok := pr.folds[c.Column]; ok {
+		if s, isString := actual.(string); isString {
+			actual = fold(s)
+		}
+		values = make([]any, len(c.Values))
+		for i, v := range c.Values {
+			if s, isString := v.(string); isString {
+				v = fold(s)
+			}
+			values[i] = v
+		}
+	}
+
+	cmp := func(i int) (int, bool) {
+		return compareValues(actual, values[i])
+	}
+	equal := func(i int) bool {
+		n, ok := cmp(i)
+		return ok && n == 0
+	}
+
+	switch c.Operator {
+	case "eq", "nseq":
+		return equal(0), nil
+	case "ne":
+		n, ok := cmp(0)
+		return ok && n != 0, nil
+	case "lt", "lte", "gt", "gte":
+		n, ok := cmp(0)
+		if !ok {
+			return false, nil
+		}
+		switch c.Operator {
+		case "lt":
+			return n < 0, nil
+		case "lte":
+			return n <= 0, nil
+		case "gt":
+			return n > 0, nil
+		default:
+			return n >= 0, nil
+		}
+	case "in", "nin":
+		for i := range c.Values {
+			if equal(i) {
+				return c.Operator == "in", nil
+			}
+		}
+		return c.Operator == "nin", nil
+	case "between", "nbetween":
+		low, okLow := cmp(0)
+		high, okHigh := cmp(1)
+		if !okLow || !okHigh {
+			return false, nil
+		}
+		return (low >= 0 && high <= 0) == (c.Operator == "between"), nil
+	case "from_until":
+		low, okLow := cmp(0)
+		high, okHigh := cmp(1)
+		return okLow && okHigh && low >= 0 && high < 0, nil
+	case "band", "bor":
+		bits, ok := toInt64(actual)
+		mask, _ := toInt64(c.Values[0])
+		if !ok {
+			return false, nil
+		}
+		if c.Operator == "band" {
+			return bits&mask != 0, nil
+		}
+		return bits|mask == mask, nil
+	case "like", "ilike", "regex":
+		s, ok := actual.(string)
+		return ok && pr.patterns[c].MatchString(s), nil
+	case "contains", "prefix":
+		s, ok := actual.(string)
+		if !ok {
+			return false, nil
+		}
+		if c.Operator == "prefix" {
+			return strings.HasPrefix(s, fmt.Sprint(values[0])), nil
+		}
+		return strings.Contains(s, fmt.Sprint(values[0])), nil
+	case "within_edits":
+		s, ok := actual.(string)
+		maxEdits, _ := toInt64(c.Values[1])
+		return ok && levenshtein(s, fmt.Sprint(values[0])) <= int(maxEdits), nil
+	case "sounds_like":
+		s, ok := actual.(string)
+		return ok && soundex(s) == soundex(fmt.Sprint(values[0])), nil
+	case "in_subnet":
+		_, subnet, err := net.ParseCIDR(fmt.Sprint(c.Values[0]))
+		if err != nil {
+			return false, InvalidValueError{Column: c.Column, Operation: c.Operator, Reason: err.Error(), Line: c.Line, Pos: c.Pos}
+		}
+		ip, ok := actual.(net.IP)
+		if !ok {
+			ip = net.ParseIP(fmt.Sprint(actual))
+		}
+		return ip != nil && subnet.Contains(ip), nil
+	default:
+		return false, UnsupportedOperationError{Backend: "memory", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+	}
+}
+
+// compareValues orders a record value against a filter value. Numbers compare whatever their
+// Go type, times compare with RFC 3339 strings. The second return is false for values of
+// unrelated types, which never match.
+func compareValues(a, b any) (int, bool) {
+	if x, ok := toFloat64(a); ok {
+		y, ok := toFloat64(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	switch x := a.(type) {
+	case time.Time:
+		other, ok := toTime(b)
+		if !ok {
+			return 0, false
+		}
+		return x.Compare(other), true
+	case string:
+		if other, ok := b.(time.Time); ok {
+			t, ok := toTime(x)
+			return t.Compare(other), ok
+		}
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if x == y {
+			return 0, true
+		}
+		if y {
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func toInt64(v any) (int64, bool) {
+	f, ok := toFloat64(v)
+	if !ok || f != float64(int64(f)) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// likeToRegexp translates a LIKE pattern, `%` matches any run of characters and `_` a single one
+func likeToRegexp(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// levenshtein is the number of single character edits between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// soundexCodes are the american soundex digits of the consonants
+var soundexCodes = map[rune]byte{
+	'b': '1', 'f': '1', 'p': '1', 'v': '1',
+	'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+	'd': '3', 't': '3',
+	'l': '4',
+	'm': '5', 'n': '5',
+	'r': '6',
+}
+
+// soundex is the american soundex code of the word, as computed by SQL's SOUNDEX
+func soundex(word string) string {
+	word = strings.ToLower(word)
+	first, size := utf8.DecodeRuneInString(word)
+	if first == utf8.RuneError {
+		return ""
+	}
+	code := []byte(strings.ToUpper(string(first)))
+	last := soundexCodes[first]
+	for _, r := range word[size:] {
+		digit, ok := soundexCodes[r]
+		switch {
+		case ok && digit != last:
+			code = append(code, digit)
+		case r == 'h' || r == 'w':
+			// h and w do not separate consonants with the same code
+			continue
+		}
+		last = digit
+		if len(code) == 4 {
+			break
+		}
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
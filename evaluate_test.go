@@ -0,0 +1,119 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	record := map[string]any{
+		"name":       "Jonathan",
+		"age":        30,
+		"flags":      6,
+		"status":     "active",
+		"ip":         "10.1.2.3",
+		"created_at": time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{``, true},
+		{`name eq "Jonathan" and age gte 18`, true},
+		{`age gt 30 or status in ["active", "pending"]`, true},
+		{`age between [31, 40]`, false},
+		{`age nbetween [31, 40]`, true},
+		{`status nin ["banned"]`, true},
+		{`name like "Jon%"`, true},
+		{`name ilike "jon_than"`, true},
+		{`name regex "^J.*n$"`, true},
+		{`name contains "nat" and name prefix "Jo"`, true},
+		{`name within_edits ["jonathon", 2]`, true},
+		{`name sounds_like "Jonathon"`, true},
+		{`ip in_subnet "10.0.0.0/8"`, true},
+		{`flags band 2 and flags bor 7`, true},
+		{`created_at gt "2024-02-01T00:00:00Z"`, true},
+		{`not (age lt 18)`, true},
+		{`(age, status) eq [30, "active"]`, true},
+		{`deleted_at eq null and name ne null`, true},
+		// comparisons against a missing key are false, like SQL
+		{`deleted_at ne "x"`, false},
+		{`age eq "thirty"`, false},
+	}
+	for _, tc := range cases {
+		got, err := Evaluate(tc.filter, record, Postgres, WithCapabilities(CapabilityFuzzyStrMatch))
+		assert.NoError(t, err, tc.filter)
+		assert.Equal(t, tc.want, got, tc.filter)
+	}
+}
+
+func TestPredicate(t *testing.T) {
+	pred, err := NewPredicate(`name eq "bob" and age gte 18`, validateColumn, WithFoldedColumns("name"))
+	assert.NoError(t, err)
+
+	match, err := pred.Match(map[string]any{"name": "BOB", "age": int64(20)})
+	assert.NoError(t, err)
+	assert.True(t, match)
+	match, err = pred.Match(map[string]any{"name": "bob", "age": 17.5})
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	// folded columns fold both sides, patterns included
+	record := map[string]any{"name": "José Martín"}
+	for _, filter := range []string{
+		`name eq "jose martin"`,
+		`name in ["x", "JOSE MARTIN"]`,
+		`name contains "Mart"`,
+		`name prefix "JOSÉ"`,
+		`name like "Jos_ M%"`,
+		`name regex "^JOSE.MART"`,
+		`name within_edits ["JOSE MARTEN", 1]`,
+	} {
+		match, err := Evaluate(filter, record, WithFoldedColumns("name"), WithCapabilities(CapabilityFuzzyStrMatch))
+		assert.NoError(t, err, filter)
+		assert.True(t, match, filter)
+	}
+	ci := WithSchema(Schema{"name": {Capabilities: Filterable | Searchable, CaseInsensitive: true}})
+	match, err = Evaluate(`name contains "MARTÍN" and name regex "^josé"`, record, ci)
+	assert.NoError(t, err)
+	assert.True(t, match)
+	match, err = Evaluate(`name contains "MARTIN"`, record, ci)
+	assert.NoError(t, err)
+	assert.False(t, match)
+
+	_, err = NewPredicate(`name regex "("`, validateColumn)
+	assert.IsType(t, InvalidValueError{}, err)
+
+	_, err = NewPredicate(`password eq "x"`, func(col string) bool { return col != "password" })
+	assert.IsType(t, InvalidColumnError{}, err)
+}
+
+func TestEvaluateNull(t *testing.T) {
+	record := map[string]any{"b": 1}
+	cases := map[string]bool{
+		// comparisons against null are unknown, so is their negation
+		`not (a eq 1)`:               false,
+		`not (a ne 1)`:               false,
+		`not (a in [1, 2])`:          false,
+		`not (a eq 1 and b eq 1)`:    false,
+		`not (a eq 1 and b eq 2)`:    true,
+		`not (a eq 1 or b eq 1)`:     false,
+		`a eq 1 or b eq 1`:           true,
+		`not (a eq 1) or b eq 1`:     true,
+		`not (not (a eq 1))`:         false,
+		`not (a eq null)`:            false,
+		`not (a ne null)`:            true,
+		`not (a nseq 1)`:             true,
+		`not ((a, b) eq [1, 1])`:     false,
+		`not (a eq 1 or b eq 2)`:     false,
+		`not (a eq null and b eq 1)`: false,
+		`not (a eq null and b eq 2)`: true,
+	}
+	for filter, want := range cases {
+		got, err := Evaluate(filter, record)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, want, got, filter)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/baderkha/rqe"
+	"github.com/baderkha/rqe/rqegorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// User is the API model, its rqe tags declare what clients may filter, sort and select
+type User struct {
+	ID        int       `json:"id" rqe:"filterable,sortable,projectable"`
+	Name      string    `json:"name" rqe:"filterable,searchable,sortable,projectable"`
+	Email     string    `json:"email" rqe:"filterable,projectable,ops=eq|in"`
+	Age       int       `json:"age" rqe:"filterable,sortable,projectable"`
+	CreatedAt time.Time `json:"created_at" rqe:"filterable,sortable,projectable"`
+	// PasswordHash has no tag, it can never be filtered on nor selected
+	PasswordHash string `json:"-"`
+}
+
+// page is the body of a list response
+type page[T any] struct {
+	Data   []T `json:"data"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// SQL is the statement GORM would run, only set in dry run mode
+	SQL string `json:"sql,omitempty"`
+}
+
+// newHandler routes the API, every route goes through the error middleware
+func newHandler(db *gorm.DB) (http.Handler, error) {
+	schema, err := rqe.NewSchema[User]()
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /users", withErrors(listUsers(db, schema.Schema)))
+	return mux, nil
+}
+
+// listUsers answers `GET /users?filter=...&sort=...&fields=...&limit=...&offset=...`
+func listUsers(db *gorm.DB, schema rqe.Schema) handlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+		limit, offset, err := pagination(query.Get("limit"), query.Get("offset"))
+		if err != nil {
+			return err
+		}
+		sorts, err := schema.ParseSort(query.Get("sort"))
+		if err != nil {
+			return err
+		}
+		fields, err := schema.ParseFields(query.Get("fields"))
+		if err != nil {
+			return err
+		}
+
+		tx := db.WithContext(r.Context()).Model(&User{}).
+			Scopes(rqegorm.Scope(query.Get("filter"), schema)).
+			Limit(limit).
+			Offset(offset)
+		for _, s := range sorts {
+			tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: column(schema, s.Column)}, Desc: s.Desc})
+		}
+		if len(fields) > 0 {
+			cols := make([]string, len(fields))
+			for i, field := range fields {
+				cols[i] = column(schema, field)
+			}
+			tx = tx.Select(cols)
+		}
+
+		users := make([]User, 0)
+		if err := tx.Find(&users).Error; err != nil {
+			return err
+		}
+		out := page[User]{Data: users, Limit: limit, Offset: offset}
+		if tx.DryRun {
+			out.SQL = tx.Statement.SQL.String()
+		}
+		return writeJSON(w, http.StatusOK, out)
+	}
+}
+
+// column is the SQL column of an API column
+func column(schema rqe.Schema, col string) string {
+	if name := schema[col].DBName; name != "" {
+		return name
+	}
+	return col
+}
+
+// pagination parses the limit and offset, the limit defaults to 20 and is capped to 100
+func pagination(limitParam, offsetParam string) (int, int, error) {
+	limit, offset := defaultLimit, 0
+	var err error
+	if limitParam != "" {
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			return 0, 0, rqe.InvalidPaginationError{Limit: -1}
+		}
+	}
+	if offsetParam != "" {
+		if offset, err = strconv.Atoi(offsetParam); err != nil {
+			return 0, 0, rqe.InvalidPaginationError{Limit: limit, Offset: -1}
+		}
+	}
+	if limit < 1 || limit > maxLimit || offset < 0 {
+		return 0, 0, rqe.InvalidPaginationError{Limit: limit, Offset: offset}
+	}
+	return limit, offset, nil
+}
+
+// handlerFunc is a handler returning its error to the middleware
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// withErrors is the middleware turning errors into JSON responses. Filter errors are the
+// client's fault and point at the offending part of the filter, anything else is a 500.
+func withErrors(next handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := next(w, r)
+		if err == nil {
+			return
+		}
+
+		var parseErr rqe.ParseError
+		var sortErr rqe.SortColumnError
+		var fieldErr rqe.FieldColumnError
+		var pageErr rqe.InvalidPaginationError
+		switch {
+		case errors.As(err, &parseErr):
+			_ = writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error":  parseErr.Error(),
+				"detail": parseErr.Pretty(r.URL.Query().Get("filter")),
+			})
+		case errors.As(err, &sortErr), errors.As(err, &fieldErr), errors.As(err, &pageErr):
+			_ = writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			_ = writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, handler http.Handler, params url.Values) (int, map[string]any) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?"+params.Encode(), nil))
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestListUsers(t *testing.T) {
+	db, err := openDB()
+	assert.NoError(t, err)
+	handler, err := newHandler(db)
+	assert.NoError(t, err)
+
+	code, body := get(t, handler, url.Values{
+		"filter": {`age gte 18 and name prefix "jo"`},
+		"sort":   {"-created_at,name"},
+		"fields": {"id,name"},
+		"limit":  {"10"},
+		"offset": {"20"},
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "SELECT `id`,`name` FROM `users` WHERE age >= ? and name LIKE ? ESCAPE '!' ORDER BY `created_at` DESC,`name` LIMIT ? OFFSET ?", body["sql"])
+	assert.Equal(t, []any{}, body["data"])
+
+	code, body = get(t, handler, url.Values{"filter": {`password_hash eq "x"`}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body["detail"], "hint:")
+
+	code, _ = get(t, handler, url.Values{"sort": {"email"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = get(t, handler, url.Values{"limit": {"1000"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
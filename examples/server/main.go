@@ -0,0 +1,38 @@
+// Command server is a reference HTTP API filtering, sorting, projecting and paginating a GORM
+// model with rqe :
+//
+//	GET /users?filter=age gte 18 and name prefix "jo"&sort=-created_at&fields=id,name&limit=20&offset=40
+//
+// It runs GORM in dry run mode so it needs no database and answers with the SQL it would run,
+// open a real dialector in openDB (e.g. postgres.Open(dsn)) to serve rows.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
+
+	db, err := openDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler, err := newHandler(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
+
+// openDB opens the database, swap the dialector for a real one to query rows
+func openDB() (*gorm.DB, error) {
+	return gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+}
@@ -0,0 +1,100 @@
+package rqe
+
+import "slices"
+
+// Backend compiles the part of a filter a single store evaluates (a SQL database,
+// a search index ... etc) into whatever that store queries with
+type Backend interface {
+	CompileFilter(expr Expr) (any, error)
+}
+
+// BackendFunc adapts a function into a Backend
+type BackendFunc func(expr Expr) (any, error)
+
+func (f BackendFunc) CompileFilter(expr Expr) (any, error) {
+	return f(expr)
+}
+
+// SQLBackend compiles with the parser (and therefore its dialect) into a ParsedQuery
+func SQLBackend(p *Parser) Backend {
+	return BackendFunc(func(expr Expr) (any, error) {
+		return p.Compile(expr), nil
+	})
+}
+
+// MergeStrategy is how the results of a FederatedPlan are combined
+type MergeStrategy string
+
+const (
+	// MergeSingle means a single backend answers the whole filter, its results are final
+	MergeSingle MergeStrategy = "single"
+	// MergeIntersect means the results of the queries must be stitched on their shared key,
+	// only rows present in every result match
+	MergeIntersect MergeStrategy = "intersect"
+	// MergeScan means nothing could be pushed down, every backend has to be read in full
+	MergeScan MergeStrategy = "scan"
+)
+
+// FederatedQuery is the part of the filter sent to one backend
+type FederatedQuery struct {
+	Backend string
+	Filter  Expr
+	// Query is what the backend compiled the filter into (a ParsedQuery for SQLBackend)
+	Query any
+}
+
+// FederatedPlan describes how to answer a filter spanning several backends
+type FederatedPlan struct {
+	// Queries are ordered by backend name
+	Queries []FederatedQuery
+	Merge   MergeStrategy
+	// Residual has to be evaluated on the merged rows, nil when the queries are enough
+	Residual Expr
+}
+
+// Federation compiles filters over columns living in different backends
+type Federation struct {
+	columns  map[string]string
+	backends map[string]Backend
+}
+
+// NewFederation creates a Federation, columns maps every column to the name of the backend
+// storing it and backends holds the compiler of each backend
+func NewFederation(columns map[string]string, backends map[string]Backend) *Federation {
+	return &Federation{columns: columns, backends: backends}
+}
+
+// Plan splits the filter per backend (see Split), compiles each part with its backend
+// and describes how the results are merged
+func (f *Federation) Plan(expr Expr) (FederatedPlan, error) {
+	split := Split(expr, f.columns)
+	plan := FederatedPlan{Residual: split.Residual}
+
+	names := make([]string, 0, len(split.Tables))
+	for name := range split.Tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		backend, ok := f.backends[name]
+		if !ok {
+			return FederatedPlan{}, FederationError{Backend: name, Reason: "backend is not registered"}
+		}
+		query, err := backend.CompileFilter(split.Tables[name])
+		if err != nil {
+			return FederatedPlan{}, err
+		}
+		plan.Queries = append(plan.Queries, FederatedQuery{Backend: name, Filter: split.Tables[name], Query: query})
+	}
+
+	switch {
+	case len(plan.Queries) == 0:
+		plan.Merge = MergeScan
+	case len(plan.Queries) == 1 && plan.Residual == nil:
+		plan.Merge = MergeSingle
+	default:
+		plan.Merge = MergeIntersect
+	}
+	return plan, nil
+}
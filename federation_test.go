@@ -0,0 +1,44 @@
+package rqe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederation(t *testing.T) {
+	search := BackendFunc(func(expr Expr) (any, error) {
+		return Anonymize(expr), nil
+	})
+	federation := NewFederation(
+		map[string]string{"name": "postgres", "age": "postgres", "bio": "search"},
+		map[string]Backend{"postgres": SQLBackend(NewParser(Postgres)), "search": search},
+	)
+
+	plan, err := federation.Plan(mustParse(t, `name eq "x" and bio sounds_like "go" and age gt 3`))
+	assert.NoError(t, err)
+	assert.Equal(t, MergeIntersect, plan.Merge)
+	assert.Nil(t, plan.Residual)
+	assert.Len(t, plan.Queries, 2)
+	assert.Equal(t, "postgres", plan.Queries[0].Backend)
+	assert.Equal(t, "name = $1 and age > $2", plan.Queries[0].Query.(ParsedQuery).SQL)
+	assert.Equal(t, "search", plan.Queries[1].Backend)
+	assert.Equal(t, `bio sounds_like ?`, plan.Queries[1].Query)
+
+	plan, err = federation.Plan(mustParse(t, `name eq "x"`))
+	assert.NoError(t, err)
+	assert.Equal(t, MergeSingle, plan.Merge)
+
+	plan, err = federation.Plan(mustParse(t, `name eq "x" or bio eq "y"`))
+	assert.NoError(t, err)
+	assert.Equal(t, MergeScan, plan.Merge)
+	assert.NotNil(t, plan.Residual)
+
+	_, err = NewFederation(map[string]string{"name": "mongo"}, nil).Plan(mustParse(t, `name eq "x"`))
+	assert.IsType(t, FederationError{}, err)
+
+	failing := BackendFunc(func(Expr) (any, error) { return nil, errors.New("down") })
+	_, err = NewFederation(map[string]string{"name": "a"}, map[string]Backend{"a": failing}).Plan(mustParse(t, `name eq "x"`))
+	assert.EqualError(t, err, "down")
+}
@@ -0,0 +1,91 @@
+package rqe
+
+// firestoreOperators are the operations mapping to a single Firestore `Where` operator
+var firestoreOperators = map[string]string{
+	"eq":   "==",
+	"nseq": "==",
+	"ne":   "!=",
+	"lt":   "<",
+	"lte":  "<=",
+	"gt":   ">",
+	"gte":  ">=",
+	"in":   "in",
+	"nin":  "not-in",
+}
+
+// FirestoreWhere is a single `Where(Path, Op, Value)` clause of a Firestore query
+type FirestoreWhere struct {
+	Path  string
+	Op    string
+	Value any
+}
+
+// ParseToFirestore parses the filter into Firestore where clauses, see ToFirestore
+func ParseToFirestore(filter string, validateCol func(col string) bool, opts ...Option) ([]FirestoreWhere, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return nil, err
+	}
+	return ToFirestore(expr)
+}
+
+// ToFirestore converts the expression into the chain of `Where` clauses of a Firestore query :
+//
+//	for _, w := range clauses {
+//		query = query.Where(w.Path, w.Op, w.Value)
+//	}
+//
+// Firestore only chains conjunctions, so `or`, `not` and operations it cannot express return an
+// UnsupportedOperationError, `contains` included : Firestore has no substring match and its
+// `array-contains` tests array membership. A nil expression gives no clauses.
+func ToFirestore(expr Expr) ([]FirestoreWhere, error) {
+	switch e := expr.(type) {
+	case nil:
+		return []FirestoreWhere{}, nil
+	case *Condition:
+		return firestoreCondition(e)
+	case *Logical:
+		if e.Operator != "and" {
+			return nil, UnsupportedOperationError{Backend: "firestore", Operation: e.Operator}
+		}
+		clauses := make([]FirestoreWhere, 0, len(e.Exprs))
+		for _, child := range e.Exprs {
+			where, err := ToFirestore(child)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, where...)
+		}
+		return clauses, nil
+	case *Not:
+		return nil, UnsupportedOperationError{Backend: "firestore", Operation: notKeyword}
+	case *Tuple:
+		return ToFirestore(e.Expanded)
+	default:
+		return nil, UnsupportedOperationError{Backend: "firestore", Operation: asOfKeyword}
+	}
+}
+
+func firestoreCondition(c *Condition) ([]FirestoreWhere, error) {
+	if c.IsNull() {
+		return []FirestoreWhere{{Path: c.Column, Op: firestoreOperators[c.Operator], Value: nil}}, nil
+	}
+	if op, ok := firestoreOperators[c.Operator]; ok {
+		value := c.Values[0]
+		if op == "in" || op == "not-in" {
+			value = c.Values
+		}
+		return []FirestoreWhere{{Path: c.Column, Op: op, Value: value}}, nil
+	}
+	if c.Operator == "between" || c.Operator == "from_until" {
+		upper := "<="
+		if c.Operator == "from_until" {
+			upper = "<"
+		}
+		return []FirestoreWhere{
+			{Path: c.Column, Op: ">=", Value: c.Values[0]},
+			{Path: c.Column, Op: upper, Value: c.Values[1]},
+		}, nil
+	}
+	return nil, UnsupportedOperationError{Backend: "firestore", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+}
@@ -0,0 +1,35 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToFirestore(t *testing.T) {
+	where, err := ParseToFirestore(`status in ["paid", "sent"] and (age between [18, 30] and tier eq "vip") and deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, []FirestoreWhere{
+		{Path: "status", Op: "in", Value: []any{"paid", "sent"}},
+		{Path: "age", Op: ">=", Value: float64(18)},
+		{Path: "age", Op: "<=", Value: float64(30)},
+		{Path: "tier", Op: "==", Value: "vip"},
+		{Path: "deleted_at", Op: "==", Value: nil},
+	}, where)
+
+	where, err = ParseToFirestore(``, validateColumn)
+	assert.NoError(t, err)
+	assert.Empty(t, where)
+
+	_, err = ParseToFirestore(`age gt 3 or name eq "bob"`, validateColumn)
+	assert.Equal(t, UnsupportedOperationError{Backend: "firestore", Operation: "or"}, err)
+
+	_, err = ParseToFirestore(`not (age gt 3)`, validateColumn)
+	assert.Equal(t, UnsupportedOperationError{Backend: "firestore", Operation: "not"}, err)
+
+	_, err = ParseToFirestore(`name like "bo%"`, validateColumn)
+	assert.IsType(t, UnsupportedOperationError{}, err)
+
+	_, err = ParseToFirestore(`tags contains "vip"`, validateColumn)
+	assert.Equal(t, UnsupportedOperationError{Backend: "firestore", Operation: "contains", Column: "tags", Line: 1, Pos: 0}, err)
+}
@@ -0,0 +1,41 @@
+package rqe
+
+// AndFragments ANDs the named fragments (see WithFragment) onto the expression, e.g. to scope a
+// client filter to the caller's tenant before compiling it. The fragments' values are merged
+// with the filter's so placeholders are numbered once for the whole query. expr may be nil.
+func (p *Parser) AndFragments(expr Expr, names ...string) (Expr, error) {
+	exprs := make([]Expr, 0, len(names)+1)
+	if l, ok := expr.(*Logical); ok && l.Operator == "and" {
+		exprs = append(exprs, l.Exprs...)
+	} else if expr != nil {
+		exprs = append(exprs, expr)
+	}
+	for _, name := range names {
+		fragment, ok := p.fragments[name]
+		if !ok {
+			return nil, UnknownFragmentError{Name: name}
+		}
+		exprs = append(exprs, fragment)
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return exprs[0], nil
+	default:
+		return &Logical{Operator: "and", Exprs: exprs}, nil
+	}
+}
+
+// ParseWithFragments parses the filter and ANDs the named fragments onto it, see AndFragments
+func (p *Parser) ParseWithFragments(filter string, validateCol func(col string) bool, names ...string) (ParsedQuery, error) {
+	expr, err := p.ParseExpr(filter, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	if expr, err = p.AndFragments(expr, names...); err != nil {
+		return ParsedQuery{}, err
+	}
+	return p.compileChecked(expr)
+}
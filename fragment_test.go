@@ -0,0 +1,36 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragments(t *testing.T) {
+	active, err := ParseExpr(`deleted_at eq null and status in ["active", "trial"]`, validateColumn)
+	assert.NoError(t, err)
+	parser := NewParser(
+		Postgres,
+		WithFragment("tenant", &Condition{Column: "tenant_id", Operator: "eq", Values: []any{int64(7)}}),
+		WithFragment("active", active),
+	)
+
+	q, err := parser.ParseWithFragments(`age gte 18 and name eq "john"`, validateColumn, "tenant", "active")
+	assert.NoError(t, err)
+	assert.Equal(t, "age >= $1 and name = $2 and tenant_id = $3 and (deleted_at IS NULL and status IN ($4, $5))", q.SQL)
+	assert.Equal(t, []interface{}{int64(18), "john", int64(7), "active", "trial"}, q.Args)
+
+	expr, err := parser.AndFragments(nil, "tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant_id = $1", parser.Compile(expr).SQL)
+
+	b, err := parser.Begin(validateColumn).Filter(`age gte 18`).Fragment("tenant").Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = $1) AND (age >= $2)", b.Where)
+	assert.Equal(t, []interface{}{int64(7), int64(18)}, b.Args)
+
+	_, err = parser.ParseWithFragments(`age gte 18`, validateColumn, "missing")
+	assert.Equal(t, UnknownFragmentError{Name: "missing"}, err)
+	_, err = parser.Begin(validateColumn).Fragment("missing").Finish()
+	assert.Equal(t, UnknownFragmentError{Name: "missing"}, err)
+}
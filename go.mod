@@ -4,11 +4,16 @@ go 1.23.5
 
 require (
 	github.com/bzick/tokenizer v1.4.10
+	github.com/davecgh/go-spew v1.1.1
+	github.com/jmoiron/sqlx v1.4.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/text v0.14.0
+	gorm.io/gorm v1.25.12
 )
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
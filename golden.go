@@ -0,0 +1,50 @@
+package rqe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// goldenQuery is the value free form of a ParsedQuery
+type goldenQuery struct {
+	SQL       string            `json:"sql"`
+	ArgTypes  []string          `json:"arg_types"`
+	Columns   []string          `json:"columns"`
+	AsOf      bool              `json:"as_of"`
+	ShardKeys int               `json:"shard_keys"`
+	NamedArgs map[string]string `json:"named_args,omitempty"`
+}
+
+// GoldenJSON renders the compiled query as indented JSON meant for golden files : the SQL,
+// the Go type of each argument and the columns, but never the argument values. The output only
+// changes when the generated query does, so diffing it across upgrades catches compiler drift.
+func (q ParsedQuery) GoldenJSON() ([]byte, error) {
+	golden := goldenQuery{
+		SQL:       q.SQL,
+		ArgTypes:  make([]string, len(q.Args)),
+		Columns:   q.Columns,
+		AsOf:      q.AsOf != nil,
+		ShardKeys: len(q.ShardKeys),
+	}
+	if golden.Columns == nil {
+		golden.Columns = []string{}
+	}
+	for i, arg := range q.Args {
+		golden.ArgTypes[i] = fmt.Sprintf("%T", arg)
+	}
+	if q.NamedArgs != nil {
+		golden.NamedArgs = make(map[string]string, len(q.NamedArgs))
+		for name, arg := range q.NamedArgs {
+			golden.NamedArgs[name] = fmt.Sprintf("%T", arg)
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(golden); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,43 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldenJSON(t *testing.T) {
+	q, err := Parse(`name eq "bob" and age gt 3`, validateColumn)
+	assert.NoError(t, err)
+	other, err := Parse(`name eq "alice" and age gt 40`, validateColumn)
+	assert.NoError(t, err)
+
+	golden, err := q.GoldenJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "sql": "name = ? and age > ?",
+  "arg_types": [
+    "string",
+    "int64"
+  ],
+  "columns": [
+    "name",
+    "age"
+  ],
+  "as_of": false,
+  "shard_keys": 0
+}
+`, string(golden))
+
+	otherGolden, err := other.GoldenJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, string(golden), string(otherGolden))
+
+	named, err := Parse(`name eq "bob"`, validateColumn, WithNamedArgs())
+	assert.NoError(t, err)
+	golden, err = named.GoldenJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(golden), `"named_args": {
+    "name_0": "string"
+  }`)
+}
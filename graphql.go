@@ -0,0 +1,100 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// graphQLComparisons are the operations mapping to a single Hasura / Prisma style comparison
+var graphQLComparisons = map[string]string{
+	"eq":    "_eq",
+	"nseq":  "_eq",
+	"ne":    "_neq",
+	"lt":    "_lt",
+	"lte":   "_lte",
+	"gt":    "_gt",
+	"gte":   "_gte",
+	"in":    "_in",
+	"nin":   "_nin",
+	"like":  "_like",
+	"ilike": "_ilike",
+	"regex": "_regex",
+}
+
+// graphQLLikeEscaper escapes the LIKE wildcards with the default backslash escape
+var graphQLLikeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ParseToGraphQL parses the filter into a GraphQL where input, see ToGraphQL
+func ParseToGraphQL(filter string, validateCol func(col string) bool, opts ...Option) (map[string]any, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return nil, err
+	}
+	return ToGraphQL(expr)
+}
+
+// ToGraphQL converts the expression into a Hasura style `where` input object (`_and`, `_or`, `_not`,
+// `{"age": {"_gte": 25}}`) a gateway can forward as a GraphQL variable. A nil expression matches
+// every row. Operations without an equivalent (`band`, `sounds_like` ... etc) return an
+// UnsupportedOperationError.
+func ToGraphQL(expr Expr) (map[string]any, error) {
+	switch e := expr.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case *Condition:
+		return graphQLCondition(e)
+	case *Logical:
+		inputs := make([]any, len(e.Exprs))
+		for i, child := range e.Exprs {
+			input, err := ToGraphQL(child)
+			if err != nil {
+				return nil, err
+			}
+			inputs[i] = input
+		}
+		return map[string]any{"_" + e.Operator: inputs}, nil
+	case *Not:
+		input, err := ToGraphQL(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"_not": input}, nil
+	case *Tuple:
+		return ToGraphQL(e.Expanded)
+	default:
+		return nil, UnsupportedOperationError{Backend: "graphql", Operation: asOfKeyword}
+	}
+}
+
+func graphQLCondition(c *Condition) (map[string]any, error) {
+	field := func(v map[string]any) (map[string]any, error) {
+		return map[string]any{c.Column: v}, nil
+	}
+	if c.IsNull() {
+		return field(map[string]any{"_is_null": c.Operator != "ne"})
+	}
+	if op, ok := graphQLComparisons[c.Operator]; ok {
+		if c.Operator == "in" || c.Operator == "nin" {
+			return field(map[string]any{op: c.Values})
+		}
+		return field(map[string]any{op: c.Values[0]})
+	}
+
+	switch c.Operator {
+	case "between":
+		return field(map[string]any{"_gte": c.Values[0], "_lte": c.Values[1]})
+	case "from_until":
+		return field(map[string]any{"_gte": c.Values[0], "_lt": c.Values[1]})
+	case "nbetween":
+		return map[string]any{"_or": []any{
+			map[string]any{c.Column: map[string]any{"_lt": c.Values[0]}},
+			map[string]any{c.Column: map[string]any{"_gt": c.Values[1]}},
+		}}, nil
+	case "contains":
+		return field(map[string]any{"_like": "%" + graphQLLikeEscaper.Replace(fmt.Sprint(c.Values[0])) + "%"})
+	case "prefix":
+		return field(map[string]any{"_like": graphQLLikeEscaper.Replace(fmt.Sprint(c.Values[0])) + "%"})
+	default:
+		return nil, UnsupportedOperationError{Backend: "graphql", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+	}
+}
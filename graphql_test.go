@@ -0,0 +1,34 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToGraphQL(t *testing.T) {
+	where, err := ParseToGraphQL(`name prefix "jo_" and (age between [18, 30] or deleted_at ne null) and not (status in ["a", "b"])`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"_and": []any{
+		map[string]any{"name": map[string]any{"_like": `jo\_%`}},
+		map[string]any{"_or": []any{
+			map[string]any{"age": map[string]any{"_gte": float64(18), "_lte": float64(30)}},
+			map[string]any{"deleted_at": map[string]any{"_is_null": false}},
+		}},
+		map[string]any{"_not": map[string]any{"status": map[string]any{"_in": []any{"a", "b"}}}},
+	}}, where)
+
+	where, err = ParseToGraphQL(``, validateColumn)
+	assert.NoError(t, err)
+	assert.Empty(t, where)
+
+	where, err = ParseToGraphQL(`age nbetween [1, 2]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"_or": []any{
+		map[string]any{"age": map[string]any{"_lt": float64(1)}},
+		map[string]any{"age": map[string]any{"_gt": float64(2)}},
+	}}, where)
+
+	_, err = ParseToGraphQL(`flags band 4`, validateColumn)
+	assert.Equal(t, UnsupportedOperationError{Backend: "graphql", Operation: "band", Column: "flags", Line: 1, Pos: 0}, err)
+}
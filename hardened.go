@@ -0,0 +1,172 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// hardenedLiterals are the only string literals the compiler emits (LIKE escape and wildcards)
+var hardenedLiterals = map[string]struct{}{likeEscape: {}, "%": {}}
+
+// hardenedSymbols are the punctuation and operator characters the compiler emits
+const hardenedSymbols = "=<>!(),&|+-~?"
+
+// assertSafe re-reads the SQL compiled from expr, before it is bound, and fails closed when it
+// holds anything the compiler should never produce : statement separators, comments, unexpected
+// literals, or identifiers that are neither keywords of the built-in operations nor the filtered
+// columns. See WithHardened.
+func (p *Parser) assertSafe(q ParsedQuery, expr Expr) error {
+	columns := make(map[string]struct{}, len(q.Columns))
+	for _, col := range q.Columns {
+		columns[p.column(col)] = struct{}{}
+		if _, ok := p.digests[col]; ok {
+			columns[p.digestColumn(col)] = struct{}{}
+		}
+	}
+	// virtual column expressions, collations and column compilers are trusted server side SQL
+	sql := q.SQL
+	if q.Having != "" {
+		sql += " and " + q.Having
+	}
+	Walk(expr, func(c *Condition) {
+		if c.IsNull() {
+			return
+		}
+		if fragment, _, ok := p.compileWith(c); ok && fragment != "" {
+			sql = strings.ReplaceAll(sql, fragment, "?")
+		}
+	})
+	for _, col := range q.Columns {
+		if expr := p.schema[col].Expression; expr != "" {
+			sql = strings.ReplaceAll(sql, expr, "?")
+		}
+		if collation := p.schema[col].Collation; collation != "" {
+			sql = strings.ReplaceAll(sql, " COLLATE "+collation, "")
+		}
+	}
+	keywords := hardenedKeywords()
+	return scanSQL(sql, func(ident string) error {
+		_, keyword := keywords[ident]
+		_, column := columns[ident]
+		if !keyword && !column {
+			return UnsafeSQLError{SQL: q.SQL, Reason: fmt.Sprintf("unexpected identifier '%s'", ident)}
+		}
+		return nil
+	})
+}
+
+// hardenedKeywords are the keywords and functions the built-in operations of every dialect
+// compile to. Custom dialect operators are deliberately left out.
+var hardenedKeywords = sync.OnceValue(func() map[string]struct{} {
+	templates := []string{"and", "or", "NOT", "CONCAT", foldExpr(""), NewParser().likeSQL("", &LikeWildcards{})}
+	for _, collation := range foldCollations {
+		templates = append(templates, "COLLATE "+collation)
+	}
+	for _, op := range operationsMapped {
+		templates = append(templates, op.sql("", 2), op.NullValue)
+		for _, format := range op.Dialects {
+			if format != nil {
+				templates = append(templates, format("", 2))
+			}
+		}
+	}
+	for _, op := range tupleOperators {
+		templates = append(templates, op)
+	}
+	keywords := make(map[string]struct{})
+	for _, template := range templates {
+		_ = scanSQL(template, func(ident string) error {
+			keywords[ident] = struct{}{}
+			return nil
+		})
+	}
+	return keywords
+})
+
+// scanSQL walks the SQL, calling ident for every identifier or keyword. It fails on anything
+// else than identifiers, numbers, placeholders, the expected literals and operator symbols.
+func scanSQL(sql string, ident func(ident string) error) error {
+	unsafe := func(reason string) error {
+		return UnsafeSQLError{SQL: sql, Reason: reason}
+	}
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == ';':
+			return unsafe("statement separator")
+		case r == '#' || r == '-' && next(runes, i) == '-' || r == '/' && next(runes, i) == '*':
+			return unsafe("comment")
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return unsafe("unterminated literal")
+			}
+			if _, ok := hardenedLiterals[string(runes[i+1:end])]; !ok {
+				return unsafe(fmt.Sprintf("unexpected literal '%s'", string(runes[i+1:end])))
+			}
+			i = end
+		case r == '[' || r == '`' || r == '"':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			end := indexRune(runes, i+1, closing)
+			// qualified names quote every part : "users"."first_name"
+			for end >= 0 && next(runes, end) == '.' && next(runes, end+1) == r {
+				end = indexRune(runes, end+3, closing)
+			}
+			if end < 0 {
+				return unsafe("unterminated identifier")
+			}
+			if err := ident(string(runes[i : end+1])); err != nil {
+				return err
+			}
+			i = end
+		case r == '$' || r == '@' || r == ':':
+			// placeholders : $1, @p1, :1, :name_0
+			for i+1 < len(runes) && isIdentRune(runes[i+1]) {
+				i++
+			}
+		case unicode.IsDigit(r):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < len(runes) && (isIdentRune(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			if err := ident(string(runes[start : i+1])); err != nil {
+				return err
+			}
+		case strings.ContainsRune(hardenedSymbols, r):
+		default:
+			return unsafe(fmt.Sprintf("unexpected character '%c'", r))
+		}
+	}
+	return nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func next(runes []rune, i int) rune {
+	if i+1 < len(runes) {
+		return runes[i+1]
+	}
+	return 0
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
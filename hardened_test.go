@@ -0,0 +1,46 @@
+package rqe
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHardened(t *testing.T) {
+	filters := []string{
+		`name eq "x'; DROP TABLE users; --" and age between [1, 2] or status nin ["a", "b"]`,
+		`not (deleted_at eq null) and email ne null and flags band 4 and flags bor 6`,
+		`name like "%x%" and name ilike "x" and name contains "x" and name prefix "x"`,
+		`name nseq "x" and age nbetween [1, 2] and (a, b) gt [1, 2] and (start, end) overlaps [1, 2]`,
+		`name sounds_like "x"`,
+	}
+	dialects := []Dialect{{}, PostgresDialect, MySQLDialect, MSSQLDialect, OracleDialect, BigQueryDialect}
+	for i, dialect := range dialects {
+		p := NewParser(WithDialect(dialect), WithHardened(), WithSQLPatterns(), WithDigestColumns("name"))
+		if dialect.folds() {
+			WithFoldedColumns("email")(p)
+		}
+		for _, filter := range filters {
+			_, err := p.Parse(filter, validateColumn)
+			assert.NoError(t, err, fmt.Sprintf("dialect %d : %s", i, filter))
+		}
+	}
+
+	_, err := Parse(`ip in_subnet "10.0.0.0/8" and name regex "^a" and name within_edits ["x", 1]`, validateColumn,
+		Postgres, WithHardened(), WithCapabilities(CapabilityFuzzyStrMatch))
+	assert.NoError(t, err)
+
+	// a misconfigured schema or dialect cannot smuggle anything into the query
+	_, err = Parse(`name eq "x"`, nil, WithHardened(), WithSchema(Schema{"name": {Capabilities: Filterable, DBName: "name /* x */"}}))
+	assert.Equal(t, UnsafeSQLError{SQL: "name /* x */ = ?", Reason: "unexpected identifier 'name'"}, err)
+
+	sleepy := Dialect{Operators: map[string]func(col string, quotes int) string{
+		"eq": func(col string, _ int) string { return col + " = ? or pg_sleep(10) = 0" },
+	}}
+	_, err = Parse(`name eq "x"`, validateColumn, WithHardened(), WithDialect(sleepy))
+	assert.Equal(t, UnsafeSQLError{SQL: "name = ? or pg_sleep(10) = 0", Reason: "unexpected identifier 'pg_sleep'"}, err)
+
+	_, err = NewParser(WithHardened(), WithDialect(sleepy)).Begin(validateColumn).Filter(`name eq "x"`).Finish()
+	assert.IsType(t, UnsafeSQLError{}, err)
+}
@@ -0,0 +1,45 @@
+package rqe
+
+// splitHaving splits the expression between the WHERE and HAVING clauses : the `and`ed parts
+// referencing an aggregate column go to HAVING, an `or` mixing both goes to HAVING as a whole
+func (p *Parser) splitHaving(expr Expr) (where, having Expr) {
+	if !p.hasAggregates() {
+		return expr, nil
+	}
+	// nested `and`s (scopes, parentheses) are flattened so each conjunct is classified on its own
+	var whereExprs, havingExprs []Expr
+	for _, conjunct := range andOperands(expr) {
+		aggregate := false
+		Walk(conjunct, func(c *Condition) {
+			aggregate = aggregate || p.schema[c.Column].Aggregate
+		})
+		if aggregate {
+			havingExprs = append(havingExprs, conjunct)
+		} else {
+			whereExprs = append(whereExprs, conjunct)
+		}
+	}
+	return andExprs(whereExprs), andExprs(havingExprs)
+}
+
+// hasAggregates reports whether the schema declares aggregate columns
+func (p *Parser) hasAggregates() bool {
+	for _, col := range p.schema {
+		if col.Aggregate {
+			return true
+		}
+	}
+	return false
+}
+
+// andExprs ANDs the expressions, nil when there are none
+func andExprs(exprs []Expr) Expr {
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return exprs[0]
+	default:
+		return &Logical{Operator: "and", Exprs: exprs}
+	}
+}
@@ -0,0 +1,62 @@
+package rqe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var aggregateSchema = Schema{
+	"name":        {Capabilities: Filterable | Sortable},
+	"order_count": {Capabilities: Filterable | Sortable, Expression: "COUNT(orders.id)", Aggregate: true},
+}
+
+func TestHaving(t *testing.T) {
+	p := NewParser(Postgres, WithSchema(aggregateSchema), WithHardened())
+
+	q, err := p.Parse(`order_count gt 5 and name eq "Jo"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = $1", q.SQL)
+	assert.Equal(t, "COUNT(orders.id) > $2", q.Having)
+	assert.Equal(t, []any{"Jo", int64(5)}, q.Args)
+	assert.Equal(t, []any{int64(5)}, q.HavingArgs)
+
+	// an or mixing both goes to HAVING as a whole
+	q, err = p.Parse(`name eq "Jo" or order_count lt 2`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", q.SQL)
+	assert.Equal(t, "name = $1 or COUNT(orders.id) < $2", q.Having)
+
+	built, err := p.Begin(nil).Filter(`order_count gte 1`).Filter(`name eq "Jo"`).Where("1 = ?", 1).OrderBy("order_count", true).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(1 = $1) AND (name = $2)", built.Where)
+	assert.Equal(t, "(COUNT(orders.id) >= $3)", built.Having)
+	assert.Equal(t, []any{1, "Jo", int64(1)}, built.Args)
+	assert.Equal(t, "COUNT(orders.id) DESC", built.OrderBy)
+
+	// nested ands are split as well, from parentheses or scopes
+	q, err = p.Parse(`(name eq "Jo" and order_count gt 5)`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = $1", q.SQL)
+	assert.Equal(t, "COUNT(orders.id) > $2", q.Having)
+
+	tenant := func(context.Context) (Expr, error) {
+		return &Condition{Column: "name", Operator: "ne", Values: []any{"x"}}, nil
+	}
+	scoped := NewParser(Postgres, WithSchema(aggregateSchema), WithScopes(tenant))
+	q, err = scoped.Parse(`name eq "Jo" and order_count gt 5`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "name <> $1 and name = $2", q.SQL)
+	assert.Equal(t, "COUNT(orders.id) > $3", q.Having)
+
+	// server side fragments and scopes are split like the client filters
+	busy := &Condition{Column: "order_count", Operator: "gte", Values: []any{int64(10)}}
+	server := NewParser(Postgres, WithSchema(aggregateSchema), WithFragment("busy", busy), WithScopes(tenant))
+	built, err = server.Begin(nil).Fragment("busy").Filter(`order_count lt 100`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(name <> $1)", built.Where)
+	assert.Equal(t, "(COUNT(orders.id) >= $2) AND (COUNT(orders.id) < $3)", built.Having)
+	assert.Equal(t, []any{"x", int64(10), int64(100)}, built.Args)
+	assert.Equal(t, []any{int64(10), int64(100)}, built.HavingArgs)
+}
@@ -0,0 +1,33 @@
+package rqe
+
+import "strings"
+
+// Ident checks the name is a plain identifier, optionally schema qualified (`reporting.orders`),
+// and quotes it for the default parser's dialect, e.g. to embed a dynamic table name in a query.
+// Whitelist the name before calling it, see WithTableValidator.
+func Ident(name string, opts ...Option) (string, error) {
+	return withDefaults(opts).Ident(name)
+}
+
+// Ident checks and quotes the name for the parser's dialect, see the package level Ident
+func (p *Parser) Ident(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if !validPluginName(part) {
+			return "", InvalidIdentifierError{Name: name, Reason: "only letters, digits and underscores are allowed"}
+		}
+		parts[i] = p.dialect.ident(part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// table validates the table against the parser's whitelist and quotes it
+func (p *Parser) table(name string) (string, error) {
+	if p.validateTable == nil {
+		return "", InvalidIdentifierError{Name: name, Reason: "no table validator configured"}
+	}
+	if !p.validateTable(name) {
+		return "", InvalidIdentifierError{Name: name, Reason: "table is not allowed"}
+	}
+	return p.Ident(name)
+}
@@ -0,0 +1,35 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdent(t *testing.T) {
+	ident, err := Ident("reporting.orders", MSSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "[reporting].[orders]", ident)
+
+	ident, err = Ident("orders_2024")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders_2024", ident)
+
+	for _, name := range []string{"", "orders; DROP TABLE users", "a..b", "1orders", `"orders"`} {
+		_, err = Ident(name)
+		assert.IsType(t, InvalidIdentifierError{}, err, name)
+	}
+}
+
+func TestBuilderFrom(t *testing.T) {
+	parser := NewParser(Oracle, WithTableValidator(func(table string) bool { return table == "orders" }))
+	q, err := parser.Begin(validateColumn).From("orders").Filter(`age gt 1`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, `"ORDERS"`, q.From)
+
+	_, err = parser.Begin(validateColumn).From("users").Finish()
+	assert.Equal(t, InvalidIdentifierError{Name: "users", Reason: "table is not allowed"}, err)
+
+	_, err = NewParser().Begin(validateColumn).From("orders").Finish()
+	assert.IsType(t, InvalidIdentifierError{}, err)
+}
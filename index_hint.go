@@ -0,0 +1,66 @@
+package rqe
+
+import "fmt"
+
+// IndexHint is the index a column is best served by
+type IndexHint struct {
+	Index string
+	// Force emits FORCE INDEX instead of USE INDEX
+	Force bool
+}
+
+func (h IndexHint) String() string {
+	if h.Force {
+		return fmt.Sprintf("FORCE INDEX (%s)", h.Index)
+	}
+	return fmt.Sprintf("USE INDEX (%s)", h.Index)
+}
+
+// indexHintDialects are the dialects understanding `USE INDEX`, the generic one included
+var indexHintDialects = map[string]struct{}{"": {}, MySQLDialect.Name: {}}
+
+// IndexHintFor returns the hint of the column dominating the filter, that is the hinted column
+// with the most selective condition restricting the whole filter. Conditions under an `or`
+// never dominate. The boolean is false when no hinted column qualifies or the dialect is
+// neither MySQL nor the generic one.
+func (p *Parser) IndexHintFor(expr Expr) (IndexHint, bool) {
+	if _, ok := indexHintDialects[p.dialect.Name]; !ok {
+		return IndexHint{}, false
+	}
+	var (
+		best   IndexHint
+		intent Intent
+	)
+	for _, c := range conjuncts(expr) {
+		hint, ok := p.indexHints[c.Column]
+		if !ok || c.IsNull() {
+			continue
+		}
+		next, narrows := operationIntents[c.Operator]
+		if !narrows || next == IntentSearch {
+			continue
+		}
+		if intent == 0 || next < intent {
+			best, intent = hint, next
+		}
+	}
+	return best, intent != 0
+}
+
+// conjuncts returns every condition joined through `and` to the root, in filter order
+func conjuncts(expr Expr) []*Condition {
+	switch e := expr.(type) {
+	case *Condition:
+		return []*Condition{e}
+	case *Logical:
+		if e.Operator != "and" {
+			return nil
+		}
+		var conds []*Condition
+		for _, child := range e.Exprs {
+			conds = append(conds, conjuncts(child)...)
+		}
+		return conds
+	}
+	return nil
+}
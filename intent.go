@@ -0,0 +1,102 @@
+package rqe
+
+import "slices"
+
+// Intent is the access pattern a filter most likely results in
+type Intent int
+
+const (
+	// IntentPointLookup filters on unique keys and touches a handful of rows
+	IntentPointLookup Intent = iota + 1
+	// IntentRangeScan narrows the rows through ranges or equality on indexed columns
+	IntentRangeScan
+	// IntentSearch relies on pattern / text matching that indexes rarely help with
+	IntentSearch
+	// IntentBroadScan cannot be narrowed by any index and will likely scan the table
+	IntentBroadScan
+)
+
+func (i Intent) String() string {
+	switch i {
+	case IntentPointLookup:
+		return "point_lookup"
+	case IntentRangeScan:
+		return "range_scan"
+	case IntentSearch:
+		return "search"
+	default:
+		return "broad_scan"
+	}
+}
+
+// IntentHints describes the table a filter runs against
+type IntentHints struct {
+	// Keys are unique columns (primary keys, unique indexes)
+	Keys []string
+	// Indexed are columns backed by a non unique index
+	Indexed []string
+}
+
+// operationIntents is how each operation narrows an indexed column
+var operationIntents = map[string]Intent{
+	"eq":         IntentPointLookup,
+	"in":         IntentPointLookup,
+	"nseq":       IntentPointLookup,
+	"lt":         IntentRangeScan,
+	"lte":        IntentRangeScan,
+	"gt":         IntentRangeScan,
+	"gte":        IntentRangeScan,
+	"between":    IntentRangeScan,
+	"from_until": IntentRangeScan,
+	"prefix":     IntentRangeScan,
+
+	"sounds_like": IntentSearch,
+	"like":        IntentSearch,
+	"ilike":       IntentSearch,
+	"regex":       IntentSearch,
+	"contains":    IntentSearch,
+
+	"within_edits": IntentSearch,
+}
+
+// Classify guesses the access pattern of a parsed filter so services can route heavy
+// queries (e.g. to a read replica). Conjunctions are as cheap as their most selective
+// branch while disjunctions are as expensive as their worst one.
+func Classify(expr Expr, hints IntentHints) Intent {
+	switch e := expr.(type) {
+	case *Condition:
+		return classifyCondition(e, hints)
+	case *Logical:
+		intent := Classify(e.Exprs[0], hints)
+		for _, child := range e.Exprs[1:] {
+			next := Classify(child, hints)
+			if (e.Operator == "and" && next < intent) || (e.Operator == "or" && next > intent) {
+				intent = next
+			}
+		}
+		return intent
+	case *Tuple:
+		return Classify(e.Expanded, hints)
+	default:
+		return IntentBroadScan
+	}
+}
+
+func classifyCondition(c *Condition, hints IntentHints) Intent {
+	intent, ok := operationIntents[c.Operator]
+	if !ok || c.IsNull() {
+		return IntentBroadScan
+	}
+	if intent == IntentSearch {
+		return IntentSearch
+	}
+
+	switch {
+	case slices.Contains(hints.Keys, c.Column):
+		return intent
+	case slices.Contains(hints.Indexed, c.Column):
+		return IntentRangeScan
+	default:
+		return IntentBroadScan
+	}
+}
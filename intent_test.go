@@ -0,0 +1,32 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	hints := IntentHints{Keys: []string{"id"}, Indexed: []string{"created_at", "status"}}
+	tests := map[string]Intent{
+		``:                                 IntentBroadScan,
+		`id eq 5`:                          IntentPointLookup,
+		`id in [1, 2, 3]`:                  IntentPointLookup,
+		`id eq 5 and bio ne "x"`:           IntentPointLookup,
+		`id gt 5`:                          IntentRangeScan,
+		`status eq "active"`:               IntentRangeScan,
+		`created_at gte "now-7d"`:          IntentRangeScan,
+		`id eq 5 or bio eq "x"`:            IntentBroadScan,
+		`status ne "active"`:               IntentBroadScan,
+		`deleted_at eq null`:               IntentBroadScan,
+		`(id eq 1 or id eq 2) and x eq 1`:  IntentPointLookup,
+		`status eq "a" or created_at gt 1`: IntentRangeScan,
+	}
+	for filter, expected := range tests {
+		t.Run(filter, func(t *testing.T) {
+			expr, err := ParseExpr(filter, validateColumn)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, Classify(expr, hints), Classify(expr, hints).String())
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package rqe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// jsonMember is a key of a JSON filter object with the byte offsets of the key and its value
+type jsonMember struct {
+	key    string
+	keyPos int
+	valPos int
+	value  json.RawMessage
+}
+
+// ParseJSON parses a filter written as a JSON document, for clients building filters as data
+// rather than strings. Columns are validated against the schema, see Parser.ParseJSONExpr.
+func ParseJSON(data []byte, schema Schema, opts ...Option) (ParsedQuery, error) {
+	return withDefaults(append(opts, WithSchema(schema))).ParseJSON(data, nil)
+}
+
+// ParseJSON parses a JSON filter into a ParsedQuery using the parser's configuration
+func (p *Parser) ParseJSON(data []byte, validateCol func(col string) bool) (ParsedQuery, error) {
+	expr, err := p.ParseJSONExpr(data, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	return p.compileChecked(expr)
+}
+
+// ParseJSONExpr parses a Mongo / Prisma style JSON filter into the same expression tree as the
+// equivalent string filter:
+//
+//	{"and": [{"age": {"gte": 25}}, {"name": {"eq": "John"}}]}
+//
+// `and` / `or` take an array of filters and `not` a filter. Any other key is a column mapping
+// operations to their values, several operations (or columns) in one object are ANDed, and a
+// bare value is short for `eq`. Multi-value operations take an array. Error positions are
+// byte offsets into the document.
+func (p *Parser) ParseJSONExpr(data []byte, validateCol func(col string) bool) (Expr, error) {
+	if err := p.checkLength(len(data)); err != nil {
+		return nil, err
+	}
+	// the document is decoded once whole then once more per object while walking it
+	budget := p.newBudget()
+	if err := budget.charge(len(data) * 3); err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return nil, jsonError(data, int(syntax.Offset), UnexpectedTokenError{Token: "invalid JSON"})
+		}
+		return nil, UnexpectedTokenError{Token: "invalid JSON", Line: 1}
+	}
+	if doc == nil || isEmptyObject(doc) {
+		return p.parsed(context.Background(), nil)
+	}
+
+	jp := &jsonParser{Parser: p, data: data, validateCol: p.columnValidator(validateCol), budget: budget, conditions: p.newConditionCounter()}
+	expr, err := jp.parseFilter(jsonStart(data, 0))
+	if err != nil {
+		return nil, err
+	}
+	return p.parsed(context.Background(), expr)
+}
+
+func isEmptyObject(doc any) bool {
+	obj, ok := doc.(map[string]any)
+	return ok && len(obj) == 0
+}
+
+// jsonParser walks a JSON filter, keeping the whole document to report byte offsets
+type jsonParser struct {
+	*Parser
+	data        []byte
+	validateCol func(col string) bool
+	budget      *memoryBudget
+	conditions  *conditionCounter
+	// depth counts the `and`, `or` and `not` the parser is in
+	depth int
+}
+
+// parseFilter parses the filter object starting at pos
+func (jp *jsonParser) parseFilter(pos int) (Expr, error) {
+	members, ok := jsonObject(jp.data, pos)
+	if !ok || len(members) == 0 {
+		return nil, jsonError(jp.data, pos, UnexpectedTokenError{Token: "filter object"})
+	}
+
+	exprs := make([]Expr, 0, len(members))
+	for _, m := range members {
+		var expr Expr
+		var err error
+		switch m.key {
+		case "and", "or", notKeyword:
+			expr, err = jp.parseGroup(m)
+		default:
+			expr, err = jp.parseColumn(m)
+		}
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &Logical{Operator: "and", Exprs: exprs}, nil
+}
+
+// parseGroup parses an `and`, `or` or `not` member one level deeper
+func (jp *jsonParser) parseGroup(m jsonMember) (Expr, error) {
+	jp.depth++
+	defer func() { jp.depth-- }()
+	if err := jp.checkDepth(jp.depth, 0, 0); err != nil {
+		return nil, jsonError(jp.data, m.keyPos, err)
+	}
+	if m.key != notKeyword {
+		return jp.parseLogical(m)
+	}
+	expr, err := jp.parseFilter(m.valPos)
+	if err != nil {
+		return nil, err
+	}
+	return &Not{Expr: expr}, nil
+}
+
+// parseLogical parses the array of filters of an `and` / `or` member
+func (jp *jsonParser) parseLogical(m jsonMember) (Expr, error) {
+	positions, ok := jsonArray(jp.data, m.valPos)
+	if !ok || len(positions) == 0 {
+		return nil, jsonError(jp.data, m.keyPos, &LogicalTokenError{Reason: m.key + " takes a non empty array of filters"})
+	}
+	exprs := make([]Expr, len(positions))
+	for i, pos := range positions {
+		expr, err := jp.parseFilter(pos)
+		if err != nil {
+			return nil, err
+		}
+		exprs[i] = expr
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &Logical{Operator: m.key, Exprs: exprs}, nil
+}
+
+// parseColumn parses the operations of a column member, `{"age": {"gte": 18, "lt": 65}}` or `{"age": 18}`
+func (jp *jsonParser) parseColumn(m jsonMember) (Expr, error) {
+	line, column := jsonPosition(jp.data, m.keyPos)
+	if jp.validateCol == nil || !jp.validateCol(m.key) {
+		return nil, jp.columnError(m.key, line, column)
+	}
+
+	ops, isObject := jsonObject(jp.data, m.valPos)
+	if !isObject {
+		ops = []jsonMember{{key: "eq", keyPos: m.keyPos, valPos: m.valPos, value: m.value}}
+	}
+	if len(ops) == 0 {
+		return nil, MissingValueError{Column: m.key, Line: line, Pos: column}
+	}
+
+	exprs := make([]Expr, 0, len(ops))
+	for _, op := range ops {
+		cond, err := jp.parseComparison(m.key, op, line, column)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, cond)
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &Logical{Operator: "and", Exprs: exprs}, nil
+}
+
+// parseComparison parses one `"operation": value` of a column, checked like the string grammar
+func (jp *jsonParser) parseComparison(col string, m jsonMember, line, column int) (*Condition, error) {
+	opName, op, err := jp.operation(col, m.key, jp.canonical(m.key), line, column)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(m.value))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, MissingValueError{Column: col, Line: line, Pos: column}
+	}
+
+	var vals []any
+	switch v := value.(type) {
+	case nil:
+		if !jp.allowsNull(col, op) {
+			return nil, InvalidOperationError{Operation: m.key + " " + nullKeyword, Column: col, Line: line, Pos: column + len(col)}
+		}
+		if err := countCondition(jp.budget, jp.conditions, []any{nil}, line, column); err != nil {
+			return nil, err
+		}
+		return &Condition{Column: col, Operator: opName, Values: []any{nil}, Line: line, Pos: column}, nil
+	case []any:
+		if !op.IsMultiValue {
+			return nil, InvalidOperationError{Operation: "multi-value array", Column: col, Line: line, Pos: column}
+		}
+		if len(v) == 0 {
+			return nil, InvalidOperationError{Operation: "multi-value array empty arguments", Column: col, Line: line, Pos: column}
+		}
+		// arrays of the string grammar are decoded from JSON without UseNumber, numbers are float64
+		for _, elem := range v {
+			if n, ok := elem.(json.Number); ok {
+				elem, _ = n.Float64()
+			}
+			vals = append(vals, elem)
+		}
+	case map[string]any:
+		return nil, MissingValueError{Column: col, Line: line, Pos: column}
+	case json.Number:
+		if n, err := v.Int64(); err == nil && !strings.ContainsAny(v.String(), ".eE") {
+			vals = []any{n}
+		} else {
+			f, _ := v.Float64()
+			vals = []any{f}
+		}
+	default:
+		vals = []any{v}
+	}
+
+	if vals, err = jp.resolveValues(col, m.key, opName, op, vals, false, line, column); err != nil {
+		return nil, err
+	}
+	vals = jp.sanitizeValues(opName, op, vals)
+	if err := countCondition(jp.budget, jp.conditions, vals, line, column); err != nil {
+		return nil, err
+	}
+	return &Condition{Column: col, Operator: opName, Values: vals, Line: line, Pos: column}, nil
+}
+
+// jsonObject reads the members of the object starting at pos in order, false when it is not an object
+func jsonObject(data []byte, pos int) ([]jsonMember, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data[pos:]))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	members := make([]jsonMember, 0)
+	for dec.More() {
+		keyPos := jsonStart(data, pos+int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		m := jsonMember{key: tok.(string), keyPos: keyPos, valPos: jsonStart(data, pos+int(dec.InputOffset()))}
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, false
+		}
+		members = append(members, m)
+	}
+	return members, true
+}
+
+// jsonArray returns the offsets of the elements of the array starting at pos, false when it is not an array
+func jsonArray(data []byte, pos int) ([]int, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data[pos:]))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, false
+	}
+	positions := make([]int, 0)
+	for dec.More() {
+		positions = append(positions, jsonStart(data, pos+int(dec.InputOffset())))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, false
+		}
+	}
+	return positions, true
+}
+
+// jsonStart skips the whitespace and separators before the next value
+func jsonStart(data []byte, pos int) int {
+	for pos < len(data) && strings.IndexByte(" \t\r\n,:", data[pos]) >= 0 {
+		pos++
+	}
+	return pos
+}
+
+// jsonPosition converts a byte offset into the line (from 1) and the offset used by the errors
+func jsonPosition(data []byte, pos int) (int, int) {
+	return bytes.Count(data[:pos], []byte("\n")) + 1, pos
+}
+
+// jsonError positions the error at the byte offset of the document
+func jsonError(data []byte, pos int, err error) error {
+	line, column := jsonPosition(data, min(pos, len(data)))
+	switch e := err.(type) {
+	case UnexpectedTokenError:
+		e.Line, e.Pos = line, column
+		return e
+	case *LogicalTokenError:
+		e.Line, e.Pos = line, column
+		return e
+	case DepthLimitExceededError:
+		e.Line, e.Pos = line, column
+		return e
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSON(t *testing.T) {
+	schema := Schema{
+		"age":        {Capabilities: Filterable},
+		"name":       {Capabilities: Filterable},
+		"status":     {Capabilities: Filterable},
+		"deleted_at": {Capabilities: Filterable},
+	}
+
+	q, err := ParseJSON([]byte(`{"and": [{"age": {"gte": 25, "lt": 65}}, {"name": {"eq": "John"}}], "or": [{"status": {"in": ["a", "b"]}}, {"deleted_at": {"eq": null}}]}`), schema, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "((age >= $1 and age < $2) and name = $3) and (status IN ($4, $5) or deleted_at IS NULL)", q.SQL)
+	assert.Equal(t, []interface{}{int64(25), int64(65), "John", "a", "b"}, q.Args)
+
+	// the same tree as the string filter
+	parser := NewParser(WithSchema(schema))
+	fromJSON, err := parser.ParseJSONExpr([]byte(`{"not": {"age": 3, "status": {"nin": [1, 2]}}}`), nil)
+	assert.NoError(t, err)
+	expr, err := parser.ParseExpr(`not (age eq 3 and status nin [1, 2])`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Canonical(expr), Canonical(fromJSON))
+
+	expr, err = parser.ParseJSONExpr([]byte(`{}`), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+
+	_, err = ParseJSON([]byte("{\n  \"secret\": 1}"), schema)
+	assert.Equal(t, InvalidColumnError{Column: "secret", Line: 2, Pos: 4}, err)
+
+	_, err = ParseJSON([]byte(`{"age": {"nope": 1}}`), schema)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	_, err = ParseJSON([]byte(`{"age": {"eq": [1, 2]}}`), schema)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	_, err = ParseJSON([]byte(`{"or": []}`), schema)
+	assert.IsType(t, &LogicalTokenError{}, err)
+
+	_, err = ParseJSON([]byte(`{"age": `), schema)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+
+	_, err = ParseJSON([]byte(`{"age": {"between": [1]}}`), schema)
+	assert.IsType(t, ValueCountError{}, err)
+}
@@ -0,0 +1,56 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LikeWildcards are the wildcards a LIKE based operation adds around the client's value
+type LikeWildcards struct {
+	Leading  bool
+	Trailing bool
+}
+
+// likeEscape is the LIKE escape character, `\` is avoided as MySQL also treats it as a string escape
+const likeEscape = "!"
+
+// likeEscaper escapes every character LIKE treats as a wildcard (`[` is one on SQL Server)
+var likeEscaper = strings.NewReplacer(
+	likeEscape, likeEscape+likeEscape,
+	"%", likeEscape+"%",
+	"_", likeEscape+"_",
+	"[", likeEscape+"[",
+)
+
+// likeSQL renders the LIKE comparison, the pattern is either bound as a whole or
+// concatenated in SQL when WithSQLPatterns is used
+func (p *Parser) likeSQL(col string, w *LikeWildcards) string {
+	pattern := "?"
+	if p.sqlPatterns && (w.Leading || w.Trailing) {
+		parts := make([]string, 0, 3)
+		if w.Leading {
+			parts = append(parts, "'%'")
+		}
+		parts = append(parts, "?")
+		if w.Trailing {
+			parts = append(parts, "'%'")
+		}
+		pattern = p.dialect.concat(parts)
+	}
+	return fmt.Sprintf("%s LIKE %s ESCAPE '%s'", col, pattern, likeEscape)
+}
+
+// likeArg escapes the value and adds the wildcards unless they are added in SQL
+func (p *Parser) likeArg(w *LikeWildcards, val any) string {
+	arg := likeEscaper.Replace(fmt.Sprint(val))
+	if p.sqlPatterns {
+		return arg
+	}
+	if w.Leading {
+		arg = "%" + arg
+	}
+	if w.Trailing {
+		arg += "%"
+	}
+	return arg
+}
@@ -0,0 +1,48 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContains(t *testing.T) {
+	q, err := Parse(`name contains "50%_off!"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name LIKE ? ESCAPE '!'", q.SQL)
+	assert.Equal(t, []interface{}{"%50!%!_off!!%"}, q.Args)
+
+	q, err = Parse(`name contains "jo"`, validateColumn, WithSQLPatterns())
+	assert.NoError(t, err)
+	assert.Equal(t, "name LIKE CONCAT('%', ?, '%') ESCAPE '!'", q.SQL)
+	assert.Equal(t, []interface{}{"jo"}, q.Args)
+
+	q, err = Parse(`name contains "jo" and age gt 1`, validateColumn, WithSQLPatterns(), Postgres, WithFoldedColumns("name"))
+	assert.NoError(t, err)
+	assert.Equal(t, "LOWER(unaccent(name)) LIKE CONCAT('%', LOWER(unaccent($1)), '%') ESCAPE '!' and age > $2", q.SQL)
+
+	q, err = Parse(`name contains "jo"`, validateColumn, WithSQLPatterns(), Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, `"NAME" LIKE '%' || :1 || '%' ESCAPE '!'`, q.SQL)
+
+	_, err = Parse(`name contains 5`, validateColumn)
+	assert.IsType(t, InvalidValueError{}, err)
+}
+
+func TestPrefix(t *testing.T) {
+	q, err := Parse(`name prefix "%jo_"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name LIKE ? ESCAPE '!'", q.SQL)
+	assert.Equal(t, []interface{}{"!%jo!_%"}, q.Args)
+
+	q, err = Parse(`name prefix "jo"`, validateColumn, WithSQLPatterns(), MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "name LIKE CONCAT(?, '%') ESCAPE '!'", q.SQL)
+	assert.Equal(t, []interface{}{"jo"}, q.Args)
+
+	_, err = Parse(`name prefix ""`, validateColumn)
+	assert.IsType(t, InvalidValueError{}, err)
+
+	expr := mustParse(t, `name prefix "jo"`)
+	assert.Equal(t, IntentRangeScan, Classify(expr, IntentHints{Indexed: []string{"name"}}))
+}
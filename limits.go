@@ -0,0 +1,51 @@
+package rqe
+
+// checkLength rejects inputs longer than WithMaxLength allows, before they are tokenized
+func (p *Parser) checkLength(length int) error {
+	if p.maxLength > 0 && length > p.maxLength {
+		return InputTooLongError{Limit: p.maxLength, Length: length}
+	}
+	return nil
+}
+
+// checkDepth fails once a group opened at line / column nests deeper than WithMaxDepth allows
+func (p *Parser) checkDepth(depth, line, column int) error {
+	if p.maxDepth > 0 && depth > p.maxDepth {
+		return DepthLimitExceededError{Limit: p.maxDepth, Line: line, Pos: column}
+	}
+	return nil
+}
+
+// conditionCounter counts the conditions of a filter against WithMaxConditions, nil is unlimited
+type conditionCounter struct {
+	limit int
+	count int
+}
+
+func (p *Parser) newConditionCounter() *conditionCounter {
+	if p.maxConditions <= 0 {
+		return nil
+	}
+	return &conditionCounter{limit: p.maxConditions}
+}
+
+// add counts the condition at line / column, failing once there are more than the limit
+func (c *conditionCounter) add(line, column int) error {
+	if c == nil {
+		return nil
+	}
+	c.count++
+	if c.count > c.limit {
+		return ConditionLimitError{Limit: c.limit, Line: line, Pos: column}
+	}
+	return nil
+}
+
+// countCondition charges a condition and its values to the budget, then counts it against the limit.
+// Null comparisons are counted like any other condition.
+func countCondition(budget *memoryBudget, conditions *conditionCounter, vals []any, line, column int) error {
+	if err := budget.chargeCondition(vals); err != nil {
+		return err
+	}
+	return conditions.add(line, column)
+}
@@ -0,0 +1,93 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxDepth(t *testing.T) {
+	p := NewParser(WithMaxDepth(2))
+
+	_, err := p.Parse(`((age gt 1) or name eq "a") and not(age lt 5)`, validateColumn)
+	assert.NoError(t, err)
+
+	filter := `(age gt 1 or (name eq "a" and (age lt 5)))`
+	_, err = p.Parse(filter, validateColumn)
+	assert.Equal(t, DepthLimitExceededError{Limit: 2, Line: 1, Pos: 30}, err)
+	assert.Contains(t, err.(DepthLimitExceededError).Pretty(filter), "at most 2 nested groups")
+
+	_, err = p.ParseOData(`not not not age gt 1`, validateColumn)
+	assert.Equal(t, DepthLimitExceededError{Limit: 2, Line: 1, Pos: 8}, err)
+
+	_, err = p.ParseJSON([]byte(`{"not": {"or": [{"and": [{"age": 1}]}]}}`), validateColumn)
+	assert.Equal(t, DepthLimitExceededError{Limit: 2, Line: 1, Pos: 17}, err)
+
+	// 0 disables the limit
+	_, err = Parse(filter, validateColumn)
+	assert.NoError(t, err)
+}
+
+func TestMaxConditions(t *testing.T) {
+	p := NewParser(WithMaxConditions(3))
+
+	_, err := p.Parse(`age gte 18 lte 65 and (age, name) gt [1, "a"]`, validateColumn)
+	assert.NoError(t, err)
+
+	filter := `age gte 18 lte 65 and name eq "a" or name eq "b"`
+	_, err = p.Parse(filter, validateColumn)
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 37}, err)
+	assert.Contains(t, err.(ConditionLimitError).Pretty(filter), "at most 3 conditions")
+
+	_, err = p.ParseOData(`age gt 1 and age lt 5 and name eq 'a' and name eq 'b'`, validateColumn)
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 42}, err)
+
+	_, err = p.ParseJSON([]byte(`{"age": {"gt": 1, "lt": 5}, "or": [{"name": "a"}, {"name": "b"}]}`), validateColumn)
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 51}, err)
+
+	// null comparisons count as well
+	_, err = p.Parse(`a eq null and b eq null and c ne null and d eq null`, validateColumn)
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 42}, err)
+	_, err = p.ParseOData(`a eq null and b eq null and c ne null and d eq null`, validateColumn)
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 42}, err)
+	_, err = p.ParseJSON([]byte(`{"a": null, "b": null, "c": {"ne": null}, "d": null}`), validateColumn)
+	assert.IsType(t, ConditionLimitError{}, err)
+
+	// the fragments of a builder share the limit
+	_, err = p.Begin(validateColumn).Filter(`a eq 1 and b eq 2`).Filter(`c eq 3`).Finish()
+	assert.NoError(t, err)
+	_, err = p.Begin(validateColumn).Filter(`a eq 1 and b eq 2`).Filter(`c eq 3 and d eq 4`).Finish()
+	assert.Equal(t, ConditionLimitError{Limit: 3, Line: 1, Pos: 11}, err)
+}
+
+func TestMaxListSize(t *testing.T) {
+	p := NewParser(WithMaxListSize(3))
+
+	_, err := p.Parse(`age in [1, 2, 3] and age between [1, 2]`, validateColumn)
+	assert.NoError(t, err)
+
+	filter := `name eq "a" and age nin [1, 2, 3, 4]`
+	_, err = p.Parse(filter, validateColumn)
+	assert.Equal(t, ListSizeError{Column: "age", Limit: 3, Got: 4, Line: 1, Pos: 16}, err)
+	assert.EqualError(t, err, "column 'age' takes at most 3 values but got 4 at line 1, offset 16")
+
+	_, err = p.ParseOData(`age in (1, 2, 3, 4)`, validateColumn)
+	assert.Equal(t, ListSizeError{Column: "age", Limit: 3, Got: 4, Line: 1, Pos: 0}, err)
+}
+
+func TestMaxLength(t *testing.T) {
+	p := NewParser(WithMaxLength(10))
+
+	_, err := p.Parse(`age gt 18`, validateColumn)
+	assert.NoError(t, err)
+
+	_, err = p.Parse(`age gt 18 and name eq "a"`, validateColumn)
+	assert.Equal(t, InputTooLongError{Limit: 10, Length: 25}, err)
+	assert.EqualError(t, err, "filter is 25 bytes long, over the limit of 10 bytes")
+
+	_, err = p.ParseOData(`age gt 18 and name eq 'a'`, validateColumn)
+	assert.Equal(t, InputTooLongError{Limit: 10, Length: 25}, err)
+
+	_, err = p.ParseJSON([]byte(`{"age": {"gt": 18}}`), validateColumn)
+	assert.Equal(t, InputTooLongError{Limit: 10, Length: 19}, err)
+}
@@ -0,0 +1,120 @@
+package rqe
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// functionOperations compile to a function or expression over the column, which keeps a plain
+// index on it from being used
+var functionOperations = map[string]struct{}{
+	"ilike":        {},
+	"sounds_like":  {},
+	"within_edits": {},
+	"band":         {},
+	"bor":          {},
+}
+
+// Lint runs the rules and returns their violations without failing, for gateways that warn
+// rather than reject. Use WithRules to enforce them instead.
+func Lint(expr Expr, rules ...Rule) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		violations = append(violations, rule(expr)...)
+	}
+	return violations
+}
+
+// Sargable is the rule set keeping filters able to use indexes : no leading wildcards, no
+// functions over the indexed columns and no `or` across different columns
+func Sargable(indexed ...string) []Rule {
+	return []Rule{NoLeadingWildcard(), NoFunctionOnIndexed(indexed...), NoCrossColumnOr()}
+}
+
+// NoLeadingWildcard rejects patterns starting with a wildcard (`contains`, `like "%x"`) on the
+// columns, every column when none are given. Use it on large tables where they scan every row.
+func NoLeadingWildcard(columns ...string) Rule {
+	return func(expr Expr) []Violation {
+		var violations []Violation
+		Walk(expr, func(c *Condition) {
+			if len(columns) > 0 && !slices.Contains(columns, c.Column) || !leadingWildcard(c) {
+				return
+			}
+			violations = append(violations, Violation{
+				Rule:    "leading_wildcard",
+				Column:  c.Column,
+				Message: fmt.Sprintf("'%s' on '%s' starts with a wildcard and cannot use an index", c.Operator, c.Column),
+			})
+		})
+		return violations
+	}
+}
+
+// NoFunctionOnIndexed rejects operations compiling to a function over one of the indexed
+// columns (`ilike`, `sounds_like` ...), the index on the column would not be used
+func NoFunctionOnIndexed(indexed ...string) Rule {
+	return func(expr Expr) []Violation {
+		var violations []Violation
+		Walk(expr, func(c *Condition) {
+			if _, ok := functionOperations[c.Operator]; !ok || !slices.Contains(indexed, c.Column) {
+				return
+			}
+			violations = append(violations, Violation{
+				Rule:    "function_on_index",
+				Column:  c.Column,
+				Message: fmt.Sprintf("'%s' applies a function to the indexed column '%s'", c.Operator, c.Column),
+			})
+		})
+		return violations
+	}
+}
+
+// NoCrossColumnOr rejects `or` between conditions on different columns, which usually turns
+// into a full scan instead of an index lookup
+func NoCrossColumnOr() Rule {
+	var check func(expr Expr) []Violation
+	check = func(expr Expr) []Violation {
+		var violations []Violation
+		switch e := expr.(type) {
+		case *Logical:
+			if e.Operator == "or" {
+				var columns []string
+				Walk(e, func(c *Condition) {
+					if !slices.Contains(columns, c.Column) {
+						columns = append(columns, c.Column)
+					}
+				})
+				if len(columns) > 1 {
+					return []Violation{{
+						Rule:    "cross_column_or",
+						Column:  columns[1],
+						Message: fmt.Sprintf("'or' across columns '%s' cannot use a single index", strings.Join(columns, "', '")),
+					}}
+				}
+			}
+			for _, child := range e.Exprs {
+				violations = append(violations, check(child)...)
+			}
+		case *Not:
+			violations = check(e.Expr)
+		}
+		return violations
+	}
+	return check
+}
+
+// leadingWildcard reports whether the condition matches a pattern starting with a wildcard
+func leadingWildcard(c *Condition) bool {
+	if c.IsNull() {
+		return false
+	}
+	if op := operationsMapped[c.Operator]; op.Like != nil {
+		return op.Like.Leading
+	}
+	if c.Operator != "like" && c.Operator != "ilike" {
+		return false
+	}
+	pattern := fmt.Sprint(c.Values[0])
+	return strings.HasPrefix(pattern, "%") || strings.HasPrefix(pattern, "_")
+}
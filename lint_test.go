@@ -0,0 +1,39 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	rules := Sargable("email")
+
+	lint := func(filter string) []Violation {
+		expr, err := ParseExpr(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		return Lint(expr, rules...)
+	}
+
+	assert.Empty(t, lint(`email eq "a@b.c" and name prefix "jo" and (status eq 1 or status eq 2)`))
+	assert.Empty(t, lint(`(a, b) gt [1, 2]`))
+
+	assert.Equal(t, []Violation{
+		{Rule: "leading_wildcard", Column: "name", Message: "'contains' on 'name' starts with a wildcard and cannot use an index"},
+		{Rule: "leading_wildcard", Column: "bio", Message: "'like' on 'bio' starts with a wildcard and cannot use an index"},
+	}, lint(`name contains "jo" and bio like "%go"`))
+
+	assert.Equal(t, []Violation{
+		{Rule: "function_on_index", Column: "email", Message: "'ilike' applies a function to the indexed column 'email'"},
+	}, lint(`email ilike "a@b.c" and name ilike "jo"`))
+
+	assert.Equal(t, []Violation{
+		{Rule: "cross_column_or", Column: "name", Message: "'or' across columns 'email', 'name' cannot use a single index"},
+	}, lint(`not (email eq "a@b.c" or name eq "jo")`))
+
+	// enforced like any other rule
+	_, err := Parse(`name contains "jo"`, validateColumn, WithRules(NoLeadingWildcard("name")))
+	assert.IsType(t, RuleViolationError{}, err)
+	_, err = Parse(`bio contains "jo"`, validateColumn, WithRules(NoLeadingWildcard("name")))
+	assert.NoError(t, err)
+}
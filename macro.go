@@ -0,0 +1,56 @@
+package rqe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/baderkha/rqe/macros"
+)
+
+// runMacro runs the handler so a misbehaving one cannot take the request down : panics are
+// recovered and, when WithMacroTimeout is set, a handler running too long is abandoned.
+// Both are reported as an InvalidMacroValueError. A handler still running when the context is
+// done is abandoned as well, the context's error is returned.
+func (p *Parser) runMacro(ctx context.Context, name string, h macros.Macro, col string, vals []any) ([]any, error) {
+	type result struct {
+		vals []any
+		err  error
+	}
+	run := func() (res result) {
+		defer func() {
+			if r := recover(); r != nil {
+				res.err = &macros.InvalidMacroValueError{Column: col, Detail: fmt.Sprintf("macro '%s' panicked : %v", name, r)}
+			}
+		}()
+		if ch, ok := h.(macros.ContextMacro); ok {
+			res.vals, res.err = ch.RunMacroContext(ctx, col, vals...)
+		} else {
+			res.vals, res.err = h.RunMacro(col, vals...)
+		}
+		return res
+	}
+
+	if p.macroTimeout <= 0 && ctx.Done() == nil {
+		res := run()
+		return res.vals, res.err
+	}
+
+	// buffered so an abandoned handler can still finish and be collected
+	done := make(chan result, 1)
+	go func() { done <- run() }()
+	var timeout <-chan time.Time
+	if p.macroTimeout > 0 {
+		timer := time.NewTimer(p.macroTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case res := <-done:
+		return res.vals, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout:
+		return nil, &macros.InvalidMacroValueError{Column: col, Detail: fmt.Sprintf("macro '%s' timed out after %s", name, p.macroTimeout)}
+	}
+}
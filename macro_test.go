@@ -0,0 +1,60 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/baderkha/rqe/macros"
+	"github.com/stretchr/testify/assert"
+)
+
+type macroFunc func(col string, args ...any) ([]any, error)
+
+func (f macroFunc) RunMacro(col string, args ...any) ([]any, error) {
+	return f(col, args...)
+}
+
+func TestMacroSandbox(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	custom := map[string]macros.Macro{
+		"boom":  macroFunc(func(string, ...any) ([]any, error) { panic("bad handler") }),
+		"stall": macroFunc(func(_ string, args ...any) ([]any, error) { <-release; return args, nil }),
+		"twice": macroFunc(func(_ string, args ...any) ([]any, error) { return []any{args[0].(int64) * 2}, nil }),
+	}
+	supported := macros.Supported
+	for name, h := range custom {
+		macros.Handlers[name] = h
+		macros.Supported = append(macros.Supported, name)
+	}
+	defer func() {
+		for name := range custom {
+			delete(macros.Handlers, name)
+		}
+		macros.Supported = supported
+	}()
+
+	_, err := Parse(`age gt boom(1)`, validateColumn)
+	assert.Equal(t, &macros.InvalidMacroValueError{Column: "age", Detail: "macro 'boom' panicked : bad handler"}, err)
+
+	p := NewParser(WithMacroTimeout(10 * time.Millisecond))
+	_, err = p.Parse(`age gt stall(1)`, validateColumn)
+	assert.Equal(t, &macros.InvalidMacroValueError{Column: "age", Detail: "macro 'stall' timed out after 10ms"}, err)
+
+	q, err := p.Parse(`age gt twice(2)`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{int64(4)}, q.Args)
+}
+
+func TestWithoutMacros(t *testing.T) {
+	p := NewParser(WithoutMacros())
+
+	filter := `name eq "a" and created_at gt age("2 days")`
+	_, err := p.Parse(filter, validateColumn)
+	assert.Equal(t, MacrosDisabledError{Macro: "age", Column: "created_at", Line: 1, Pos: 16}, err)
+	assert.EqualError(t, err, "macro 'age' used on column 'created_at' is disabled at line 1, offset 16")
+	assert.Contains(t, err.(MacrosDisabledError).Pretty(filter), "macros are disabled")
+
+	_, err = p.Parse(`name eq "age"`, validateColumn)
+	assert.NoError(t, err)
+}
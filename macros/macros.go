@@ -1,6 +1,7 @@
 package macros
 
 import (
+	"context"
 	"time"
 )
 
@@ -21,3 +22,10 @@ var (
 type Macro interface {
 	RunMacro(col string, args ...any) (arg []any, err error)
 }
+
+// ContextMacro is a Macro reading request scoped data (current user, tenant ...) from the
+// context of rqe.ParseContext, it is called instead of RunMacro
+type ContextMacro interface {
+	Macro
+	RunMacroContext(ctx context.Context, col string, args ...any) (arg []any, err error)
+}
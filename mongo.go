@@ -0,0 +1,135 @@
+package rqe
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// mongoComparisons are the operations mapping to a single mongo query operator
+var mongoComparisons = map[string]string{
+	"ne":  "$ne",
+	"lt":  "$lt",
+	"lte": "$lte",
+	"gt":  "$gt",
+	"gte": "$gte",
+	"in":  "$in",
+	"nin": "$nin",
+}
+
+// ParseToMongo parses the filter into a MongoDB query document, see ToMongo
+func ParseToMongo(filter string, validateCol func(col string) bool, opts ...Option) (map[string]any, error) {
+	expr, err := withDefaults(opts).ParseExpr(filter, validateCol)
+	if err != nil {
+		return nil, err
+	}
+	return ToMongo(expr)
+}
+
+// ToMongo converts the expression into a MongoDB query document (`$and`, `$or`, `$in`, `$gte` ...).
+// The document is a plain map usable as a bson.M, a nil expression matches every document.
+// Operations mongo cannot express (`sounds_like`, `in_subnet` ... etc) return an UnsupportedOperationError.
+func ToMongo(expr Expr) (map[string]any, error) {
+	switch e := expr.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case *Condition:
+		return mongoCondition(e)
+	case *Logical:
+		docs := make([]any, len(e.Exprs))
+		for i, child := range e.Exprs {
+			doc, err := ToMongo(child)
+			if err != nil {
+				return nil, err
+			}
+			docs[i] = doc
+		}
+		return map[string]any{"$" + e.Operator: docs}, nil
+	case *Not:
+		doc, err := ToMongo(e.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"$nor": []any{doc}}, nil
+	case *Tuple:
+		return ToMongo(e.Expanded)
+	default:
+		return nil, UnsupportedOperationError{Backend: "mongo", Operation: asOfKeyword}
+	}
+}
+
+func mongoCondition(c *Condition) (map[string]any, error) {
+	field := func(v any) (map[string]any, error) {
+		return map[string]any{c.Column: v}, nil
+	}
+	if op, ok := mongoComparisons[c.Operator]; ok {
+		if c.IsNull() {
+			return field(map[string]any{op: nil})
+		}
+		if c.Operator == "in" || c.Operator == "nin" {
+			return field(map[string]any{op: c.Values})
+		}
+		return field(map[string]any{op: c.Values[0]})
+	}
+
+	switch c.Operator {
+	case "eq", "nseq":
+		return field(c.Values[0])
+	case "between":
+		return field(map[string]any{"$gte": c.Values[0], "$lte": c.Values[1]})
+	case "from_until":
+		return field(map[string]any{"$gte": c.Values[0], "$lt": c.Values[1]})
+	case "nbetween":
+		return map[string]any{"$or": []any{
+			map[string]any{c.Column: map[string]any{"$lt": c.Values[0]}},
+			map[string]any{c.Column: map[string]any{"$gt": c.Values[1]}},
+		}}, nil
+	case "band", "bor":
+		// macros and typed columns can hand anything else than an int64 over
+		flags, ok := c.Values[0].(int64)
+		if !ok {
+			return nil, InvalidValueError{Column: c.Column, Operation: c.Operator, Reason: fmt.Sprintf("%v is not an integer", c.Values[0]), Line: c.Line, Pos: c.Pos}
+		}
+		if c.Operator == "band" {
+			return field(map[string]any{"$bitsAnySet": flags})
+		}
+		// no bit outside of the value may be set
+		bits := make([]int, 0, 63)
+		for bit := range 63 {
+			if flags&(1<<bit) == 0 {
+				bits = append(bits, bit)
+			}
+		}
+		return field(map[string]any{"$bitsAllClear": bits})
+	case "regex":
+		return field(map[string]any{"$regex": fmt.Sprint(c.Values[0])})
+	case "like":
+		return field(map[string]any{"$regex": likeToRegex(fmt.Sprint(c.Values[0]))})
+	case "ilike":
+		return field(map[string]any{"$regex": likeToRegex(fmt.Sprint(c.Values[0])), "$options": "i"})
+	case "contains":
+		return field(map[string]any{"$regex": regexp.QuoteMeta(fmt.Sprint(c.Values[0]))})
+	case "prefix":
+		return field(map[string]any{"$regex": "^" + regexp.QuoteMeta(fmt.Sprint(c.Values[0]))})
+	default:
+		return nil, UnsupportedOperationError{Backend: "mongo", Operation: c.Operator, Column: c.Column, Line: c.Line, Pos: c.Pos}
+	}
+}
+
+// likeToRegex converts a LIKE pattern into an anchored regular expression
+func likeToRegex(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToMongo(t *testing.T) {
+	doc, err := ParseToMongo(`name eq "john" and (age between [18, 30] or status in ["a", "b"]) and deleted_at ne null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"$and": []any{
+		map[string]any{"name": "john"},
+		map[string]any{"$or": []any{
+			map[string]any{"age": map[string]any{"$gte": float64(18), "$lte": float64(30)}},
+			map[string]any{"status": map[string]any{"$in": []any{"a", "b"}}},
+		}},
+		map[string]any{"deleted_at": map[string]any{"$ne": nil}},
+	}}, doc)
+
+	for filter, expected := range map[string]map[string]any{
+		`name like "jo%n_"`:   {"name": map[string]any{"$regex": `^jo.*n.$`}},
+		`name ilike "j.%"`:    {"name": map[string]any{"$regex": `^j\..*$`, "$options": "i"}},
+		`name prefix "a.b"`:   {"name": map[string]any{"$regex": `^a\.b`}},
+		`not (age lt 3)`:      {"$nor": []any{map[string]any{"age": map[string]any{"$lt": int64(3)}}}},
+		`age nbetween [1, 2]`: {"$or": []any{map[string]any{"age": map[string]any{"$lt": float64(1)}}, map[string]any{"age": map[string]any{"$gt": float64(2)}}}},
+		`flags band 4`:        {"flags": map[string]any{"$bitsAnySet": int64(4)}},
+		`(a, b) eq [1, 2]`:    {"$and": []any{map[string]any{"a": float64(1)}, map[string]any{"b": float64(2)}}},
+	} {
+		doc, err := ParseToMongo(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, expected, doc, filter)
+	}
+
+	doc, err = ParseToMongo(`flags bor 6`, validateColumn)
+	assert.NoError(t, err)
+	bits := doc["flags"].(map[string]any)["$bitsAllClear"].([]int)
+	assert.Equal(t, []int{0, 3, 4}, bits[:3])
+	assert.Len(t, bits, 61)
+
+	// values which are not integers once coerced or produced by a macro
+	_, err = ToMongo(&Condition{Column: "flags", Operator: "bor", Values: []any{"5"}, Line: 1})
+	assert.Equal(t, InvalidValueError{Column: "flags", Operation: "bor", Reason: "5 is not an integer", Line: 1, Pos: 0}, err)
+	_, err = ParseToMongo(`flags bor age(1)`, validateColumn)
+	assert.IsType(t, InvalidValueError{}, err)
+
+	doc, err = ParseToMongo(``, validateColumn)
+	assert.NoError(t, err)
+	assert.Empty(t, doc)
+
+	_, err = ParseToMongo(`name sounds_like "x"`, validateColumn)
+	assert.IsType(t, UnsupportedOperationError{}, err)
+}
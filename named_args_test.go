@@ -0,0 +1,53 @@
+package rqe
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedArgs(t *testing.T) {
+	q, err := Parse(`name eq "john" and (age between [18, 30] or flags bor 4)`, validateColumn, WithNamedArgs(), Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = :name_0 and (age BETWEEN :age_1 AND :age_2 or (flags | :flags_3) = :flags_4)", q.SQL)
+	assert.Equal(t, map[string]interface{}{
+		"name_0":  "john",
+		"age_1":   float64(18),
+		"age_2":   float64(30),
+		"flags_3": int64(4),
+		"flags_4": int64(4),
+	}, q.NamedArgs)
+	assert.Len(t, q.Args, 5)
+
+	q, err = Parse(`(a, b) gt [1, 2]`, validateColumn, WithNamedArgs())
+	assert.NoError(t, err)
+	assert.Equal(t, "(a, b) > (:a_0, :b_1)", q.SQL)
+
+	q, err = Parse(`age gt 1`, validateColumn)
+	assert.NoError(t, err)
+	assert.Nil(t, q.NamedArgs)
+
+	b, err := NewParser(WithNamedArgs()).Begin(validateColumn).Where("tenant_id = ?", 7).Filter(`age gte 18`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = :arg_0) AND (age >= :age_1)", b.Where)
+	assert.Equal(t, map[string]interface{}{"arg_0": 7, "age_1": int64(18)}, b.NamedArgs)
+}
+
+func TestArgInfo(t *testing.T) {
+	q, err := Parse(`name eq "john" and age between [18, 30] and (a, b) gt [1, 2] and deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	str, i64, f64 := reflect.TypeOf(""), reflect.TypeOf(int64(0)), reflect.TypeOf(float64(0))
+	assert.Equal(t, []ArgInfo{
+		{Column: "name", Operator: "eq", GoType: str},
+		{Column: "age", Operator: "between", GoType: f64},
+		{Column: "age", Operator: "between", GoType: f64},
+		{Column: "a", Operator: "gt", GoType: f64},
+		{Column: "b", Operator: "gt", GoType: f64},
+	}, q.ArgInfo)
+	assert.Len(t, q.ArgInfo, len(q.Args))
+
+	b, err := NewParser().Begin(validateColumn).Where("tenant_id = ?", 7).Filter(`age gte 18`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, []ArgInfo{{GoType: reflect.TypeOf(0)}, {Column: "age", Operator: "gte", GoType: i64}}, b.ArgInfo)
+}
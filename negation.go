@@ -0,0 +1,72 @@
+package rqe
+
+// negatedOperations maps every operation to its complement, `not (a lt 1)` is `a gte 1`.
+// Operations missing here (`band`, `sounds_like` ... etc) have no complement and stay negated.
+var negatedOperations = map[string]string{
+	"eq":       "ne",
+	"ne":       "eq",
+	"lt":       "gte",
+	"gte":      "lt",
+	"gt":       "lte",
+	"lte":      "gt",
+	"between":  "nbetween",
+	"nbetween": "between",
+	"in":       "nin",
+	"nin":      "in",
+}
+
+// PushNegations applies De Morgan's laws until every `not` sits on a leaf and replaces
+// negated comparisons with their complement, so `not (a eq 1 or b gt 2)` is `a ne 1 and b lte 2`.
+// Leaves without a complement keep their `not`. The result compiles to the same rows,
+// the `not` free filters can be sent to backends without negation support.
+func PushNegations(expr Expr) Expr {
+	switch e := expr.(type) {
+	case *Not:
+		return negate(e.Expr)
+	case *Logical:
+		exprs := make([]Expr, len(e.Exprs))
+		for i, child := range e.Exprs {
+			exprs[i] = PushNegations(child)
+		}
+		return &Logical{Operator: e.Operator, Exprs: exprs}
+	default:
+		return expr
+	}
+}
+
+// negate returns the negation of expr with the negations pushed down
+func negate(expr Expr) Expr {
+	switch e := expr.(type) {
+	case *Not:
+		return PushNegations(e.Expr)
+	case *Logical:
+		op := "or"
+		if e.Operator == "or" {
+			op = "and"
+		}
+		exprs := make([]Expr, len(e.Exprs))
+		for i, child := range e.Exprs {
+			exprs[i] = negate(child)
+		}
+		return &Logical{Operator: op, Exprs: exprs}
+	case *Condition:
+		if e.IsNull() {
+			// null comparisons are either IS NULL (eq, nseq) or IS NOT NULL (ne)
+			if e.Operator == "ne" {
+				return &Condition{Column: e.Column, Operator: "eq", Values: e.Values, Line: e.Line, Pos: e.Pos}
+			}
+			return &Condition{Column: e.Column, Operator: "ne", Values: e.Values, Line: e.Line, Pos: e.Pos}
+		}
+		if op, ok := negatedOperations[e.Operator]; ok {
+			return &Condition{Column: e.Column, Operator: op, Values: e.Values, Line: e.Line, Pos: e.Pos}
+		}
+	case *Tuple:
+		op, ok := negatedOperations[e.Operator]
+		if _, isTuple := tupleOperators[op]; ok && isTuple {
+			negated := &Tuple{Columns: e.Columns, Operator: op, Values: e.Values, Line: e.Line, Pos: e.Pos}
+			negated.Expanded = expandTuple(negated)
+			return negated
+		}
+	}
+	return &Not{Expr: expr}
+}
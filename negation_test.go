@@ -0,0 +1,41 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNot(t *testing.T) {
+	q, err := Parse(`not (a eq 1 or b gt 2) and c eq 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (a = ? or b > ?) and c = ?", q.SQL)
+	assert.Equal(t, []string{"a", "b", "c"}, q.Columns)
+
+	q, err = Parse(`not (not (a eq 1))`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (NOT (a = ?))", q.SQL)
+
+	for _, filter := range []string{`not a eq 1`, `not ()`, `not (a eq 1`} {
+		_, err = Parse(filter, validateColumn)
+		assert.Error(t, err, filter)
+	}
+
+	q, err = Parse(`not eq 1`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "not = ?", q.SQL)
+}
+
+func TestPushNegations(t *testing.T) {
+	compile := NewParser().Compile
+	for filter, expected := range map[string]string{
+		`not (a eq 1 or b gt 2)`:                            "a <> ? and b <= ?",
+		`not (a lt 1 and (b between [1, 2] or c ne null))`:  "a >= ? or (b NOT BETWEEN ? AND ? and c IS NULL)",
+		`not (not (a eq 1))`:                                "a = ?",
+		`x eq 1 and not (a in [1, 2] or b sounds_like "x")`: "x = ? and (a NOT IN (?, ?) and NOT (SOUNDEX(b) = SOUNDEX(?)))",
+		`not ((a, b) gt [1, 2])`:                            "(a, b) <= (?, ?)",
+		`not ((a, b) overlaps [1, 2])`:                      "NOT (a < ? and b > ?)",
+	} {
+		assert.Equal(t, expected, compile(PushNegations(mustParse(t, filter))).SQL, filter)
+	}
+}
@@ -0,0 +1,89 @@
+package rqe
+
+// ToCNF rewrites the filter in conjunctive normal form, an `and` of `or`s of conditions,
+// e.g. `a eq 1 or (b eq 2 and c eq 3)` is `(a eq 1 or b eq 2) and (a eq 1 or c eq 3)`.
+// Normal forms can grow exponentially, a NormalFormLimitError is returned once the result
+// would have more than maxClauses clauses. A `not` is kept as a leaf, use PushNegations first.
+func ToCNF(expr Expr, maxClauses int) (Expr, error) {
+	clauses, err := normalForm(expr, "and", maxClauses)
+	if err != nil {
+		return nil, err
+	}
+	return buildNormalForm(clauses, "and", "or"), nil
+}
+
+// ToDNF rewrites the filter in disjunctive normal form, an `or` of `and`s of conditions,
+// e.g. `a eq 1 and (b eq 2 or c eq 3)` is `(a eq 1 and b eq 2) or (a eq 1 and c eq 3)`.
+// See ToCNF for the clause limit.
+func ToDNF(expr Expr, maxClauses int) (Expr, error) {
+	clauses, err := normalForm(expr, "or", maxClauses)
+	if err != nil {
+		return nil, err
+	}
+	return buildNormalForm(clauses, "or", "and"), nil
+}
+
+// normalForm returns the clauses joined by outer, each clause being the operands joined by
+// the other operator. Conditions, tuples and `asof` are kept as is.
+func normalForm(expr Expr, outer string, maxClauses int) ([][]Expr, error) {
+	l, ok := expr.(*Logical)
+	if !ok {
+		if expr == nil {
+			return nil, nil
+		}
+		return [][]Expr{{expr}}, nil
+	}
+
+	var clauses [][]Expr
+	for i, child := range l.Exprs {
+		childClauses, err := normalForm(child, outer, maxClauses)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case i == 0:
+			clauses = childClauses
+		case l.Operator == outer:
+			clauses = append(clauses, childClauses...)
+		default: // distribute the inner operator over the outer one
+			if len(clauses)*len(childClauses) > maxClauses {
+				return nil, NormalFormLimitError{Form: normalFormName(outer), Limit: maxClauses}
+			}
+			product := make([][]Expr, 0, len(clauses)*len(childClauses))
+			for _, left := range clauses {
+				for _, right := range childClauses {
+					product = append(product, append(append([]Expr{}, left...), right...))
+				}
+			}
+			clauses = product
+		}
+		if len(clauses) > maxClauses {
+			return nil, NormalFormLimitError{Form: normalFormName(outer), Limit: maxClauses}
+		}
+	}
+	return clauses, nil
+}
+
+func buildNormalForm(clauses [][]Expr, outer, inner string) Expr {
+	join := func(op string, exprs []Expr) Expr {
+		if len(exprs) == 1 {
+			return exprs[0]
+		}
+		return &Logical{Operator: op, Exprs: exprs}
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+	exprs := make([]Expr, len(clauses))
+	for i, clause := range clauses {
+		exprs[i] = join(inner, clause)
+	}
+	return join(outer, exprs)
+}
+
+func normalFormName(outer string) string {
+	if outer == "and" {
+		return "cnf"
+	}
+	return "dnf"
+}
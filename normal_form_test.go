@@ -0,0 +1,39 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalForms(t *testing.T) {
+	compile := NewParser().Compile
+
+	cnf, err := ToCNF(mustParse(t, `a eq 1 or (b eq 2 and c eq 3)`), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? or b = ?) and (a = ? or c = ?)", compile(cnf).SQL)
+
+	dnf, err := ToDNF(mustParse(t, `a eq 1 and (b eq 2 or c eq 3)`), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? and b = ?) or (a = ? and c = ?)", compile(dnf).SQL)
+
+	dnf, err = ToDNF(mustParse(t, `(a eq 1 or b eq 2) and (c eq 3 or d eq 4) and e eq 5`), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? and c = ? and e = ?) or (a = ? and d = ? and e = ?) or (b = ? and c = ? and e = ?) or (b = ? and d = ? and e = ?)", compile(dnf).SQL)
+
+	// already normalized filters keep their shape
+	cnf, err = ToCNF(mustParse(t, `a eq 1 and (b eq 2 or c eq 3)`), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = ? and (b = ? or c = ?)", compile(cnf).SQL)
+
+	single, err := ToDNF(mustParse(t, `a eq 1`), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = ?", compile(single).SQL)
+
+	empty, err := ToCNF(nil, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+
+	_, err = ToDNF(mustParse(t, `(a eq 1 or b eq 2) and (c eq 3 or d eq 4) and (e eq 5 or f eq 6)`), 4)
+	assert.Equal(t, NormalFormLimitError{Form: "dnf", Limit: 4}, err)
+}
@@ -0,0 +1,77 @@
+package rqe
+
+import "fmt"
+
+// NullPolicy is how a nullable column is handled by AuditNulls
+type NullPolicy int
+
+const (
+	// NullWarn reports negative comparisons on the column and leaves them as is
+	NullWarn NullPolicy = iota
+	// NullInclude rewrites negative comparisons so NULL rows match, `a ne 1` is `a ne 1 or a eq null`
+	NullInclude
+	// NullExclude accepts that NULL rows never match, nothing is reported
+	NullExclude
+)
+
+// nullExcludingOperations are the negative comparisons users expect to match NULL rows
+// while SQL's three valued logic never does
+var nullExcludingOperations = map[string]struct{}{
+	"ne":       {},
+	"nin":      {},
+	"nbetween": {},
+}
+
+// NullWarning is a negative comparison on a nullable column silently excluding NULL rows
+type NullWarning struct {
+	Column   string `json:"column"`
+	Operator string `json:"operator"`
+	Line     int    `json:"line"`
+	Pos      int    `json:"pos"`
+	Message  string `json:"message"`
+}
+
+// AuditNulls looks for `ne` / `nin` / `nbetween` conditions on nullable columns, which exclude
+// NULL rows even though `status ne "banned"` reads like it should match rows without a status.
+// nullable maps every nullable column to its policy, the returned expression has the
+// NullInclude rewrites applied and the warnings list the NullWarn conditions.
+func AuditNulls(expr Expr, nullable map[string]NullPolicy) (Expr, []NullWarning) {
+	var warnings []NullWarning
+	var audit func(expr Expr) Expr
+	audit = func(expr Expr) Expr {
+		switch e := expr.(type) {
+		case *Condition:
+			policy, ok := nullable[e.Column]
+			if _, excludes := nullExcludingOperations[e.Operator]; !ok || !excludes || e.IsNull() {
+				return e
+			}
+			switch policy {
+			case NullInclude:
+				return &Logical{Operator: "or", Exprs: []Expr{
+					e,
+					&Condition{Column: e.Column, Operator: "eq", Values: []any{nil}, Line: e.Line, Pos: e.Pos},
+				}}
+			case NullWarn:
+				warnings = append(warnings, NullWarning{
+					Column:   e.Column,
+					Operator: e.Operator,
+					Line:     e.Line,
+					Pos:      e.Pos,
+					Message:  fmt.Sprintf("'%s %s' never matches rows where '%s' is null", e.Column, e.Operator, e.Column),
+				})
+			}
+			return e
+		case *Logical:
+			exprs := make([]Expr, len(e.Exprs))
+			for i, child := range e.Exprs {
+				exprs[i] = audit(child)
+			}
+			return &Logical{Operator: e.Operator, Exprs: exprs}
+		case *Not:
+			return &Not{Expr: audit(e.Expr)}
+		default:
+			return expr
+		}
+	}
+	return audit(expr), warnings
+}
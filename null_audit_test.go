@@ -0,0 +1,26 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditNulls(t *testing.T) {
+	nullable := map[string]NullPolicy{
+		"status":  NullWarn,
+		"country": NullInclude,
+		"deleted": NullExclude,
+	}
+
+	expr, warnings := AuditNulls(mustParse(t, `status ne "banned" and country nin ["fr", "de"] and deleted ne 1 and age ne 3 and status ne null`), nullable)
+	assert.Equal(t, `status <> ? and (country NOT IN (?, ?) or country IS NULL) and deleted <> ? and age <> ? and status IS NOT NULL`, NewParser().Compile(expr).SQL)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "status", warnings[0].Column)
+	assert.Equal(t, "ne", warnings[0].Operator)
+	assert.Equal(t, 1, warnings[0].Line)
+
+	expr, warnings = AuditNulls(mustParse(t, `status eq "x" or country gt 1`), nullable)
+	assert.Empty(t, warnings)
+	assert.Equal(t, `status = ? or country > ?`, NewParser().Compile(expr).SQL)
+}
@@ -0,0 +1,434 @@
+package rqe
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// odataOperators maps the OData comparison operators onto the rqe operations
+var odataOperators = map[string]string{
+	"eq": "eq",
+	"ne": "ne",
+	"gt": "gt",
+	"ge": "gte",
+	"lt": "lt",
+	"le": "lte",
+}
+
+// odataFunctions maps the OData boolean string functions onto the rqe operations
+var odataFunctions = map[string]string{
+	"startswith": "prefix",
+	"contains":   "contains",
+}
+
+// odataDate matches the unquoted date and date time literals, `2024-01-01`, `2024-01-01T10:00:00Z`
+var odataDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?$`)
+
+type odataKind int
+
+const (
+	odataEOF odataKind = iota
+	odataIdent
+	odataString
+	odataLiteral // numbers and dates
+	odataParenOpen
+	odataParenClose
+	odataComma
+)
+
+type odataToken struct {
+	Kind  odataKind
+	Text  string
+	Value any
+	Line  int
+	Pos   int
+}
+
+// ParseOData converts an OData `$filter` (`name eq 'John' and age ge 25`) into a ParsedQuery, see
+// Parser.ParseODataExpr for the supported syntax
+func ParseOData(filter string, validateCol func(col string) bool, opts ...Option) (ParsedQuery, error) {
+	return withDefaults(opts).ParseOData(filter, validateCol)
+}
+
+// ParseOData converts an OData `$filter` into a ParsedQuery using the parser's configuration
+func (p *Parser) ParseOData(filter string, validateCol func(col string) bool) (ParsedQuery, error) {
+	expr, err := p.ParseODataExpr(filter, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	return p.compileChecked(expr)
+}
+
+// ParseODataExpr parses an OData `$filter` into the same expression tree as the equivalent rqe
+// filter, so clients of an OData service keep working unchanged. It supports the comparison
+// operators (`eq`, `ne`, `gt`, `ge`, `lt`, `le`), `in (...)`, `and`, `or`, `not`, parentheses and
+// the `startswith(col, 'x')` / `contains(col, 'x')` functions. Strings use single quotes, a quote
+// inside them is escaped as two single quotes. Dates and date times are written unquoted.
+// Errors point into the OData filter.
+func (p *Parser) ParseODataExpr(filter string, validateCol func(col string) bool) (Expr, error) {
+	if err := p.checkLength(len(filter)); err != nil {
+		return nil, err
+	}
+	toks, err := lexOData(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 1 {
+		return p.parsed(context.Background(), nil)
+	}
+
+	budget := p.newBudget()
+	if err := budget.charge(len(filter) + len(toks)*tokenCost); err != nil {
+		return nil, err
+	}
+
+	op := &odataParser{Parser: p, toks: toks, validateCol: p.columnValidator(validateCol), budget: budget, conditions: p.newConditionCounter()}
+	expr, err := op.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := op.current(); tok.Kind != odataEOF {
+		if tok.Kind == odataParenClose {
+			return nil, UnmatchedParenthesisError{Type: "closing", Line: tok.Line, Pos: tok.Pos}
+		}
+		return nil, UnexpectedTokenError{Token: tok.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+
+	return p.parsed(context.Background(), expr)
+}
+
+// lexOData splits the filter into tokens, the last one is always odataEOF
+func lexOData(filter string) ([]odataToken, error) {
+	toks := make([]odataToken, 0)
+	line := 1
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		tok := odataToken{Line: line, Pos: i}
+		switch {
+		case c == '\n':
+			line++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '(':
+			tok.Kind, tok.Text = odataParenOpen, "("
+			i++
+		case c == ')':
+			tok.Kind, tok.Text = odataParenClose, ")"
+			i++
+		case c == ',':
+			tok.Kind, tok.Text = odataComma, ","
+			i++
+		case c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(filter); j++ {
+				if filter[j] != '\'' {
+					sb.WriteByte(filter[j])
+					continue
+				}
+				if j+1 < len(filter) && filter[j+1] == '\'' {
+					sb.WriteByte('\'')
+					j++
+					continue
+				}
+				break
+			}
+			if j >= len(filter) {
+				return nil, UnexpectedTokenError{Token: "unterminated string", Line: line, Pos: i}
+			}
+			tok.Kind, tok.Text, tok.Value = odataString, filter[i:j+1], sb.String()
+			i = j + 1
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(filter) && (filter[j] == '_' || unicode.IsLetter(rune(filter[j])) || unicode.IsDigit(rune(filter[j]))) {
+				j++
+			}
+			tok.Kind, tok.Text = odataIdent, filter[i:j]
+			i = j
+		case unicode.IsDigit(rune(c)) || c == '-' && i+1 < len(filter) && unicode.IsDigit(rune(filter[i+1])):
+			j := i + 1
+			for j < len(filter) && strings.IndexByte("0123456789.:+-TZe", filter[j]) >= 0 {
+				j++
+			}
+			tok.Kind, tok.Text = odataLiteral, filter[i:j]
+			value, ok := odataLiteralValue(tok.Text)
+			if !ok {
+				return nil, UnexpectedTokenError{Token: tok.Text, Line: line, Pos: i}
+			}
+			tok.Value = value
+			i = j
+		default:
+			return nil, UnexpectedTokenError{Token: string(c), Line: line, Pos: i}
+		}
+		toks = append(toks, tok)
+	}
+	return append(toks, odataToken{Kind: odataEOF, Line: line, Pos: len(filter)}), nil
+}
+
+// odataLiteralValue decodes numbers like the rqe grammar does (int64 or float64) and keeps dates
+// as strings, the same as a quoted date in rqe
+func odataLiteralValue(text string) (any, bool) {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, true
+	}
+	if odataDate.MatchString(text) {
+		return text, true
+	}
+	return nil, false
+}
+
+// odataParser is a recursive descent parser over the tokens of an OData filter.
+//
+//	or      = and { "or" and }
+//	and     = unary { "and" unary }
+//	unary   = "not" unary | primary
+//	primary = "(" or ")" | function "(" column "," string ")" | column operator value | column "in" "(" values ")"
+type odataParser struct {
+	*Parser
+	toks        []odataToken
+	i           int
+	validateCol func(col string) bool
+	budget      *memoryBudget
+	conditions  *conditionCounter
+	// depth counts the parentheses and `not`s the parser is in
+	depth int
+}
+
+func (op *odataParser) current() odataToken {
+	return op.toks[op.i]
+}
+
+// next returns the current token and moves past it, the stream stays on odataEOF
+func (op *odataParser) next() odataToken {
+	tok := op.toks[op.i]
+	if tok.Kind != odataEOF {
+		op.i++
+	}
+	return tok
+}
+
+// isWord reports whether the current token is the keyword
+func (op *odataParser) isWord(word string) bool {
+	tok := op.current()
+	return tok.Kind == odataIdent && tok.Text == word
+}
+
+// expect consumes a token of the kind, failing with an UnexpectedTokenError naming what was expected
+func (op *odataParser) expect(kind odataKind, expected string) (odataToken, error) {
+	tok := op.next()
+	if tok.Kind != kind {
+		if kind == odataParenClose && tok.Kind == odataEOF {
+			return tok, UnmatchedParenthesisError{Type: "opening", Line: tok.Line, Pos: tok.Pos}
+		}
+		return tok, UnexpectedTokenError{Token: expected, Line: tok.Line, Pos: tok.Pos}
+	}
+	return tok, nil
+}
+
+func (op *odataParser) parseOr() (Expr, error) {
+	return op.parseLogical("or", op.parseAnd)
+}
+
+func (op *odataParser) parseAnd() (Expr, error) {
+	return op.parseLogical("and", op.parseUnary)
+}
+
+func (op *odataParser) parseLogical(operator string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []Expr{first}
+	for op.isWord(operator) {
+		tok := op.next()
+		if op.current().Kind == odataEOF {
+			return nil, &LogicalTokenError{Reason: "cannot end with a logical operation", Line: tok.Line, Pos: tok.Pos}
+		}
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, next)
+	}
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &Logical{Operator: operator, Exprs: exprs}, nil
+}
+
+func (op *odataParser) parseUnary() (Expr, error) {
+	if !op.isWord(notKeyword) {
+		return op.parsePrimary()
+	}
+	tok := op.next()
+	op.depth++
+	if err := op.checkDepth(op.depth, tok.Line, tok.Pos); err != nil {
+		return nil, err
+	}
+	expr, err := op.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	op.depth--
+	return &Not{Expr: expr}, nil
+}
+
+func (op *odataParser) parsePrimary() (Expr, error) {
+	tok := op.next()
+	switch {
+	case tok.Kind == odataParenOpen:
+		op.depth++
+		if err := op.checkDepth(op.depth, tok.Line, tok.Pos); err != nil {
+			return nil, err
+		}
+		expr, err := op.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		op.depth--
+		if _, err := op.expect(odataParenClose, ")"); err != nil {
+			if _, unmatched := err.(UnmatchedParenthesisError); unmatched {
+				return nil, UnmatchedParenthesisError{Type: "opening", Line: tok.Line, Pos: tok.Pos}
+			}
+			return nil, err
+		}
+		return expr, nil
+	case tok.Kind == odataIdent && (tok.Text == "and" || tok.Text == "or"):
+		return nil, &LogicalTokenError{Reason: "cannot start with a logical operation", Line: tok.Line, Pos: tok.Pos}
+	case tok.Kind == odataIdent && op.current().Kind == odataParenOpen:
+		return op.parseFunction(tok)
+	case tok.Kind == odataIdent:
+		return op.parseComparison(tok)
+	case tok.Kind == odataParenClose:
+		return nil, UnmatchedParenthesisError{Type: "closing", Line: tok.Line, Pos: tok.Pos}
+	case tok.Kind == odataEOF:
+		return nil, UnexpectedTokenError{Token: "expression", Line: tok.Line, Pos: tok.Pos}
+	default:
+		return nil, UnexpectedTokenError{Token: tok.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+}
+
+// parseFunction parses `startswith(column, 'value')` / `contains(column, 'value')`
+func (op *odataParser) parseFunction(fn odataToken) (Expr, error) {
+	opName, ok := odataFunctions[fn.Text]
+	op.next()
+	col, err := op.expect(odataIdent, "column")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, InvalidOperationError{Operation: fn.Text, Column: col.Text, Line: fn.Line, Pos: fn.Pos}
+	}
+	if !op.validateCol(col.Text) {
+		return nil, op.columnError(col.Text, col.Line, col.Pos)
+	}
+	if _, err := op.expect(odataComma, ","); err != nil {
+		return nil, err
+	}
+	val, err := op.expect(odataString, "string")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := op.expect(odataParenClose, ")"); err != nil {
+		return nil, err
+	}
+	return op.condition(col, fn.Text, opName, []any{val.Value}, fn.Pos)
+}
+
+// parseComparison parses `operator value` or `in (values)` following the column
+func (op *odataParser) parseComparison(col odataToken) (Expr, error) {
+	if !op.validateCol(col.Text) {
+		return nil, op.columnError(col.Text, col.Line, col.Pos)
+	}
+	operator := op.next()
+	if operator.Kind != odataIdent {
+		return nil, UnexpectedTokenError{Token: "equality operation", Line: operator.Line, Pos: operator.Pos}
+	}
+
+	if operator.Text == "in" {
+		if _, err := op.expect(odataParenOpen, "("); err != nil {
+			return nil, err
+		}
+		vals := make([]any, 0)
+		for {
+			val, err := op.parseValue(col)
+			if err != nil {
+				return nil, err
+			}
+			// rqe arrays are decoded from JSON, numbers in them are float64
+			if n, ok := val.(int64); ok {
+				val = float64(n)
+			}
+			vals = append(vals, val)
+			if op.current().Kind != odataComma {
+				break
+			}
+			op.next()
+		}
+		if _, err := op.expect(odataParenClose, ")"); err != nil {
+			return nil, err
+		}
+		return op.condition(col, operator.Text, "in", vals, col.Pos)
+	}
+
+	opName, ok := odataOperators[operator.Text]
+	if !ok {
+		return nil, InvalidOperationError{Operation: operator.Text, Column: col.Text, Line: col.Line, Pos: operator.Pos}
+	}
+	if op.isWord(nullKeyword) {
+		op.next()
+		if !op.allowsNull(col.Text, operationsMapped[opName]) {
+			return nil, InvalidOperationError{Operation: operator.Text + " " + nullKeyword, Column: col.Text, Line: col.Line, Pos: operator.Pos}
+		}
+		if _, _, err := op.operation(col.Text, operator.Text, opName, col.Line, col.Pos); err != nil {
+			return nil, err
+		}
+		if err := countCondition(op.budget, op.conditions, []any{nil}, col.Line, col.Pos); err != nil {
+			return nil, err
+		}
+		return &Condition{Column: col.Text, Operator: opName, Values: []any{nil}, Line: col.Line, Pos: col.Pos}, nil
+	}
+	val, err := op.parseValue(col)
+	if err != nil {
+		return nil, err
+	}
+	return op.condition(col, operator.Text, opName, []any{val}, col.Pos)
+}
+
+// parseValue parses a string, number, date or boolean literal
+func (op *odataParser) parseValue(col odataToken) (any, error) {
+	tok := op.next()
+	switch {
+	case tok.Kind == odataString || tok.Kind == odataLiteral:
+		return tok.Value, nil
+	case tok.Kind == odataIdent && (tok.Text == "true" || tok.Text == "false"):
+		return tok.Text == "true", nil
+	default:
+		return nil, MissingValueError{Column: col.Text, Line: tok.Line, Pos: tok.Pos}
+	}
+}
+
+// condition checks and resolves the values the same way the rqe grammar does
+func (op *odataParser) condition(col odataToken, opValue, opName string, vals []any, pos int) (*Condition, error) {
+	opName, meta, err := op.operation(col.Text, opValue, opName, col.Line, pos)
+	if err != nil {
+		return nil, err
+	}
+	if vals, err = op.resolveValues(col.Text, opValue, opName, meta, vals, false, col.Line, pos); err != nil {
+		return nil, err
+	}
+	vals = op.sanitizeValues(opName, meta, vals)
+	if err := countCondition(op.budget, op.conditions, vals, col.Line, pos); err != nil {
+		return nil, err
+	}
+	return &Condition{Column: col.Text, Operator: opName, Values: vals, Line: col.Line, Pos: pos}, nil
+}
@@ -0,0 +1,45 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOData(t *testing.T) {
+	q, err := ParseOData(`name eq 'O''Brien' and (age ge 25 or startswith(city, 'New')) and not (status in ('a', 'b'))`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ? and (age >= ? or city LIKE ? ESCAPE '!') and NOT (status IN (?, ?))", q.SQL)
+	assert.Equal(t, []interface{}{"O'Brien", int64(25), "New%", "a", "b"}, q.Args)
+
+	q, err = ParseOData(`deleted_at eq null and active eq true and created_at lt 2024-01-01T00:00:00Z`, validateColumn, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at IS NULL and active = $1 and created_at < $2", q.SQL)
+	assert.Equal(t, []interface{}{true, "2024-01-01T00:00:00Z"}, q.Args)
+
+	// the same tree as the rqe filter
+	odata, err := NewParser().ParseODataExpr(`age le 3 and tags in (1, 2) and contains(name, 'jo')`, validateColumn)
+	assert.NoError(t, err)
+	expr, err := ParseExpr(`age lte 3 and tags in [1, 2] and name contains "jo"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, Canonical(expr), Canonical(odata))
+
+	expr, err = NewParser().ParseODataExpr(``, validateColumn)
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+
+	_, err = ParseOData(`name eq 'x' and secret eq 1`, func(col string) bool { return col == "name" })
+	assert.Equal(t, InvalidColumnError{Column: "secret", Line: 1, Pos: 16}, err)
+
+	_, err = ParseOData(`endswith(name, 'x')`, validateColumn)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	_, err = ParseOData(`(age eq 1`, validateColumn)
+	assert.Equal(t, UnmatchedParenthesisError{Type: "opening", Line: 1, Pos: 0}, err)
+
+	_, err = ParseOData(`name eq 'x`, validateColumn)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+
+	_, err = ParseOData(`age eq`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+}
@@ -0,0 +1,374 @@
+package rqe
+
+import "time"
+
+// Option configures a Parser
+type Option func(p *Parser)
+
+// WithInlineEnum makes the parser emit the given integer values for the column as
+// SQL literals instead of placeholders. This helps planners that optimize better
+// with constants on small integer enums (status codes, types ... etc).
+//
+// Only int64 literals that are part of values are inlined. Strings, floats and
+// any value outside of the set are always bound as arguments.
+func WithInlineEnum(column string, values ...int64) Option {
+	return func(p *Parser) {
+		allowed, ok := p.inlineEnums[column]
+		if !ok {
+			allowed = make(map[int64]struct{}, len(values))
+			p.inlineEnums[column] = allowed
+		}
+		for _, v := range values {
+			allowed[v] = struct{}{}
+		}
+	}
+}
+
+// WithSanitizer overrides the sanitizer of an operation (`eq`, `in` ... etc) for this parser.
+// Passing a nil sanitizer disables sanitizing for the operation.
+func WithSanitizer(operation string, sanitize Sanitizer) Option {
+	return func(p *Parser) {
+		p.sanitizers[operation] = sanitize
+	}
+}
+
+// WithFoldedColumns makes comparisons on the given columns case and accent insensitive
+// by wrapping both sides with `LOWER(unaccent(...))`, so `name eq "jose"` matches "José".
+//
+// This relies on the postgres `unaccent` extension being installed. MySQL, SQL Server and
+// Oracle compare the column with an accent insensitive collation instead (`utf8mb4_0900_ai_ci`,
+// `Latin1_General_CI_AI`, `BINARY_AI`), BigQuery cannot fold and fails with an UnsupportedOperationError.
+func WithFoldedColumns(columns ...string) Option {
+	return func(p *Parser) {
+		for _, col := range columns {
+			p.folded[col] = struct{}{}
+		}
+	}
+}
+
+// WithKeywordAliases registers localized keywords for the logical and comparison operators.
+// The map goes from the alias to the canonical operator, e.g. for a spanish UI:
+//
+//	rqe.WithKeywordAliases(map[string]string{"y": "and", "o": "or", "igual": "eq"})
+//
+// Aliases pointing to an unknown operator fail at parse time with an InvalidOperationError.
+func WithKeywordAliases(aliases map[string]string) Option {
+	return func(p *Parser) {
+		for alias, op := range aliases {
+			p.aliases[alias] = op
+		}
+	}
+}
+
+// WithClock sets the function used to get the current time when resolving
+// relative time literals such as `"now-7d"`, defaults to time.Now
+func WithClock(now func() time.Time) Option {
+	return func(p *Parser) {
+		p.now = now
+	}
+}
+
+// WithShardKey exposes the values the filter pins the column to on ParsedQuery.ShardKeys,
+// so callers can route to the right shard or reject filters that span every shard
+func WithShardKey(column string) Option {
+	return func(p *Parser) {
+		p.shardKey = column
+	}
+}
+
+// WithBareWords lets unquoted single words be used as string values, e.g. `status eq active`.
+// The `null` keyword, logical operations and macro names keep their meaning and must be quoted
+// to be used as strings.
+func WithBareWords() Option {
+	return func(p *Parser) {
+		p.bareWords = true
+	}
+}
+
+// WithCollector records the anonymized shape of every successfully parsed filter
+func WithCollector(c *Collector) Option {
+	return func(p *Parser) {
+		p.collector = c
+	}
+}
+
+// WithTemporal declares the filtered table as temporal, enabling `asof("2024-01-31")` filters
+func WithTemporal(t Temporal) Option {
+	return func(p *Parser) {
+		p.temporal = &t
+	}
+}
+
+// WithViews lets clients reuse the registry's saved views with `include(name, args...)`
+func WithViews(v *Views) Option {
+	return func(p *Parser) {
+		p.views = v
+	}
+}
+
+// WithRules checks every parsed filter against the rules, violations are returned
+// together as a RuleViolationError
+func WithRules(rules ...Rule) Option {
+	return func(p *Parser) {
+		p.rules = append(p.rules, rules...)
+	}
+}
+
+// WithContextRules checks every parsed filter against rules reading the request's context,
+// e.g. the current tenant, see ParseContext
+func WithContextRules(rules ...ContextRule) Option {
+	return func(p *Parser) {
+		p.contextRules = append(p.contextRules, rules...)
+	}
+}
+
+// WithMandatoryColumns rejects every filter that does not restrict all of the columns,
+// for values clients must supply themselves (tenant, date range ... etc)
+func WithMandatoryColumns(columns ...string) Option {
+	return WithRules(Mandatory(columns...))
+}
+
+// WithMaxSpan rejects filters on the date column covering more than max,
+// protecting large tables from unbounded scans
+func WithMaxSpan(column string, max time.Duration) Option {
+	return WithRules(MaxSpan(column, max))
+}
+
+// WithIndexHint attaches an index to the column, builders emit it as `USE INDEX (index)`
+// (or `FORCE INDEX` when forced) whenever the column dominates the filter. Only MySQL style
+// databases understand the hint, it is never emitted under the other dialects.
+func WithIndexHint(column, index string, force bool) Option {
+	return func(p *Parser) {
+		p.indexHints[column] = IndexHint{Index: index, Force: force}
+	}
+}
+
+// WithDialect compiles filters for the given database, see PostgresDialect and MySQLDialect
+func WithDialect(d Dialect) Option {
+	return func(p *Parser) {
+		p.dialect = d
+	}
+}
+
+// WithNamedArgs emits named parameters (`:name_0`, `:age_1`) filled in ParsedQuery.NamedArgs
+// instead of the dialect's positional placeholders, ready for sqlx.Named. Args keeps the
+// same values in order.
+func WithNamedArgs() Option {
+	return func(p *Parser) {
+		p.namedArgs = true
+	}
+}
+
+// WithSQLPatterns composes the LIKE patterns of substring searches in SQL (`col LIKE CONCAT('%', ?, '%')`)
+// and binds the bare value, so statement digests group every search under one shape
+func WithSQLPatterns() Option {
+	return func(p *Parser) {
+		p.sqlPatterns = true
+	}
+}
+
+// WithCapabilities enables the operations relying on optional database features,
+// e.g. WithCapabilities(CapabilityFuzzyStrMatch) for `within_edits`
+func WithCapabilities(capabilities ...string) Option {
+	return func(p *Parser) {
+		for _, c := range capabilities {
+			p.caps[c] = struct{}{}
+		}
+	}
+}
+
+// WithDigestColumns compiles equality (`eq`, `ne`, `in`, `nin`) on large text columns to a comparison of
+// their `<column>_md5` companion column against the Digest of the values, so the hash index is used.
+// The companion is named after the column's database name (Column.DBName) in the same table.
+// Null checks and other operations still use the column itself.
+func WithDigestColumns(columns ...string) Option {
+	return func(p *Parser) {
+		for _, col := range columns {
+			p.digests[col] = struct{}{}
+		}
+	}
+}
+
+// WithRateLimiter throttles ParseFor per caller key with the limiter, see RateLimiter
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(p *Parser) {
+		p.limiter = l
+	}
+}
+
+// WithMacroTimeout abandons macro handlers running longer than timeout, failing the filter with
+// an InvalidMacroValueError. Panicking handlers are always recovered.
+func WithMacroTimeout(timeout time.Duration) Option {
+	return func(p *Parser) {
+		p.macroTimeout = timeout
+	}
+}
+
+// WithSchema restricts filters, sorts and projections to the capabilities the schema declares.
+// The validateCol callback still applies on top of it and may be nil.
+func WithSchema(s Schema) Option {
+	return func(p *Parser) {
+		p.schema = s
+	}
+}
+
+// WithHardened re-checks every compiled query before returning it and fails closed with an
+// UnsafeSQLError when the SQL holds a semicolon, a comment, an unexpected literal or an identifier
+// that is neither a keyword of the built-in operations nor a filtered column. Custom dialect
+// operators may only use those keywords.
+func WithHardened() Option {
+	return func(p *Parser) {
+		p.hardened = true
+	}
+}
+
+// WithClientTimeZone is the zone date literals without an offset are written in, UTC by default.
+// They are converted for the columns declaring a storage Column.TimeZone.
+func WithClientTimeZone(loc *time.Location) Option {
+	return func(p *Parser) {
+		p.clientZone = loc
+	}
+}
+
+// WithExclusiveBetween makes `between` a half open range, `col between [a, b]` compiles to
+// `col >= ? AND col < ?` like `from_until`, so date ranges do not miss the end of their last day
+func WithExclusiveBetween() Option {
+	return func(p *Parser) {
+		p.exclusiveBetween = true
+	}
+}
+
+// WithFragment registers a named server side condition, built as an expression tree
+// (`&rqe.Condition{...}` or a trusted rqe.ParseExpr) rather than raw SQL, see Parser.AndFragments
+func WithFragment(name string, expr Expr) Option {
+	return func(p *Parser) {
+		p.fragments[name] = expr
+	}
+}
+
+// WithTableValidator whitelists the tables and views Builder.From accepts, From fails without it
+func WithTableValidator(validateTable func(table string) bool) Option {
+	return func(p *Parser) {
+		p.validateTable = validateTable
+	}
+}
+
+// WithMemoryBudget bounds the approximate memory, in bytes, parsing a single filter may use for
+// its input, tokens and values. Filters going over fail with a MemoryBudgetError. 0 means no limit.
+func WithMemoryBudget(bytes int) Option {
+	return func(p *Parser) {
+		p.memoryBudget = bytes
+	}
+}
+
+// WithMaxDepth rejects filters nesting groups (parentheses, and for JSON filters `and` / `or` /
+// `not`) deeper than depth with a DepthLimitExceededError. 0 disables the limit.
+func WithMaxDepth(depth int) Option {
+	return func(p *Parser) {
+		p.maxDepth = depth
+	}
+}
+
+// WithMaxConditions rejects filters with more than max conditions (a tuple comparison counts
+// as one) with a ConditionLimitError, bounding the cost of a single query. 0 disables the limit.
+func WithMaxConditions(max int) Option {
+	return func(p *Parser) {
+		p.maxConditions = max
+	}
+}
+
+// WithMaxListSize rejects arrays (`in`, `nin` ...) of more than size values with a
+// ListSizeError instead of binding a placeholder for each. 0 disables the limit.
+func WithMaxListSize(size int) Option {
+	return func(p *Parser) {
+		p.maxListSize = size
+	}
+}
+
+// WithMaxLength rejects filters longer than bytes with an InputTooLongError before they are
+// tokenized. 0 disables the limit.
+func WithMaxLength(bytes int) Option {
+	return func(p *Parser) {
+		p.maxLength = bytes
+	}
+}
+
+// WithTableAlias qualifies every emitted column with the table alias (`u.name = ?`), so the
+// clause can be embedded in joins without ambiguous columns. Columns already qualified by the
+// schema's DBName or WithColumnTable keep their table.
+func WithTableAlias(alias string) Option {
+	return func(p *Parser) {
+		p.tableAlias = alias
+	}
+}
+
+// WithColumnTable qualifies the column with the table (or alias) it belongs to in a join,
+// taking precedence over WithTableAlias
+func WithColumnTable(column, table string) Option {
+	return func(p *Parser) {
+		p.columnTables[column] = table
+	}
+}
+
+// WithDeniedColumns rejects filters, sorts and projections on sensitive columns (password_hash,
+// ssn ...) with a DeniedColumnError, even when validateCol or the schema would allow them
+func WithDeniedColumns(columns ...string) Option {
+	return func(p *Parser) {
+		for _, col := range columns {
+			p.deniedColumns[col] = struct{}{}
+		}
+	}
+}
+
+// WithMaskedColumnErrors makes every rejected column, denied or unknown, an InvalidColumnError
+// naming `***` so clients cannot probe which columns exist
+func WithMaskedColumnErrors() Option {
+	return func(p *Parser) {
+		p.maskColumns = true
+	}
+}
+
+// WithScopes ANDs the scopes around every filter the parser returns, empty filters included,
+// so multi tenant services cannot forget them. Scopes reading the context need ParseContext or
+// BeginContext, the other entry points give them an empty context.
+func WithScopes(scopes ...Scope) Option {
+	return func(p *Parser) {
+		p.scopes = append(p.scopes, scopes...)
+	}
+}
+
+// WithoutMacros rejects every macro with a MacrosDisabledError, leaving the pure comparison grammar
+func WithoutMacros() Option {
+	return func(p *Parser) {
+		p.noMacros = true
+	}
+}
+
+// WithTokenMode sets how the string grammar treats unexpected tokens, StrictTokens by default
+func WithTokenMode(mode TokenMode) Option {
+	return func(p *Parser) {
+		p.tokenMode = mode
+	}
+}
+
+// WithColumnCompiler hands the SQL of every condition on the column but null checks to the compiler
+func WithColumnCompiler(column string, compiler ColumnCompiler) Option {
+	return func(p *Parser) {
+		p.columnCompilers[column] = compiler
+	}
+}
+
+// WithWeekStart sets the first day of the week of `this_week` / `last_week`, monday by default
+func WithWeekStart(day time.Weekday) Option {
+	return func(p *Parser) {
+		p.weekStart = day
+	}
+}
+
+// WithLocale sets the week start from the region of the locale (`en-US` starts on sunday,
+// `fr-FR` on monday), see WithWeekStart
+func WithLocale(locale string) Option {
+	return func(p *Parser) {
+		p.weekStart = weekStartOf(locale)
+	}
+}
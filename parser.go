@@ -1,13 +1,16 @@
 package rqe
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/baderkha/rqe/macros"
 	"github.com/bzick/tokenizer"
-	"github.com/davecgh/go-spew/spew"
 )
 
 const (
@@ -19,17 +22,84 @@ const (
 	TParenClose
 	TArray
 	TMacro
+	TMinus
 )
 
 type OperationMeta struct {
-	Value           func(quotes int) string
-	IsMultiValue    bool
+	Value        func(quotes int) string
+	IsMultiValue bool
+	// MultiValueLimit is the exact number of values the operation needs, 0 means any amount
 	MultiValueLimit int
+	// Sanitize, when set, is applied to every value of the operation before it is bound
+	Sanitize Sanitizer
+	// NullValue is the SQL emitted when the operation is compared against the
+	// `null` keyword (e.g. `IS NULL`). Empty means null is not allowed.
+	NullValue string
+	// Format, when set, renders the whole comparison instead of `col Value`, for operations
+	// wrapping the column (e.g. `(col & ?) <> 0`). A single value is bound for every placeholder.
+	Format func(col string, quotes int) string
+	// IntegerOnly operations reject any value that is not an integer literal
+	IntegerOnly bool
+	// Validate, when set, checks every value of the operation before it is bound
+	Validate func(val any) error
+	// Dialects renders the comparison for the dialects (by Dialect.Name) spelling it differently,
+	// like Format. A nil entry means the dialect cannot express the operation.
+	Dialects map[string]func(col string, quotes int) string
+	// Like, when set, makes the operation a LIKE search for the single value with the wildcards
+	// around it. Wildcards in the value itself are escaped.
+	Like *LikeWildcards
+	// Normalize, when set, checks the values as a whole and returns them in the form they are bound
+	Normalize func(vals []any) ([]any, error)
+	// Requires is the database capability (e.g. an extension) the operation needs, it is only
+	// available once enabled with WithCapabilities
+	Requires string
 }
 
+// sql renders the comparison of the operation against the column
+func (o OperationMeta) sql(col string, quotes int) string {
+	if o.Format != nil {
+		return o.Format(col, quotes)
+	}
+	return fmt.Sprintf("%s %s", col, o.Value(quotes))
+}
+
+// nullKeyword is the bare value that compiles to an IS NULL / IS NOT NULL check
+const nullKeyword = "null"
+
+// notKeyword negates the parenthesized expression following it
+const notKeyword = "not"
+
 type ParsedQuery struct {
 	SQL  string
 	Args []interface{}
+	// Columns referenced by the filter in order of first appearance
+	Columns []string
+	// AsOf is the point in time requested with `asof(...)` on a system versioned table,
+	// bind it with `FROM table FOR SYSTEM_TIME AS OF ?`. nil when not requested.
+	AsOf *time.Time
+	// ShardKeys are the values of the configured shard key column the filter is pinned to.
+	// nil when no shard key is configured or the filter may span every shard, see WithShardKey.
+	ShardKeys []any
+	// NamedArgs are the arguments by parameter name when WithNamedArgs is used, nil otherwise
+	NamedArgs map[string]interface{}
+	// ArgInfo describes each argument of Args, at the same index
+	ArgInfo []ArgInfo
+	// Having is the HAVING clause (without the keyword) of the conditions on aggregate columns
+	// (see Column.Aggregate), empty when there are none. Its placeholders follow the ones of SQL.
+	Having string
+	// HavingArgs are the arguments of Having, they are also the last arguments of Args
+	HavingArgs []interface{}
+}
+
+// ArgInfo describes a bound argument so drivers wrappers, loggers or encryption layers can
+// handle it without parsing the filter again
+type ArgInfo struct {
+	// Column the argument is compared against, empty for server side conditions
+	Column string
+	// Operator is the operation the argument is bound to (`eq`, `in` ... etc) or the tuple operation
+	Operator string
+	// GoType is the type of the argument, nil for a nil argument
+	GoType reflect.Type
 }
 
 var operationsMapped = map[string]OperationMeta{
@@ -44,6 +114,8 @@ var operationsMapped = map[string]OperationMeta{
 	"eq": {
 		Value:        func(_ int) string { return "= ?" },
 		IsMultiValue: false,
+		Sanitize:     TrimSpace,
+		NullValue:    "IS NULL",
 	},
 	"gte": {
 		Value:        func(_ int) string { return ">= ?" },
@@ -56,6 +128,21 @@ var operationsMapped = map[string]OperationMeta{
 	"ne": {
 		Value:        func(_ int) string { return "<> ?" },
 		IsMultiValue: false,
+		Sanitize:     TrimSpace,
+		NullValue:    "IS NOT NULL",
+	},
+	// nseq is the null safe equality, `null` values compare equal to each other
+	"nseq": {
+		Value:     func(_ int) string { return "IS NOT DISTINCT FROM ?" },
+		Sanitize:  TrimSpace,
+		NullValue: "IS NULL",
+		Dialects: map[string]func(col string, quotes int) string{
+			"mysql": func(col string, _ int) string { return fmt.Sprintf("%s <=> ?", col) },
+			// IS NOT DISTINCT FROM only exists since SQL Server 2022, INTERSECT treats nulls as equal
+			"mssql": func(col string, _ int) string { return fmt.Sprintf("EXISTS (SELECT %s INTERSECT SELECT ?)", col) },
+			// DECODE is the only comparison treating two nulls as equal
+			"oracle": func(col string, _ int) string { return fmt.Sprintf("DECODE(%s, ?, 1, 0) = 1", col) },
+		},
 	},
 	"in": {
 		Value: func(quotes int) string {
@@ -67,15 +154,119 @@ var operationsMapped = map[string]OperationMeta{
 		},
 		IsMultiValue: true,
 	},
+	"nin": {
+		Value: func(quotes int) string {
+			placeholders := make([]string, quotes)
+			for i := range placeholders {
+				placeholders[i] = "?"
+			}
+			return fmt.Sprintf("NOT IN (%s)", strings.Join(placeholders, ", "))
+		},
+		IsMultiValue: true,
+	},
 	"between": {
 		Value:        func(_ int) string { return "BETWEEN ? AND ?" },
 		IsMultiValue: true, MultiValueLimit: 2,
 	},
+	"nbetween": {
+		Value:        func(_ int) string { return "NOT BETWEEN ? AND ?" },
+		IsMultiValue: true, MultiValueLimit: 2,
+	},
+	// from_until is the half open range `[from, until)`, `created_at from_until ["2024-01-01", "2024-02-01"]`
+	// covers all of january where an inclusive between would need the last instant of the 31st
+	"from_until": {
+		Value:        func(_ int) string { return ">= ? AND < ?" },
+		Format:       func(col string, _ int) string { return fmt.Sprintf("%s >= ? AND %s < ?", col, col) },
+		IsMultiValue: true, MultiValueLimit: 2,
+	},
+	"band": {
+		Value:       func(_ int) string { return "& ?" },
+		Format:      func(col string, _ int) string { return fmt.Sprintf("(%s & ?) <> 0", col) },
+		IntegerOnly: true,
+		Dialects: map[string]func(col string, quotes int) string{
+			"oracle": func(col string, _ int) string { return fmt.Sprintf("BITAND(%s, ?) <> 0", col) },
+		},
+	},
+	"bor": {
+		Value:       func(_ int) string { return "| ?" },
+		Format:      func(col string, _ int) string { return fmt.Sprintf("(%s | ?) = ?", col) },
+		IntegerOnly: true,
+		Dialects: map[string]func(col string, quotes int) string{
+			"oracle": func(col string, _ int) string { return fmt.Sprintf("(%s + ? - BITAND(%s, ?)) = ?", col, col) },
+		},
+	},
+	// sounds_like relies on SOUNDEX, native on MySQL and provided by the fuzzystrmatch extension on postgres
+	"sounds_like": {
+		Value:  func(_ int) string { return "= SOUNDEX(?)" },
+		Format: func(col string, _ int) string { return fmt.Sprintf("SOUNDEX(%s) = SOUNDEX(?)", col) },
+	},
+	// in_subnet is the postgres inet containment operator, `<<` shifts bits everywhere else
+	"in_subnet": {
+		Value:    func(_ int) string { return "<< ?" },
+		Validate: validateCIDR,
+		Dialects: map[string]func(col string, quotes int) string{
+			"":         nil,
+			"mysql":    nil,
+			"mssql":    nil,
+			"oracle":   nil,
+			"bigquery": nil,
+		},
+	},
+	"like": {
+		Value: func(_ int) string { return "LIKE ?" },
+	},
+	// prefix only matches the start of the value so an index on the column stays usable,
+	// e.g. for autocomplete. Empty prefixes are rejected as they would match every row.
+	"prefix": {
+		Value:    func(_ int) string { return "LIKE ? ESCAPE '" + likeEscape + "'" },
+		Like:     &LikeWildcards{Trailing: true},
+		Validate: validatePrefix,
+	},
+	// within_edits matches values at most n edits (levenshtein distance) away, `name within_edits ["jon", 2]`.
+	// It needs the fuzzystrmatch extension on postgres.
+	"within_edits": {
+		Value:           func(_ int) string { return "<= ?" },
+		IsMultiValue:    true,
+		MultiValueLimit: 2,
+		Format:          func(col string, _ int) string { return fmt.Sprintf("levenshtein(%s, ?) <= ?", col) },
+		Normalize:       normalizeEdits,
+		Requires:        CapabilityFuzzyStrMatch,
+		Dialects: map[string]func(col string, quotes int) string{
+			"oracle":   func(col string, _ int) string { return fmt.Sprintf("UTL_MATCH.EDIT_DISTANCE(%s, ?) <= ?", col) },
+			"bigquery": func(col string, _ int) string { return fmt.Sprintf("EDIT_DISTANCE(%s, ?) <= ?", col) },
+			"mysql":    nil,
+			"mssql":    nil,
+		},
+	},
+	// contains is a substring search, `%` and `_` in the value match literally
+	"contains": {
+		Value:    func(_ int) string { return "LIKE ? ESCAPE '" + likeEscape + "'" },
+		Like:     &LikeWildcards{Leading: true, Trailing: true},
+		Validate: validateString,
+	},
+	// ilike is a case insensitive like
+	"ilike": {
+		Value:  func(_ int) string { return "LIKE LOWER(?)" },
+		Format: func(col string, _ int) string { return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", col) },
+		Dialects: map[string]func(col string, quotes int) string{
+			"postgres": func(col string, _ int) string { return fmt.Sprintf("%s ILIKE ?", col) },
+		},
+	},
+	// regex matches a regular expression, SQL Server has no native support
+	"regex": {
+		Value: func(_ int) string { return "REGEXP ?" },
+		Dialects: map[string]func(col string, quotes int) string{
+			"postgres": func(col string, _ int) string { return fmt.Sprintf("%s ~ ?", col) },
+			"oracle":   func(col string, _ int) string { return fmt.Sprintf("REGEXP_LIKE(%s, ?)", col) },
+			"bigquery": func(col string, _ int) string { return fmt.Sprintf("REGEXP_CONTAINS(%s, ?)", col) },
+			"mssql":    nil,
+		},
+	},
 }
 
 // Parse takes a human-readable query string and converts it into a structured SQL statement
 // with placeholders and corresponding argument values. It allows logical operators (`AND`, `OR`),
-// comparison operators (`=`, `!=`, `>`, `<`, `>=`, `<=`), and multi-value expressions (`IN`, `BETWEEN`).
+// comparison operators (`eq`, `ne`, `gt`, `lt`, `gte`, `lte` or their symbolic forms `=`, `!=`, `>`, `<`, `>=`, `<=`), and multi-value expressions (`IN`, `BETWEEN`, `NBETWEEN`).
 //
 // The function ensures that only valid column names are used, supports nested expressions with
 // parentheses, and generates a properly formatted SQL string.
@@ -89,7 +280,7 @@ var operationsMapped = map[string]OperationMeta{
 //
 // Returns:
 //   - ParsedQuery: A struct containing:
-//   - SQL (string): The formatted SQL query with placeholders (`?`).
+//   - SQL (string): The formatted SQL query with placeholders (`?`, or the dialect's own, see WithDialect).
 //   - Args ([]interface{}): A slice containing the argument values for the SQL query.
 //   - error: An error if parsing fails, including detailed error messages with line and column numbers.
 //
@@ -112,9 +303,7 @@ var operationsMapped = map[string]OperationMeta{
 // Example Output:
 //
 //	SQL:
-//	name = ?
-//	AND age >= ?
-//	OR ( city = ? AND status IN ( ?, ? ) )
+//	(name = ? and age >= ?) or (city = ? and status IN (?, ?))
 //
 //	Args:
 //	["John", 25, "New York", "active", "pending"]
@@ -125,168 +314,694 @@ var operationsMapped = map[string]OperationMeta{
 //   - MissingValueError: When an operation is missing a required value.
 //   - InvalidOperationError: When an operation is not valid for a given context.
 //   - UnmatchedParenthesisError: When there are unmatched opening or closing parentheses.
+//   - ValueCountError: When `between` / `nbetween` / `from_until` do not receive exactly two values.
 //
 // Notes:
-//   - Multi-value expressions (`IN`, `BETWEEN`) must have the correct number of values.
-//   - Strings should be enclosed in double (`"`) or single (`'`) quotes.
+//   - The bare `null` keyword is only valid with `eq` / `ne` / `nseq` and compiles to `IS NULL` / `IS NOT NULL`.
+//   - Multi-value expressions (`IN`, `BETWEEN`, `NBETWEEN`) must have the correct number of values.
+//   - Numbers may be negative (`-100`) and use exponent notation (`1.5e6`), which binds as a float64.
+//   - Quoted relative times (`"now"`, `"now-7d"`, `"now+1h"`) are resolved to a time.Time argument.
+//   - Strings should be enclosed in double (`"`) or single (`'`) quotes, unless WithBareWords is used.
 //   - Arrays should be enclosed in square brackets (`[ ]`).
-func Parse(filter string, validateCol func(col string) bool) (ParsedQuery, error) {
-	var sb strings.Builder
-	vals := make([]interface{}, 0)
+//   - The Default parser is used (see Configure), opts extend its configuration for this call.
+func Parse(filter string, validateCol func(col string) bool, opts ...Option) (ParsedQuery, error) {
+	return withDefaults(opts).Parse(filter, validateCol)
+}
+
+// Parser holds the configuration used when converting filters into SQL.
+// Create one with NewParser and reuse it across requests.
+type Parser struct {
+	inlineEnums  map[string]map[int64]struct{}
+	sanitizers   map[string]Sanitizer
+	folded       map[string]struct{}
+	aliases      map[string]string
+	now          func() time.Time
+	shardKey     string
+	bareWords    bool
+	collector    *Collector
+	temporal     *Temporal
+	views        *Views
+	rules        []Rule
+	indexHints   map[string]IndexHint
+	dialect      Dialect
+	namedArgs    bool
+	sqlPatterns  bool
+	caps         map[string]struct{}
+	digests      map[string]struct{}
+	limiter      *RateLimiter
+	macroTimeout time.Duration
+	schema       Schema
+	hardened     bool
+	clientZone   *time.Location
+	weekStart    time.Weekday
+	// exclusiveBetween parses `between` as `from_until`
+	exclusiveBetween bool
+	fragments        map[string]Expr
+	validateTable    func(table string) bool
+	memoryBudget     int
+	contextRules     []ContextRule
+	maxDepth         int
+	maxConditions    int
+	maxListSize      int
+	maxLength        int
+	// tableAlias and columnTables qualify the emitted columns, see WithTableAlias
+	tableAlias   string
+	columnTables map[string]string
+	// deniedColumns can never be used, whatever validateCol or the schema say
+	deniedColumns map[string]struct{}
+	maskColumns   bool
+	scopes        []Scope
+	noMacros      bool
+	tokenMode     TokenMode
+	// columnCompilers take over the SQL of their column, see WithColumnCompiler
+	columnCompilers map[string]ColumnCompiler
+}
+
+// NewParser creates a Parser configured with the given options
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		inlineEnums:     make(map[string]map[int64]struct{}),
+		sanitizers:      make(map[string]Sanitizer),
+		folded:          make(map[string]struct{}),
+		aliases:         make(map[string]string),
+		indexHints:      make(map[string]IndexHint),
+		caps:            make(map[string]struct{}),
+		digests:         make(map[string]struct{}),
+		fragments:       make(map[string]Expr),
+		columnTables:    make(map[string]string),
+		deniedColumns:   make(map[string]struct{}),
+		columnCompilers: make(map[string]ColumnCompiler),
+		now:             time.Now,
+		weekStart:       time.Monday,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Parse converts the filter into a ParsedQuery using the parser's configuration.
+// See the package level Parse for the grammar and possible errors.
+func (p *Parser) Parse(filter string, validateCol func(col string) bool) (ParsedQuery, error) {
+	expr, err := p.ParseExpr(filter, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	return p.compileChecked(expr)
+}
+
+// compileChecked compiles the expression, asserting the SQL is safe in hardened mode
+func (p *Parser) compileChecked(expr Expr) (ParsedQuery, error) {
+	q := p.compile(expr)
+	if p.hardened {
+		if err := p.assertSafe(q, expr); err != nil {
+			return ParsedQuery{}, err
+		}
+	}
+	q.SQL, q.Having, q.NamedArgs = p.bindHaving(q.SQL, q.Having, q.Args, q.ArgInfo)
+	return q, nil
+}
+
+// ParseExpr parses the filter into its expression tree without compiling it to SQL.
+// An empty filter returns a nil Expr.
+func ParseExpr(filter string, validateCol func(col string) bool, opts ...Option) (Expr, error) {
+	return withDefaults(opts).ParseExpr(filter, validateCol)
+}
 
-	// Configure tokenizer
+// ParseExpr parses the filter into its expression tree using the parser's configuration.
+// Values are fully resolved (macros, relative times, sanitizers) but not yet compiled.
+func (p *Parser) ParseExpr(filter string, validateCol func(col string) bool) (Expr, error) {
+	return p.ParseExprContext(context.Background(), filter, validateCol)
+}
+
+// ParseExprContext is ParseExpr stopping with the context's error once it is done, the context
+// is handed to macros implementing macros.ContextMacro and to the ContextRules
+func (p *Parser) ParseExprContext(ctx context.Context, filter string, validateCol func(col string) bool) (Expr, error) {
+	expr, err := p.parseFilter(ctx, filter, validateCol, p.newConditionCounter())
+	if err != nil {
+		return nil, err
+	}
+	return p.parsed(ctx, expr)
+}
+
+// parseFilter parses the filter without checking the rules nor ANDing the scopes around it,
+// the Builder does both once for all its fragments and counts their conditions together
+func (p *Parser) parseFilter(ctx context.Context, filter string, validateCol func(col string) bool, conditions *conditionCounter) (Expr, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.checkLength(len(filter)); err != nil {
+		return nil, err
+	}
+
+	// Create tokens' stream
+	stream := newTokenizer().ParseString(filter)
+	defer stream.Close()
+
+	if !stream.IsValid() {
+		return nil, p.unreadable(filter)
+	}
+
+	budget := p.newBudget()
+	if err := budget.chargeTokens(filter, stream); err != nil {
+		return nil, err
+	}
+
+	fp := &filterParser{Parser: p, ctx: ctx, stream: stream, validateCol: p.columnValidator(validateCol), budget: budget, conditions: conditions}
+	if fp.unreadableStart() {
+		return nil, nil
+	}
+	expr, err := fp.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	// anything left over was not consumed by the grammar
+	if err := fp.leftover(); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parsed finishes parsing the client's expression, whatever its syntax : it checks the rules,
+// records it and ANDs the scopes around it
+func (p *Parser) parsed(ctx context.Context, expr Expr) (Expr, error) {
+	expr, err := p.checked(ctx, expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.applyScopes(ctx, expr)
+}
+
+// checked checks the rules of the client's expression and records it
+func (p *Parser) checked(ctx context.Context, expr Expr) (Expr, error) {
+	if err := p.checkRules(ctx, expr); err != nil {
+		return nil, err
+	}
+	if p.collector != nil {
+		p.collector.Record(expr)
+	}
+	return expr, nil
+}
+
+// newTokenizer configures the tokenizer for the filter grammar
+func newTokenizer() *tokenizer.Tokenizer {
 	parser := tokenizer.New()
-	parser.DefineTokens(TEquality, []string{"lt", "lte", "eq", "gte", "gt", "ne", "in", "between"})
-	parser.DefineTokens(TLogicalOperation, []string{"and", "or"})
+	// word operators, logical operations and macros are lexed as keywords and classified
+	// afterwards, defining them as tokens would split columns such as `age` or `order_id`
+	parser.DefineTokens(TEquality, symbolicOperators())
 	parser.DefineTokens(TParenOpen, []string{"("})
 	parser.DefineTokens(TParenClose, []string{")"})
 	parser.DefineStringToken(TDoubleQuoted, `"`, `"`).SetEscapeSymbol(tokenizer.BackSlash)
 	parser.DefineStringToken(TDoubleQuoted, `'`, `'`).SetEscapeSymbol(tokenizer.BackSlash)
 	parser.DefineStringToken(TArray, `[`, `]`).SetEscapeSymbol(tokenizer.BackSlash)
-	parser.DefineTokens(TMacro, macros.Supported)
+	parser.DefineTokens(TMinus, []string{"-"})
 
 	parser.AllowKeywordSymbols(tokenizer.Underscore, tokenizer.Numbers)
+	return parser
+}
 
-	// Create tokens' stream
-	stream := parser.ParseString(filter)
-	defer stream.Close()
+// filterParser is a recursive descent parser over the tokens of a single filter.
+//
+//	or        = and { "or" and }
+//	and       = factor { "and" factor }
+//	factor    = "(" or ")" | tuple | "asof" "(" value ")" | "include" "(" name { "," value } ")" | condition
+//	tuple     = "(" column { "," column } ")" operation array
+//	condition = column operation value { operation value }
+type filterParser struct {
+	*Parser
+	ctx         context.Context
+	stream      *tokenizer.Stream
+	validateCol func(col string) bool
+	asOfSeen    bool
+	// params are the include arguments while parsing a saved view, nil otherwise
+	params map[string]any
+	budget *memoryBudget
+	// conditions counts the conditions of the filter, views included
+	conditions *conditionCounter
+	// depth is the number of parentheses the parser is in
+	depth int
+	// truncated is set once LenientTokens dropped an incomplete end of the filter
+	truncated bool
+}
+
+func (fp *filterParser) parseOr() (Expr, error) {
+	return fp.parseLogical("or", fp.parseAnd)
+}
 
-	// Stack to track nested parentheses
-	var parenStack []int
+func (fp *filterParser) parseAnd() (Expr, error) {
+	return fp.parseLogical("and", fp.parseFactor)
+}
 
-	// Helper function to ensure spaces around content
-	writeWithSpaces := func(content string) {
-		if sb.Len() > 0 {
-			sb.WriteString(" ")
+// parseLogical parses a chain of operands joined by the given logical operation
+func (fp *filterParser) parseLogical(operator string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	exprs := []Expr{first}
+
+	for !fp.truncated && fp.isLogicalOperation(fp.stream.CurrentToken()) && fp.canonical(fp.stream.CurrentToken().ValueString()) == operator {
+		tok := fp.stream.CurrentToken()
+		if !fp.stream.GoNext().IsValid() {
+			if fp.tokenMode == LenientTokens {
+				fp.truncated = true
+				break
+			}
+			return nil, &LogicalTokenError{Reason: "cannot end with a logical operation", Line: tok.Line(), Pos: tok.Offset()}
 		}
-		sb.WriteString(content)
-		sb.WriteString(" ")
+		next, err := operand()
+		if fp.truncates(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, next)
 	}
 
-	// Iterate over each token
-	for stream.IsValid() {
-		line, column := stream.CurrentToken().Line(), stream.CurrentToken().Offset()
-		tokenValue := stream.CurrentToken().ValueString()
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	if operator == "or" && slices.ContainsFunc(exprs, containsAsOf) {
+		return nil, UnexpectedTokenError{Token: asOfKeyword + " can only be combined with and", Line: 0, Pos: 0}
+	}
+	return &Logical{Operator: operator, Exprs: exprs}, nil
+}
 
-		switch {
-		case stream.CurrentToken().Is(tokenizer.TokenKeyword):
-			col := tokenValue
-			macroType := ""
-			quotesNeeded := 1
-			currentVals := []any{}
+func (fp *filterParser) parseFactor() (Expr, error) {
+	stream := fp.stream
+	tok := stream.CurrentToken()
+	line, column := tok.Line(), tok.Offset()
 
-			if !validateCol(col) {
-				return ParsedQuery{}, InvalidColumnError{Column: col, Line: line, Pos: column}
-			}
+	switch {
+	case fp.isLogicalOperation(tok):
+		if fp.isLogicalOperation(stream.PrevToken()) {
+			return nil, &LogicalTokenError{Reason: "before or after a logical operation, you must have an expression or nested expression", Line: line, Pos: column}
+		}
+		return nil, &LogicalTokenError{Reason: "cannot start with a logical operation", Line: line, Pos: column}
 
-			if !stream.GoNextIfNextIs(TEquality) {
-				return ParsedQuery{}, UnexpectedTokenError{Token: "equality operation", Line: line, Pos: column + len(col)}
+	case tok.Is(TParenOpen):
+		if !stream.NextToken().Is(tokenizer.TokenKeyword, TParenOpen) || fp.isLogicalOperation(stream.NextToken()) {
+			return nil, UnexpectedTokenError{Token: "expression", Line: line, Pos: column}
+		}
+		stream.GoNext()
+		if stream.NextToken().ValueString() == "," {
+			return fp.parseTuple(line, column)
+		}
+		fp.depth++
+		if err := fp.checkDepth(fp.depth, line, column); err != nil {
+			return nil, err
+		}
+		expr, err := fp.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		fp.depth--
+		if !stream.CurrentToken().Is(TParenClose) {
+			// a group left open by a truncated or unterminated filter closes with it
+			if fp.tokenMode == LenientTokens && (fp.truncated || !stream.IsValid()) {
+				fp.truncated = true
+				return expr, nil
 			}
-
-			opValue := stream.CurrentToken().ValueString()
-			op, foundOp := operationsMapped[opValue]
-			if !foundOp {
-				return ParsedQuery{}, InvalidOperationError{Operation: opValue, Column: col, Line: line, Pos: column + len(col)}
+			if stream.IsValid() {
+				return nil, UnexpectedTokenError{Token: stream.CurrentToken().ValueString(), Line: stream.CurrentToken().Line(), Pos: stream.CurrentToken().Offset()}
 			}
+			return nil, UnmatchedParenthesisError{Type: "opening", Line: line, Pos: column}
+		}
+		stream.GoNext()
+		return expr, nil
 
-			if !stream.GoNextIfNextIs(tokenizer.TokenFloat, tokenizer.TokenInteger, tokenizer.TokenString, TMacro) {
-				return ParsedQuery{}, MissingValueError{Column: col, Line: line, Pos: column + len(col) + len(opValue)}
-			}
+	case tok.IsKeyword() && tok.ValueString() == asOfKeyword && stream.NextToken().Is(TParenOpen):
+		return fp.parseAsOf()
 
-			// parse macro + precheck
-			if stream.CurrentToken().Is(TMacro) {
-				macroType = stream.CurrentToken().ValueString()
-				spew.Dump(stream.NextToken().ValueString())
-				if !stream.GoNextIfNextIs(TParenOpen) {
-					return ParsedQuery{}, UnexpectedTokenError{Token: "Macro expressions must have opening parenthesis and closing ones", Line: line, Pos: column}
-				}
-				stream.GoNext()
-				if !stream.NextToken().Is(TParenClose) {
-					return ParsedQuery{}, UnexpectedTokenError{Token: "Macro expressions must have opening parenthesis and closing ones", Line: line, Pos: column}
-				}
-			}
+	case tok.IsKeyword() && tok.ValueString() == includeKeyword && stream.NextToken().Is(TParenOpen):
+		return fp.parseInclude()
 
-			// value parsing logic remains the same
-			switch {
-			case stream.CurrentToken().IsFloat():
-				currentVals = append(currentVals, stream.CurrentToken().ValueFloat64())
-			case stream.CurrentToken().IsInteger():
-				currentVals = append(currentVals, stream.CurrentToken().ValueInt64())
-			case stream.CurrentToken().IsString():
-				if stream.CurrentToken().StringKey() == TArray {
-					if !op.IsMultiValue {
-						return ParsedQuery{}, InvalidOperationError{Operation: "multi-value array", Column: col, Line: line, Pos: column}
-					}
-
-					var value []interface{}
-					err := json.Unmarshal([]byte(stream.CurrentToken().ValueString()), &value)
-					if err != nil {
-						return ParsedQuery{}, UnexpectedTokenError{Token: "invalid array argument", Line: line, Pos: column}
-					}
-					quotesNeeded = len(value)
-					if len(value) == 0 {
-						return ParsedQuery{}, InvalidOperationError{Operation: "multi-value array empty arguments", Column: col, Line: line, Pos: column}
-					}
-					currentVals = append(currentVals, value...)
-				} else {
-					strVal := stream.CurrentToken().ValueString()
-					currentVals = append(currentVals, strVal[1:len(strVal)-1]) // Strip quotes
-				}
-			}
+	case tok.IsKeyword() && tok.ValueString() == notKeyword && stream.NextToken().Is(TParenOpen):
+		stream.GoNext()
+		expr, err := fp.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		if containsAsOf(expr) {
+			return nil, UnexpectedTokenError{Token: asOfKeyword + " inside " + notKeyword, Line: line, Pos: column}
+		}
+		return &Not{Expr: expr}, nil
 
-			// run macro transformation after we have a value
-			if macroType != "" {
-				h, ok := macros.Handlers[macroType]
-				if !ok {
-					return ParsedQuery{}, macros.MacroNotImplemented{Column: col, MacroName: macroType}
-				}
-				transformedArgs, err := h.RunMacro(col, currentVals...)
-				if err != nil {
-					return ParsedQuery{}, err
-				}
-				currentVals = transformedArgs
-				stream.GoNext().GoNext() // we did a check before so we good
-			}
+	case tok.Is(tokenizer.TokenKeyword):
+		return fp.parseCondition()
+
+	case tok.Is(TParenClose):
+		return nil, UnmatchedParenthesisError{Type: "closing", Line: line, Pos: column}
+
+	case !stream.IsValid():
+		return nil, UnexpectedTokenError{Token: "expression", Line: line, Pos: column}
+
+	default:
+		return nil, UnexpectedTokenError{Token: tok.ValueString(), Line: line, Pos: column}
+	}
+}
+
+// parseCondition parses `column operation value [operation value ...]` and leaves the stream after the last value
+func (fp *filterParser) parseCondition() (Expr, error) {
+	if err := fp.ctx.Err(); err != nil {
+		return nil, err
+	}
+	stream := fp.stream
+	line, column := stream.CurrentToken().Line(), stream.CurrentToken().Offset()
+	col := stream.CurrentToken().ValueString()
+
+	if !fp.validateCol(col) {
+		return nil, fp.columnError(col, line, column)
+	}
 
-			writeWithSpaces(fmt.Sprintf("%s %s", col, op.Value(quotesNeeded)))
-			vals = append(vals, currentVals...)
-		case stream.CurrentToken().Is(TLogicalOperation):
-			if stream.PrevToken().Is(TLogicalOperation) || stream.NextToken().Is(TLogicalOperation) {
-				return ParsedQuery{}, &LogicalTokenError{Reason: "before or after a logical operation, you must have an expression or nested expression"}
-			} else if stream.CurrentToken().Offset() == 0 {
-				return ParsedQuery{}, &LogicalTokenError{Reason: "cannot start with a logical operation"}
+	if !stream.GoNextIfNextIs(TEquality, tokenizer.TokenKeyword) {
+		return nil, UnexpectedTokenError{Token: "equality operation", Line: line, Pos: column + len(col)}
+	}
+
+	// `age gte 18 lte 65` chains comparisons on the same column, it is short for
+	// `age gte 18 and age lte 65`
+	exprs := make([]Expr, 0, 1)
+	for {
+		cond, err := fp.parseComparison(col, line, column)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, cond)
+		if !fp.isOperation(stream.CurrentToken()) {
+			break
+		}
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &Logical{Operator: "and", Exprs: exprs}, nil
+}
+
+// parseComparison parses `operation value` for the column, the stream must be on the operation.
+// It leaves the stream on the token following the value.
+func (fp *filterParser) parseComparison(col string, line, column int) (*Condition, error) {
+	stream := fp.stream
+	opValue := stream.CurrentToken().ValueString()
+	opName, op, err := fp.operation(col, opValue, fp.canonical(opValue), line, column)
+	if err != nil {
+		return nil, err
+	}
+
+	cond := &Condition{Column: col, Operator: opName, Line: line, Pos: column}
+
+	// `col eq null` / `col ne null` compile to IS NULL checks with no bound value
+	if stream.NextToken().IsKeyword() && stream.NextToken().ValueString() == nullKeyword {
+		if !fp.allowsNull(col, op) {
+			return nil, InvalidOperationError{Operation: opValue + " " + nullKeyword, Column: col, Line: line, Pos: column + len(col)}
+		}
+		stream.GoNext().GoNext()
+		cond.Values = []any{nil}
+		if err := countCondition(fp.budget, fp.conditions, cond.Values, line, column); err != nil {
+			return nil, err
+		}
+		return cond, nil
+	}
+
+	macroType := ""
+	if isMacro(stream.NextToken()) {
+		if fp.noMacros {
+			return nil, MacrosDisabledError{Macro: stream.NextToken().ValueString(), Column: col, Line: line, Pos: column}
+		}
+		// parse macro + precheck
+		macroType = stream.GoNext().CurrentToken().ValueString()
+		if !stream.GoNextIfNextIs(TParenOpen) {
+			return nil, UnexpectedTokenError{Token: "Macro expressions must have opening parenthesis and closing ones", Line: line, Pos: column}
+		}
+		debugDump(stream.NextToken().ValueString())
+	}
+
+	vals, err := fp.parseValue(col, opValue, op)
+	if err != nil {
+		return nil, err
+	}
+	if vals, err = fp.resolveValues(col, opValue, opName, op, vals, macroType != "", line, column); err != nil {
+		return nil, err
+	}
+
+	// run macro transformation after we have a value
+	if macroType != "" {
+		if !stream.NextToken().Is(TParenClose) {
+			return nil, UnexpectedTokenError{Token: "Macro expressions must have opening parenthesis and closing ones", Line: line, Pos: column}
+		}
+		stream.GoNext() // we did a check before so we good, land on the closing parenthesis
+
+		h, ok := macros.Handlers[macroType]
+		if !ok {
+			return nil, macros.MacroNotImplemented{Column: col, MacroName: macroType}
+		}
+		vals, err = fp.runMacro(fp.ctx, macroType, h, col, vals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cond.Values = fp.sanitizeValues(opName, op, vals)
+	if err := countCondition(fp.budget, fp.conditions, cond.Values, line, column); err != nil {
+		return nil, err
+	}
+	stream.GoNext()
+	return cond, nil
+}
+
+// operation resolves the operation named opValue (opName once canonical) applied to the column,
+// checking the dialect, capabilities and schema allow it
+func (p *Parser) operation(col, opValue, opName string, line, column int) (string, OperationMeta, error) {
+	op, foundOp := operationsMapped[opName]
+	if !foundOp || !p.dialect.supports(opName) || !p.hasCapability(op.Requires) {
+		return "", OperationMeta{}, InvalidOperationError{Operation: opValue, Column: col, Line: line, Pos: column + len(col)}
+	}
+	if _, folded := p.folded[col]; folded && !p.dialect.folds() {
+		return "", OperationMeta{}, UnsupportedOperationError{Backend: p.dialect.Name, Operation: opValue, Column: col, Line: line, Pos: column + len(col)}
+	}
+	if p.schema != nil && !p.schema.restricts(col, opName) {
+		return "", OperationMeta{}, OperatorNotAllowedError{Operation: opValue, Column: col, Allowed: p.schema[col].Operators, Line: line, Pos: column + len(col)}
+	}
+	if p.schema != nil && !p.schema.capable(col, opName) {
+		return "", OperationMeta{}, InvalidOperationError{Operation: opValue, Column: col, Line: line, Pos: column + len(col)}
+	}
+	if opName == "between" && p.exclusiveBetween {
+		opName, op = "from_until", operationsMapped["from_until"]
+	}
+	return opName, op, nil
+}
+
+// resolveValues checks the decoded values against the operation and resolves relative times,
+// declared types and time zones. Integer checks are skipped for macros, they produce the value themselves.
+func (p *Parser) resolveValues(col, opValue, opName string, op OperationMeta, vals []any, macro bool, line, column int) ([]any, error) {
+	var err error
+	if op.MultiValueLimit > 0 && len(vals) != op.MultiValueLimit {
+		return nil, ValueCountError{Operation: opValue, Column: col, Expected: op.MultiValueLimit, Got: len(vals), Line: line, Pos: column}
+	}
+	if op.IsMultiValue && p.maxListSize > 0 && len(vals) > p.maxListSize {
+		return nil, ListSizeError{Column: col, Limit: p.maxListSize, Got: len(vals), Line: line, Pos: column}
+	}
+	if op.Validate != nil {
+		for _, v := range vals {
+			if err := op.Validate(v); err != nil {
+				return nil, InvalidValueError{Column: col, Operation: opValue, Reason: err.Error(), Line: line, Pos: column}
 			}
-			if !stream.GoNext().IsValid() {
-				return ParsedQuery{}, &LogicalTokenError{Reason: "cannot end with a logical operation"}
+		}
+	}
+	if op.Normalize != nil {
+		if vals, err = op.Normalize(vals); err != nil {
+			return nil, InvalidValueError{Column: col, Operation: opValue, Reason: err.Error(), Line: line, Pos: column}
+		}
+	}
+	if op.IntegerOnly && !macro {
+		for _, v := range vals {
+			if _, ok := v.(int64); !ok {
+				return nil, InvalidOperationError{Operation: opValue + " on a non integer value", Column: col, Line: line, Pos: column}
 			}
-			writeWithSpaces(tokenValue)
-			continue
+		}
+	}
 
-		case tokenValue == "(":
-			if !stream.NextToken().Is(tokenizer.TokenKeyword) {
-				return ParsedQuery{}, UnexpectedTokenError{Token: "expression", Line: line, Pos: column}
+	// resolve relative time literals (`"now-7d"`) to concrete timestamps
+	if p.resolvesRelativeTimes(col, opName) {
+		for i, v := range vals {
+			if str, ok := v.(string); ok {
+				if t, isRelative := p.relativeTime(str); isRelative {
+					vals[i] = t
+				}
 			}
-			writeWithSpaces("(" + "")
-			parenStack = append(parenStack, len(vals)) // Track nested position
+		}
+	}
+	if vals, err = p.coerceValues(col, opValue, opName, vals, line, column); err != nil {
+		return nil, err
+	}
+	if err := p.validateValues(col, opName, vals, line, column); err != nil {
+		return nil, err
+	}
 
-		case tokenValue == ")":
-			if len(parenStack) == 0 {
-				return ParsedQuery{}, UnmatchedParenthesisError{Type: "closing", Line: line, Pos: column}
-			}
-			parenStack = parenStack[:len(parenStack)-1] // Pop from stack
-			writeWithSpaces(") ")
+	return p.toStorageZone(col, vals), nil
+}
 
-		default:
-			return ParsedQuery{}, UnexpectedTokenError{Token: tokenValue, Line: line, Pos: column}
+// sanitizeValues runs the sanitizer of the operation over the values
+func (p *Parser) sanitizeValues(opName string, op OperationMeta, vals []any) []any {
+	if sanitize := p.sanitizer(opName, op); sanitize != nil {
+		for i, v := range vals {
+			vals[i] = sanitize(v)
 		}
+	}
+	return vals
+}
 
+// parseValue moves the stream onto the value literal following the current token and decodes it
+func (fp *filterParser) parseValue(col, opValue string, op OperationMeta) ([]any, error) {
+	stream := fp.stream
+	line, column := stream.CurrentToken().Line(), stream.CurrentToken().Offset()
+	missing := MissingValueError{Column: col, Line: line, Pos: column + len(opValue)}
+
+	// `:name` parameters of a saved view are replaced by the include arguments
+	if fp.params != nil && stream.NextToken().ValueString() == ":" {
 		stream.GoNext()
+		if !stream.GoNextIfNextIs(tokenizer.TokenKeyword) {
+			return nil, missing
+		}
+		v, ok := fp.params[stream.CurrentToken().ValueString()]
+		if !ok {
+			return nil, UnexpectedTokenError{Token: "undeclared view parameter :" + stream.CurrentToken().ValueString(), Line: line, Pos: column}
+		}
+		return []any{v}, nil
+	}
+
+	// unquoted single words are string literals when enabled, `status eq active`
+	if fp.bareWords && stream.NextToken().IsKeyword() && !fp.isLogicalOperation(stream.NextToken()) {
+		return []any{stream.GoNext().CurrentToken().ValueString()}, nil
+	}
+
+	if stream.NextToken().IsString() && stream.NextToken().StringKey() == TArray {
+		tok := stream.GoNext().CurrentToken()
+		if !op.IsMultiValue {
+			return nil, InvalidOperationError{Operation: "multi-value array", Column: col, Line: line, Pos: column}
+		}
+
+		var value []interface{}
+		err := json.Unmarshal([]byte(tok.ValueString()), &value)
+		if err != nil {
+			return nil, UnexpectedTokenError{Token: "invalid array argument", Line: line, Pos: column}
+		}
+		if len(value) == 0 {
+			return nil, InvalidOperationError{Operation: "multi-value array empty arguments", Column: col, Line: line, Pos: column}
+		}
+		return value, nil
+	}
+
+	v, ok := fp.parseScalar()
+	if !ok {
+		return nil, missing
+	}
+	return []any{v}, nil
+}
+
+// parseScalar moves the stream onto the number or quoted string following the current
+// token and decodes it, the second return is false when there is no such literal
+func (fp *filterParser) parseScalar() (any, bool) {
+	stream := fp.stream
+
+	// a minus sign directly attached to a number makes it negative (`-100`, `-1.5e3`)
+	negative := false
+	if stream.GoNextIfNextIs(TMinus) {
+		if !stream.NextToken().IsNumber() || len(stream.NextToken().Indent()) > 0 {
+			return nil, false
+		}
+		negative = true
+	}
+
+	if !stream.GoNextIfNextIs(tokenizer.TokenFloat, tokenizer.TokenInteger, tokenizer.TokenString) {
+		return nil, false
+	}
+
+	tok := stream.CurrentToken()
+	switch {
+	case tok.IsFloat():
+		v := tok.ValueFloat64()
+		if negative {
+			v = -v
+		}
+		return v, true
+	case tok.IsInteger():
+		v := tok.ValueInt64()
+		if negative {
+			v = -v
+		}
+		return v, true
+	case tok.StringKey() == TArray:
+		return nil, false
+	default:
+		strVal := tok.ValueString()
+		return strVal[1 : len(strVal)-1], true // Strip quotes
+	}
+}
+
+// symbolicAliases maps symbolic operators onto the operation they stand for
+var symbolicAliases = map[string]string{
+	"=":  "eq",
+	"!=": "ne",
+	"<>": "ne",
+	">":  "gt",
+	">=": "gte",
+	"<":  "lt",
+	"<=": "lte",
+}
+
+// symbolicOperators lists the symbolic operators for the tokenizer
+func symbolicOperators() []string {
+	ops := make([]string, 0, len(symbolicAliases))
+	for sym := range symbolicAliases {
+		ops = append(ops, sym)
+	}
+	return ops
+}
+
+// canonical resolves symbolic and registered keyword aliases to the canonical operator
+func (p *Parser) canonical(op string) string {
+	if name, ok := p.aliases[op]; ok {
+		return name
+	}
+	if name, ok := symbolicAliases[op]; ok {
+		return name
+	}
+	return op
+}
+
+// isLogicalOperation reports whether the token is an `and` / `or` keyword or one of their aliases
+func (p *Parser) isLogicalOperation(tok *tokenizer.Token) bool {
+	if !tok.IsKeyword() {
+		return false
 	}
+	v := p.canonical(tok.ValueString())
+	return v == "and" || v == "or"
+}
 
-	// If the stack is not empty, we have unclosed parentheses
-	if len(parenStack) > 0 {
-		return ParsedQuery{}, UnmatchedParenthesisError{Type: "opening", Line: 0, Pos: 0}
+// isOperation reports whether the token is a comparison operation or one of its aliases
+func (p *Parser) isOperation(tok *tokenizer.Token) bool {
+	if tok.Is(TEquality) {
+		return true
 	}
+	if !tok.IsKeyword() || p.isLogicalOperation(tok) {
+		return false
+	}
+	_, ok := operationsMapped[p.canonical(tok.ValueString())]
+	return ok
+}
 
-	return ParsedQuery{SQL: strings.TrimSpace(sb.String()), Args: vals}, nil
+// isMacro reports whether the token is the name of a supported macro
+func isMacro(tok *tokenizer.Token) bool {
+	return tok.IsKeyword() && slices.Contains(macros.Supported, tok.ValueString())
+}
+
+// sanitizer returns the parser override for the operation or its default one
+func (p *Parser) sanitizer(opName string, op OperationMeta) Sanitizer {
+	if s, ok := p.sanitizers[opName]; ok {
+		return s
+	}
+	return op.Sanitize
 }
@@ -2,12 +2,17 @@ package rqe
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Custom error types
 type ParseError interface {
 	Error() string
 	Position() (int, int) // Returns line and column position
+	// Pretty renders the error with the offending line of the filter, a caret under the
+	// position and a short hint, e.g. for API error responses and CLI output
+	Pretty(filter string) string
 }
 
 // InvalidColumnError represents an error when an invalid column is used
@@ -25,6 +30,29 @@ func (e InvalidColumnError) Position() (int, int) {
 	return e.Line, e.Pos
 }
 
+func (e InvalidColumnError) Pretty(filter string) string {
+	return prettyError(e, filter, "the column does not exist or cannot be filtered on")
+}
+
+// DeniedColumnError represents an error when the filter uses a column of the deny-list, see WithDeniedColumns
+type DeniedColumnError struct {
+	Column string
+	Line   int
+	Pos    int
+}
+
+func (e DeniedColumnError) Error() string {
+	return fmt.Sprintf("column '%s' cannot be filtered on at line %d, offset %d", e.Column, e.Line, e.Pos)
+}
+
+func (e DeniedColumnError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e DeniedColumnError) Pretty(filter string) string {
+	return prettyError(e, filter, "the column is restricted")
+}
+
 // UnexpectedTokenError represents an error when an unexpected token appears
 type UnexpectedTokenError struct {
 	Token string
@@ -36,6 +64,14 @@ func (e UnexpectedTokenError) Error() string {
 	return fmt.Sprintf("unexpected token '%s' at line %d, offset %d", e.Token, e.Line, e.Pos)
 }
 
+func (e UnexpectedTokenError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e UnexpectedTokenError) Pretty(filter string) string {
+	return prettyError(e, filter, "filters look like `column operation value`, e.g. `name eq \"john\"`")
+}
+
 // UnexpectedTokenError represents an error when an unexpected token appears
 type LogicalTokenError struct {
 	Reason string
@@ -51,6 +87,10 @@ func (e LogicalTokenError) Position() (int, int) {
 	return e.Line, e.Pos
 }
 
+func (e LogicalTokenError) Pretty(filter string) string {
+	return prettyError(e, filter, "`and` / `or` must sit between two expressions")
+}
+
 // MissingValueError represents an error when a value is missing after an operation
 type MissingValueError struct {
 	Column string
@@ -66,6 +106,10 @@ func (e MissingValueError) Position() (int, int) {
 	return e.Line, e.Pos
 }
 
+func (e MissingValueError) Pretty(filter string) string {
+	return prettyError(e, filter, "add a value after the operation, strings must be quoted")
+}
+
 // InvalidOperationError represents an error when an invalid operation is used
 type InvalidOperationError struct {
 	Operation string
@@ -82,6 +126,32 @@ func (e InvalidOperationError) Position() (int, int) {
 	return e.Line, e.Pos
 }
 
+func (e InvalidOperationError) Pretty(filter string) string {
+	return prettyError(e, filter, "the operation cannot be used here or with this value")
+}
+
+// OperatorNotAllowedError represents an error when the schema restricts the operations of a
+// column (Column.Operators) and the filter uses another one
+type OperatorNotAllowedError struct {
+	Operation string
+	Column    string
+	Allowed   []string
+	Line      int
+	Pos       int
+}
+
+func (e OperatorNotAllowedError) Error() string {
+	return fmt.Sprintf("operation '%s' is not allowed on column '%s', allowed are [%s] at line %d, offset %d", e.Operation, e.Column, strings.Join(e.Allowed, ", "), e.Line, e.Pos)
+}
+
+func (e OperatorNotAllowedError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e OperatorNotAllowedError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("use one of %s", strings.Join(e.Allowed, ", ")))
+}
+
 // UnmatchedParenthesisError represents an error for unmatched parentheses
 type UnmatchedParenthesisError struct {
 	Type string // "opening" or "closing"
@@ -96,3 +166,393 @@ func (e UnmatchedParenthesisError) Error() string {
 func (e UnmatchedParenthesisError) Position() (int, int) {
 	return e.Line, e.Pos
 }
+
+func (e UnmatchedParenthesisError) Pretty(filter string) string {
+	return prettyError(e, filter, "every `(` needs a matching `)`")
+}
+
+// SortColumnError represents an error when a sort column is not allowed
+type SortColumnError struct {
+	Column string
+	Reason string
+}
+
+func (e SortColumnError) Error() string {
+	return fmt.Sprintf("cannot sort on column '%s' : [%s]", e.Column, e.Reason)
+}
+
+// RegistrationError represents an error when a dialect, operator or macro cannot be registered
+type RegistrationError struct {
+	Kind   string
+	Name   string
+	Reason string
+}
+
+func (e RegistrationError) Error() string {
+	return fmt.Sprintf("cannot register %s '%s' : [%s]", e.Kind, e.Name, e.Reason)
+}
+
+// UnsafeSQLError represents an error when hardened mode finds unexpected content in the compiled SQL
+type UnsafeSQLError struct {
+	SQL    string
+	Reason string
+}
+
+func (e UnsafeSQLError) Error() string {
+	return fmt.Sprintf("refusing unsafe SQL : [%s]", e.Reason)
+}
+
+// InvalidSchemaError represents an error when a struct cannot be turned into a schema
+type InvalidSchemaError struct {
+	Field  string
+	Reason string
+}
+
+func (e InvalidSchemaError) Error() string {
+	return fmt.Sprintf("invalid schema field '%s' : [%s]", e.Field, e.Reason)
+}
+
+// FieldColumnError represents an error when a projected column is not allowed
+type FieldColumnError struct {
+	Column string
+	Reason string
+}
+
+func (e FieldColumnError) Error() string {
+	return fmt.Sprintf("cannot select column '%s' : [%s]", e.Column, e.Reason)
+}
+
+// InvalidIdentifierError represents an error when a table or column name cannot be used as an identifier
+type InvalidIdentifierError struct {
+	Name   string
+	Reason string
+}
+
+func (e InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("invalid identifier '%s' : [%s]", e.Name, e.Reason)
+}
+
+// MemoryBudgetError represents an error when parsing a filter would use more memory than allowed
+type MemoryBudgetError struct {
+	Limit int
+	Used  int
+}
+
+func (e MemoryBudgetError) Error() string {
+	return fmt.Sprintf("filter needs about %d bytes, over the budget of %d bytes", e.Used, e.Limit)
+}
+
+// DepthLimitExceededError represents an error when groups nest deeper than WithMaxDepth allows
+type DepthLimitExceededError struct {
+	Limit int
+	Line  int
+	Pos   int
+}
+
+func (e DepthLimitExceededError) Error() string {
+	return fmt.Sprintf("filter nests deeper than %d levels at line %d, offset %d", e.Limit, e.Line, e.Pos)
+}
+
+func (e DepthLimitExceededError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e DepthLimitExceededError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("flatten the filter, at most %d nested groups are allowed", e.Limit))
+}
+
+// ConditionLimitError represents an error when a filter has more conditions than WithMaxConditions allows
+type ConditionLimitError struct {
+	Limit int
+	Line  int
+	Pos   int
+}
+
+func (e ConditionLimitError) Error() string {
+	return fmt.Sprintf("filter has more than %d conditions at line %d, offset %d", e.Limit, e.Line, e.Pos)
+}
+
+func (e ConditionLimitError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e ConditionLimitError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("at most %d conditions are allowed", e.Limit))
+}
+
+// ListSizeError represents an error when an array has more values than WithMaxListSize allows
+type ListSizeError struct {
+	Column string
+	Limit  int
+	Got    int
+	Line   int
+	Pos    int
+}
+
+func (e ListSizeError) Error() string {
+	return fmt.Sprintf("column '%s' takes at most %d values but got %d at line %d, offset %d", e.Column, e.Limit, e.Got, e.Line, e.Pos)
+}
+
+func (e ListSizeError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e ListSizeError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("split the list, at most %d values are allowed", e.Limit))
+}
+
+// InputTooLongError represents an error when a filter is longer than WithMaxLength allows
+type InputTooLongError struct {
+	Limit  int
+	Length int
+}
+
+func (e InputTooLongError) Error() string {
+	return fmt.Sprintf("filter is %d bytes long, over the limit of %d bytes", e.Length, e.Limit)
+}
+
+// MissingScopeError represents an error when the value a scope restricts the column to is missing, see ScopeFromContext
+type MissingScopeError struct {
+	Column string
+}
+
+func (e MissingScopeError) Error() string {
+	return fmt.Sprintf("no value to scope column '%s' to", e.Column)
+}
+
+// InvalidEnumValueError represents an error when a value is not one of the column's Enum values
+type InvalidEnumValueError struct {
+	Column  string
+	Value   string
+	Allowed []string
+	Line    int
+	Pos     int
+}
+
+func (e InvalidEnumValueError) Error() string {
+	return fmt.Sprintf("value '%s' of column '%s' must be one of [%s] at line %d, offset %d", e.Value, e.Column, strings.Join(e.Allowed, ", "), e.Line, e.Pos)
+}
+
+func (e InvalidEnumValueError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e InvalidEnumValueError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("use one of %s", strings.Join(e.Allowed, ", ")))
+}
+
+// ValidationError represents an error when a value is rejected by one of the column's Validators
+type ValidationError struct {
+	Column string
+	Value  any
+	Reason string
+	Line   int
+	Pos    int
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("value %v of column '%s' is invalid : [%s] at line %d, offset %d", e.Value, e.Column, e.Reason, e.Line, e.Pos)
+}
+
+func (e ValidationError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e ValidationError) Pretty(filter string) string {
+	return prettyError(e, filter, e.Reason)
+}
+
+// MacrosDisabledError represents an error when a filter uses a macro while they are disabled, see WithoutMacros
+type MacrosDisabledError struct {
+	Macro  string
+	Column string
+	Line   int
+	Pos    int
+}
+
+func (e MacrosDisabledError) Error() string {
+	return fmt.Sprintf("macro '%s' used on column '%s' is disabled at line %d, offset %d", e.Macro, e.Column, e.Line, e.Pos)
+}
+
+func (e MacrosDisabledError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e MacrosDisabledError) Pretty(filter string) string {
+	return prettyError(e, filter, "macros are disabled, compare against a plain value")
+}
+
+// UnknownFragmentError represents an error when a fragment was not registered with WithFragment
+type UnknownFragmentError struct {
+	Name string
+}
+
+func (e UnknownFragmentError) Error() string {
+	return fmt.Sprintf("unknown fragment '%s'", e.Name)
+}
+
+// InvalidPaginationError represents an error when the limit or offset are out of range
+type InvalidPaginationError struct {
+	Limit  int
+	Offset int
+}
+
+func (e InvalidPaginationError) Error() string {
+	return fmt.Sprintf("invalid pagination limit %d offset %d", e.Limit, e.Offset)
+}
+
+// ValueCountError represents an error when an operation receives the wrong amount of values
+type ValueCountError struct {
+	Operation string
+	Column    string
+	Expected  int
+	Got       int
+	Line      int
+	Pos       int
+}
+
+func (e ValueCountError) Error() string {
+	return fmt.Sprintf("operation '%s' for column '%s' expects %d values but got %d at line %d, offset %d", e.Operation, e.Column, e.Expected, e.Got, e.Line, e.Pos)
+}
+
+func (e ValueCountError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e ValueCountError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("the operation takes exactly %d values", e.Expected))
+}
+
+// InvalidViewError represents an error when a saved view is misdeclared or misused
+type InvalidViewError struct {
+	View   string
+	Reason string
+	Line   int
+	Pos    int
+}
+
+func (e InvalidViewError) Error() string {
+	return fmt.Sprintf("invalid use of view '%s' : [%s] at line %d, offset %d", e.View, e.Reason, e.Line, e.Pos)
+}
+
+func (e InvalidViewError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e InvalidViewError) Pretty(filter string) string {
+	return prettyError(e, filter, "check the view name and the arguments it takes")
+}
+
+// RuleViolationError represents a filter breaking one or more of the parser rules
+type RuleViolationError struct {
+	Violations []Violation
+}
+
+func (e RuleViolationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return fmt.Sprintf("filter breaks %d rule(s) : [%s]", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// InvalidValueError represents an error when a literal is not acceptable for the column or operation
+type InvalidValueError struct {
+	Column    string
+	Operation string
+	Reason    string
+	Line      int
+	Pos       int
+}
+
+func (e InvalidValueError) Error() string {
+	return fmt.Sprintf("invalid value for column '%s' with operation '%s' : [%s] at line %d, offset %d", e.Column, e.Operation, e.Reason, e.Line, e.Pos)
+}
+
+func (e InvalidValueError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e InvalidValueError) Pretty(filter string) string {
+	return prettyError(e, filter, "the value is not in the expected format")
+}
+
+// FederationError represents an error when a filter cannot be planned across backends
+type FederationError struct {
+	Backend string
+	Reason  string
+}
+
+func (e FederationError) Error() string {
+	return fmt.Sprintf("cannot plan backend '%s' : %s", e.Backend, e.Reason)
+}
+
+// NormalFormLimitError represents an error when a normal form would have too many clauses
+type NormalFormLimitError struct {
+	Form  string
+	Limit int
+}
+
+func (e NormalFormLimitError) Error() string {
+	return fmt.Sprintf("filter exceeds %d clauses when converted to %s", e.Limit, strings.ToUpper(e.Form))
+}
+
+// RateLimitError represents an error when a caller exceeded its parse rate
+type RateLimitError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for '%s', retry after %s", e.Key, e.RetryAfter)
+}
+
+// prettyError renders err followed by the line of the filter it points at, a caret under the
+// position and the hint. Positions are byte offsets from the start of the filter.
+func prettyError(err ParseError, filter, hint string) string {
+	line, pos := err.Position()
+	pos = max(0, min(pos, len(filter)))
+	start := strings.LastIndex(filter[:pos], "\n") + 1
+	end := strings.IndexByte(filter[pos:], '\n')
+	if end == -1 {
+		end = len(filter)
+	} else {
+		end += pos
+	}
+
+	gutter := fmt.Sprintf(" %d | ", line)
+	var sb strings.Builder
+	sb.WriteString(err.Error() + "\n")
+	sb.WriteString(gutter + filter[start:end] + "\n")
+	// keep tabs so the caret lines up with the source
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, filter[start:pos])
+	sb.WriteString(strings.Repeat(" ", len(gutter)-2) + "| " + indent + "^\n")
+	sb.WriteString("hint: " + hint)
+	return sb.String()
+}
+
+// UnsupportedOperationError represents an error when a backend cannot express part of a filter
+type UnsupportedOperationError struct {
+	Backend   string
+	Operation string
+	Column    string
+	Line      int
+	Pos       int
+}
+
+func (e UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("operation '%s' on column '%s' is not supported by %s at line %d, offset %d", e.Operation, e.Column, e.Backend, e.Line, e.Pos)
+}
+
+func (e UnsupportedOperationError) Position() (int, int) {
+	return e.Line, e.Pos
+}
+
+func (e UnsupportedOperationError) Pretty(filter string) string {
+	return prettyError(e, filter, fmt.Sprintf("%s cannot run this operation, rewrite the condition", e.Backend))
+}
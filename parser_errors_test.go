@@ -0,0 +1,31 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyErrors(t *testing.T) {
+	filter := "name eq 1 and\n  secret eq 2"
+	_, err := Parse(filter, func(col string) bool { return col != "secret" })
+	assert.Equal(t, "invalid column 'secret' at line 2, offset 16\n"+
+		" 2 |   secret eq 2\n"+
+		"   |   ^\n"+
+		"hint: the column does not exist or cannot be filtered on", err.(ParseError).Pretty(filter))
+
+	filter = `age gte`
+	_, err = Parse(filter, validateColumn)
+	assert.Equal(t, "expected a valid value for column 'age' at line 1, offset 7\n"+
+		" 1 | age gte\n"+
+		"   |        ^\n"+
+		"hint: add a value after the operation, strings must be quoted", err.(ParseError).Pretty(filter))
+
+	for _, filter := range []string{`a eq 1 )`, `and a eq 1`, `a eq 1 b eq 2`, `a between [1]`, `ip in_subnet "x"`, `a eq 1 and (b eq 2`} {
+		_, err = Parse(filter, validateColumn)
+		pretty, ok := err.(ParseError)
+		if assert.True(t, ok, filter) {
+			assert.Contains(t, pretty.Pretty(filter), "^\nhint: ", filter)
+		}
+	}
+}
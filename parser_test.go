@@ -2,6 +2,7 @@ package rqe
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -153,3 +154,381 @@ func TestSQLInjection(t *testing.T) {
 		})
 	}
 }
+
+func TestNullValue(t *testing.T) {
+	q, err := Parse(`deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at IS NULL", q.SQL)
+	assert.Empty(t, q.Args)
+
+	q, err = Parse(`deleted_at ne null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at IS NOT NULL", q.SQL)
+	assert.Empty(t, q.Args)
+
+	q, err = Parse(`deleted_at eq "null"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at = ?", q.SQL)
+	assert.Equal(t, []interface{}{"null"}, q.Args)
+
+	_, err = Parse(`deleted_at gt null`, validateColumn)
+	assert.IsType(t, InvalidOperationError{}, err)
+}
+
+func TestInlineEnum(t *testing.T) {
+	opt := WithInlineEnum("status", 1, 2, 3)
+
+	q, err := Parse(`status eq 2`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "status = 2", q.SQL)
+	assert.Empty(t, q.Args)
+
+	// array members are decoded as float64 and therefore never inlined
+	q, err = Parse(`status in [1, 9]`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "status IN (?, ?)", q.SQL)
+	assert.Equal(t, []interface{}{float64(1), float64(9)}, q.Args)
+
+	q, err = Parse(`status eq "2"`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "status = ?", q.SQL)
+	assert.Equal(t, []interface{}{"2"}, q.Args)
+
+	q, err = Parse(`status eq 7`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "status = ?", q.SQL)
+	assert.Equal(t, []interface{}{int64(7)}, q.Args)
+}
+
+func TestNumericLiterals(t *testing.T) {
+	tests := []struct {
+		filter string
+		arg    interface{}
+	}{
+		{`balance lt -100`, int64(-100)},
+		{`balance lt 100`, int64(100)},
+		{`value gte 1.5e6`, float64(1.5e6)},
+		{`value gte 2E-3`, float64(0.002)},
+		{`value gte -1.5e-3`, float64(-0.0015)},
+		{`value gte -0.25`, float64(-0.25)},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			q, err := Parse(test.filter, validateColumn)
+			assert.NoError(t, err)
+			assert.Equal(t, []interface{}{test.arg}, q.Args)
+		})
+	}
+
+	_, err := Parse(`balance lt - 100`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+
+	_, err = Parse(`balance lt -"100"`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+}
+
+func TestSanitizers(t *testing.T) {
+	q, err := Parse(`name eq "  John "`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"John"}, q.Args)
+
+	q, err = Parse(`name eq "  John "`, validateColumn, WithSanitizer("eq", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"  John "}, q.Args)
+
+	q, err = Parse(`name in ["jo%n", "j_", 5]`, validateColumn, WithSanitizer("in", StripWildcards))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"jon", "j", float64(5)}, q.Args)
+}
+
+func TestFoldedColumns(t *testing.T) {
+	q, err := Parse(`name eq "jose" and city in ["Zürich", "Genève"] and zip eq 10`, validateColumn, WithFoldedColumns("name", "city"))
+	assert.NoError(t, err)
+	assert.Contains(t, q.SQL, "LOWER(unaccent(name)) = LOWER(unaccent(?))")
+	assert.Contains(t, q.SQL, "LOWER(unaccent(city)) IN (LOWER(unaccent(?)), LOWER(unaccent(?)))")
+	assert.Contains(t, q.SQL, "zip = ?")
+	assert.Equal(t, []interface{}{"jose", "Zürich", "Genève", int64(10)}, q.Args)
+
+	// dialects without unaccent compare with an accent insensitive collation
+	q, err = Parse(`name eq "jose" and city in ["Zürich"]`, validateColumn, WithFoldedColumns("name", "city"), MySQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "name COLLATE utf8mb4_0900_ai_ci = ? and city COLLATE utf8mb4_0900_ai_ci IN (?)", q.SQL)
+	assert.Equal(t, []interface{}{"jose", "Zürich"}, q.Args)
+
+	q, err = Parse(`name eq "jose"`, validateColumn, WithFoldedColumns("name"), MSSQL, WithHardened())
+	assert.NoError(t, err)
+	assert.Equal(t, "[name] COLLATE Latin1_General_CI_AI = @p1", q.SQL)
+
+	_, err = Parse(`zip eq 10 and name eq "jose"`, validateColumn, WithFoldedColumns("name"), BigQuery)
+	assert.Equal(t, UnsupportedOperationError{Backend: "bigquery", Operation: "eq", Column: "name", Line: 1, Pos: 18}, err)
+}
+
+func TestSymbolicOperators(t *testing.T) {
+	tests := map[string]string{
+		`age >= 25`:  `age gte 25`,
+		`age <= 25`:  `age lte 25`,
+		`age > 25`:   `age gt 25`,
+		`age < 25`:   `age lt 25`,
+		`age = 25`:   `age eq 25`,
+		`age != 25`:  `age ne 25`,
+		`age <> 25`:  `age ne 25`,
+		`age>=25`:    `age gte 25`,
+		`a = null`:   `a eq null`,
+		`a != null`:  `a ne null`,
+		`n >= -1e3`:  `n gte -1e3`,
+		`order_id=1`: `order_id eq 1`,
+	}
+	for symbolic, word := range tests {
+		t.Run(symbolic, func(t *testing.T) {
+			expected, err := Parse(word, validateColumn)
+			assert.NoError(t, err)
+			actual, err := Parse(symbolic, validateColumn)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestKeywordPrefixedColumns(t *testing.T) {
+	q, err := Parse(`age gte 25 and order_count gt 5 or (inactive eq 1 and ltv lt 3)`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(25), int64(5), int64(1), int64(3)}, q.Args)
+}
+
+func TestKeywordAliases(t *testing.T) {
+	parser := NewParser(WithKeywordAliases(map[string]string{"y": "and", "o": "or", "mayor": "gt", "roto": "nope"}))
+
+	expected, err := Parse(`age gt 18 and (name eq "a" or name eq "b")`, validateColumn)
+	assert.NoError(t, err)
+	actual, err := parser.Parse(`age mayor 18 y (name eq "a" o name eq "b")`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = parser.Parse(`age roto 18`, validateColumn)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	_, err = Parse(`age gt 18 y name eq "a"`, validateColumn)
+	assert.Error(t, err)
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	q, err := Parse(`created_at gte "now-7d" and updated_at lt 'now+1h' and seen_at lte "now"`, validateColumn, clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{now.AddDate(0, 0, -7), now.Add(time.Hour), now}, q.Args)
+
+	q, err = Parse(`created_at between ["now-1M", "now-2w"]`, validateColumn, clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{now.AddDate(0, -1, 0), now.AddDate(0, 0, -14)}, q.Args)
+
+	q, err = Parse(`name eq "nowhere" and note eq "now-7x"`, validateColumn, clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"nowhere", "now-7x"}, q.Args)
+
+	// only date columns and the ranges of untyped ones are resolved
+	q, err = Parse(`word eq "now" and word contains "now"`, validateColumn, clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"now", "%now%"}, q.Args)
+
+	schema := WithSchema(Schema{"word": {Type: TypeString, Capabilities: Filterable}, "created_at": {Type: TypeDate, Capabilities: Filterable}})
+	q, err = Parse(`word eq "now" and word gt "now" and created_at eq "now"`, nil, clock, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"now", "now", now}, q.Args)
+}
+
+func TestWeekStart(t *testing.T) {
+	// a sunday afternoon
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	q, err := Parse(`created_at gte "last_week" and created_at lt "this_week" and updated_at lt "this_week+2d"`, validateColumn, clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{day(-3), day(4), day(6)}, q.Args)
+
+	q, err = Parse(`created_at between ["last_week", "this_week"]`, validateColumn, clock, WithLocale("en-US"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{day(3), day(10)}, q.Args)
+
+	q, err = Parse(`created_at gte "this_week"`, validateColumn, clock, WithLocale("ar_EG"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{day(9)}, q.Args)
+
+	q, err = Parse(`created_at gte "this_week"`, validateColumn, clock, WithLocale("fr-FR"), WithWeekStart(time.Wednesday))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{day(6)}, q.Args)
+}
+
+func TestBetween(t *testing.T) {
+	q, err := Parse(`age nbetween [18, 65]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "age NOT BETWEEN ? AND ?", q.SQL)
+	assert.Equal(t, []interface{}{float64(18), float64(65)}, q.Args)
+
+	q, err = Parse(`age between [18, 65]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "age BETWEEN ? AND ?", q.SQL)
+
+	for _, filter := range []string{`age between [18]`, `age nbetween [1, 2, 3]`, `age nbetween 5`, `age between "x"`} {
+		_, err = Parse(filter, validateColumn)
+		assert.IsType(t, ValueCountError{}, err, filter)
+	}
+}
+
+func TestFromUntil(t *testing.T) {
+	q, err := Parse(`created_at from_until ["2024-01-01", "2024-02-01"] or age eq 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= ? AND created_at < ? or age = ?", q.SQL)
+	assert.Equal(t, []interface{}{"2024-01-01", "2024-02-01", int64(3)}, q.Args)
+
+	q, err = Parse(`created_at between ["2024-01-01", "2024-02-01"]`, validateColumn, WithExclusiveBetween(), Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at >= $1 AND created_at < $2", q.SQL)
+
+	_, err = Parse(`created_at from_until ["2024-01-01"]`, validateColumn)
+	assert.IsType(t, ValueCountError{}, err)
+
+	match, err := Evaluate(`age from_until [18, 65]`, map[string]any{"age": 65})
+	assert.NoError(t, err)
+	assert.False(t, match)
+}
+
+func TestBareWords(t *testing.T) {
+	parser := NewParser(WithBareWords())
+
+	q, err := parser.Parse(`status eq active and role ne super_admin or deleted_at eq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(status = ? and role <> ?) or deleted_at IS NULL", q.SQL)
+	assert.Equal(t, []interface{}{"active", "super_admin"}, q.Args)
+
+	_, err = parser.Parse(`status eq and`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+
+	_, err = Parse(`status eq active`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+}
+
+func TestOperatorChaining(t *testing.T) {
+	q, err := Parse(`age gte 18 lte 65`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "age >= ? and age <= ?", q.SQL)
+	assert.Equal(t, []interface{}{int64(18), int64(65)}, q.Args)
+
+	q, err = Parse(`age > 18 < 65 or name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(age > ? and age < ?) or name = ?", q.SQL)
+
+	_, err = Parse(`age gte 18 foo 65`, validateColumn)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+
+	_, err = Parse(`age gte 18 lte`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	q, err := Parse(`flags band 4`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(flags & ?) <> 0", q.SQL)
+	assert.Equal(t, []interface{}{int64(4)}, q.Args)
+
+	q, err = Parse(`flags bor 6 and name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(flags | ?) = ? and name = ?", q.SQL)
+	assert.Equal(t, []interface{}{int64(6), int64(6), "x"}, q.Args)
+
+	for _, filter := range []string{`flags band "4"`, `flags band 4.5`, `flags bor [1, 2]`} {
+		_, err = Parse(filter, validateColumn)
+		assert.IsType(t, InvalidOperationError{}, err, filter)
+	}
+}
+
+func TestSoundsLike(t *testing.T) {
+	q, err := Parse(`last_name sounds_like "smyth"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "SOUNDEX(last_name) = SOUNDEX(?)", q.SQL)
+	assert.Equal(t, []interface{}{"smyth"}, q.Args)
+	assert.Equal(t, IntentSearch, Classify(mustParse(t, `last_name sounds_like "smyth"`), IntentHints{Indexed: []string{"last_name"}}))
+}
+
+func TestInSubnet(t *testing.T) {
+	q, err := Parse(`client_ip in_subnet "10.0.0.0/8"`, validateColumn, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "client_ip << $1", q.SQL)
+	assert.Equal(t, []interface{}{"10.0.0.0/8"}, q.Args)
+
+	_, err = Parse(`client_ip in_subnet "2001:db8::/32"`, validateColumn, Postgres)
+	assert.NoError(t, err)
+
+	for _, filter := range []string{`client_ip in_subnet "10.0.0.0"`, `client_ip in_subnet "nope/8"`, `client_ip in_subnet 10`} {
+		_, err = Parse(filter, validateColumn, Postgres)
+		assert.IsType(t, InvalidValueError{}, err, filter)
+	}
+
+	// `<<` is a bit shift outside of postgres
+	_, err = Parse(`client_ip in_subnet "10.0.0.0/8"`, validateColumn)
+	assert.IsType(t, InvalidOperationError{}, err)
+}
+
+func TestNullSafeEquality(t *testing.T) {
+	q, err := Parse(`manager_id nseq 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "manager_id IS NOT DISTINCT FROM ?", q.SQL)
+	assert.Equal(t, []interface{}{int64(3)}, q.Args)
+
+	q, err = Parse(`manager_id nseq null`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "manager_id IS NULL", q.SQL)
+	assert.Empty(t, q.Args)
+}
+
+func TestWithinEdits(t *testing.T) {
+	fuzzy := WithCapabilities(CapabilityFuzzyStrMatch)
+
+	q, err := Parse(`name within_edits ["jon", 2]`, validateColumn, fuzzy, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "levenshtein(name, $1) <= $2", q.SQL)
+	assert.Equal(t, []interface{}{"jon", int64(2)}, q.Args)
+
+	q, err = Parse(`name within_edits ["jon", 1]`, validateColumn, fuzzy, Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, `UTL_MATCH.EDIT_DISTANCE("NAME", :1) <= :2`, q.SQL)
+
+	_, err = Parse(`name within_edits ["jon", 2]`, validateColumn)
+	assert.IsType(t, InvalidOperationError{}, err)
+	_, err = Parse(`name within_edits ["jon", 2]`, validateColumn, fuzzy, MySQL)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	for _, filter := range []string{`name within_edits ["jon", 1.5]`, `name within_edits ["jon", 9]`, `name within_edits [1, 2]`} {
+		_, err = Parse(filter, validateColumn, fuzzy)
+		assert.IsType(t, InvalidValueError{}, err, filter)
+	}
+	_, err = Parse(`name within_edits ["jon"]`, validateColumn, fuzzy)
+	assert.IsType(t, ValueCountError{}, err)
+}
+
+func TestDigestColumns(t *testing.T) {
+	opt := WithDigestColumns("body")
+
+	q, err := Parse(`body eq "hello" and title eq "x"`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "body_md5 = ? and title = ?", q.SQL)
+	assert.Equal(t, []interface{}{"5d41402abc4b2a76b9719d911017c592", "x"}, q.Args)
+	assert.Equal(t, []string{"body", "title"}, q.Columns)
+
+	q, err = Parse(`body nin ["hello", "world"]`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "body_md5 NOT IN (?, ?)", q.SQL)
+	assert.Equal(t, []interface{}{Digest("hello"), Digest("world")}, q.Args)
+
+	q, err = Parse(`body eq null or body contains "ell"`, validateColumn, opt)
+	assert.NoError(t, err)
+	assert.Equal(t, "body IS NULL or body LIKE ? ESCAPE '!'", q.SQL)
+
+	// the companion follows the column's database name and table
+	schema := WithSchema(Schema{"email": {Capabilities: Filterable, DBName: "contact_email"}})
+	q, err = Parse(`email eq "a@b.c"`, nil, schema, WithDigestColumns("email"), WithColumnTable("email", "users"), MSSQL, WithHardened())
+	assert.NoError(t, err)
+	assert.Equal(t, "[users].[contact_email_md5] = @p1", q.SQL)
+}
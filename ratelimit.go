@@ -0,0 +1,97 @@
+package rqe
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is how many callers are tracked before full buckets are dropped
+const maxIdleBuckets = 4096
+
+// RateLimiter is a token bucket per caller key, filters are charged their complexity so
+// a client flooding large filters is throttled sooner than one sending simple ones.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter refilling rate tokens per second up to burst tokens per caller.
+// A rate of 0 never refills, every caller gets burst tokens in total. burst must be positive.
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if burst <= 0 {
+		return nil, fmt.Errorf("rate limiter burst must be positive, got %d", burst)
+	}
+	if rate < 0 || math.IsNaN(rate) {
+		return nil, fmt.Errorf("rate limiter rate cannot be negative, got %v", rate)
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket), now: time.Now}, nil
+}
+
+// Take charges cost tokens to the caller. It returns 0 when allowed, or how long the caller
+// should wait before retrying, the maximum Duration when the limiter never refills. Costs are clamped to [1, burst] so any filter can eventually pass.
+func (l *RateLimiter) Take(key string, cost int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	need := min(max(float64(cost), 1), l.burst)
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxIdleBuckets {
+			l.sweep(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens >= need {
+		b.tokens -= need
+		return 0
+	}
+	if l.rate == 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration((need - b.tokens) / l.rate * float64(time.Second))
+}
+
+// refill returns the tokens of the bucket at now
+func (l *RateLimiter) refill(b *bucket, now time.Time) float64 {
+	return min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
+
+// sweep drops the buckets that refilled, they behave exactly like a new caller
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ParseFor parses the filter on behalf of a caller, charging its complexity to the caller's
+// bucket when WithRateLimiter is configured. A throttled caller gets a RateLimitError before
+// the filter is compiled, without a limiter it behaves like Parse.
+func (p *Parser) ParseFor(key, filter string, validateCol func(col string) bool) (ParsedQuery, error) {
+	expr, err := p.ParseExpr(filter, validateCol)
+	if err != nil {
+		return ParsedQuery{}, err
+	}
+	if p.limiter != nil {
+		if wait := p.limiter.Take(key, complexity(exprStats(expr))); wait > 0 {
+			return ParsedQuery{}, RateLimitError{Key: key, RetryAfter: wait}
+		}
+	}
+	return p.compileChecked(expr)
+}
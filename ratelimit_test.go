@@ -0,0 +1,57 @@
+package rqe
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter, err := NewRateLimiter(2, 10)
+	assert.NoError(t, err)
+	limiter.now = func() time.Time { return now }
+	p := NewParser(WithRateLimiter(limiter))
+
+	// name eq "bob" costs 3 : a condition, a value and a level
+	for range 3 {
+		_, err := p.ParseFor("abuser", `name eq "bob"`, validateColumn)
+		assert.NoError(t, err)
+	}
+	_, err = p.ParseFor("abuser", `name eq "bob"`, validateColumn)
+	assert.Equal(t, RateLimitError{Key: "abuser", RetryAfter: time.Second}, err)
+
+	// other callers have their own bucket
+	_, err = p.ParseFor("someone", `name eq "bob"`, validateColumn)
+	assert.NoError(t, err)
+
+	now = now.Add(time.Second)
+	q, err := p.ParseFor("abuser", `name eq "bob"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", q.SQL)
+
+	// parse errors are reported as is and not charged
+	_, err = p.ParseFor("abuser", `name eq`, validateColumn)
+	assert.IsType(t, MissingValueError{}, err)
+
+	// without a limiter nothing is throttled
+	for range 10 {
+		_, err = NewParser().ParseFor("abuser", `name eq "bob"`, validateColumn)
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimiterArguments(t *testing.T) {
+	for _, args := range [][2]float64{{1, 0}, {1, -1}, {-1, 10}, {math.NaN(), 10}} {
+		_, err := NewRateLimiter(args[0], int(args[1]))
+		assert.Error(t, err, args)
+	}
+
+	// a zero rate never refills
+	limiter, err := NewRateLimiter(0, 3)
+	assert.NoError(t, err)
+	assert.Zero(t, limiter.Take("caller", 3))
+	assert.Equal(t, time.Duration(math.MaxInt64), limiter.Take("caller", 1))
+}
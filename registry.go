@@ -0,0 +1,113 @@
+package rqe
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/baderkha/rqe/macros"
+)
+
+// registryMu guards the registrations, they are meant to happen in init functions of extension
+// packages before any filter is parsed
+var registryMu sync.Mutex
+
+// dialects are the registered dialects by name
+var dialects = map[string]Dialect{
+	PostgresDialect.Name: PostgresDialect,
+	MySQLDialect.Name:    MySQLDialect,
+	MSSQLDialect.Name:    MSSQLDialect,
+	OracleDialect.Name:   OracleDialect,
+	BigQueryDialect.Name: BigQueryDialect,
+}
+
+// RegisterDialect makes a dialect available by name to LookupDialect, typically from the init
+// function of an extension package. Registering a name twice is an error.
+func RegisterDialect(d Dialect) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if !validPluginName(d.Name) {
+		return RegistrationError{Kind: "dialect", Name: d.Name, Reason: "invalid name"}
+	}
+	if _, exists := dialects[d.Name]; exists {
+		return RegistrationError{Kind: "dialect", Name: d.Name, Reason: "already registered"}
+	}
+	dialects[d.Name] = d
+	return nil
+}
+
+// LookupDialect returns the registered dialect with the name
+func LookupDialect(name string) (Dialect, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	d, ok := dialects[name]
+	return d, ok
+}
+
+// Dialects returns the names of the registered dialects, sorted
+func Dialects() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Sorted(maps.Keys(dialects))
+}
+
+// RegisterOperator adds an operation usable in every filter, e.g. `location within_km [...]`.
+// Registering an existing operation (built-in or not) is an error.
+func RegisterOperator(name string, op OperationMeta) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if !validPluginName(name) || op.Value == nil && op.Format == nil {
+		return RegistrationError{Kind: "operator", Name: name, Reason: "invalid name or missing SQL"}
+	}
+	if _, exists := operationsMapped[name]; exists {
+		return RegistrationError{Kind: "operator", Name: name, Reason: "already registered"}
+	}
+	operationsMapped[name] = op
+	return nil
+}
+
+// Operators returns the names of every operation, built-in and registered, sorted
+func Operators() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Sorted(maps.Keys(operationsMapped))
+}
+
+// MacroFactory creates the handler of a macro
+type MacroFactory func() macros.Macro
+
+// RegisterMacroFactory adds a macro usable as `column op name(value)`. Registering an existing
+// macro is an error.
+func RegisterMacroFactory(name string, factory MacroFactory) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if !validPluginName(name) || factory == nil {
+		return RegistrationError{Kind: "macro", Name: name, Reason: "invalid name or missing factory"}
+	}
+	if _, exists := macros.Handlers[name]; exists || slices.Contains(macros.Supported, name) {
+		return RegistrationError{Kind: "macro", Name: name, Reason: "already registered"}
+	}
+	macros.Handlers[name] = factory()
+	macros.Supported = append(macros.Supported, name)
+	return nil
+}
+
+// Macros returns the names of the available macros, sorted
+func Macros() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return slices.Sorted(slices.Values(macros.Supported))
+}
+
+// validPluginName reports whether the name can be lexed as a single keyword
+func validPluginName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !isIdentRune(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/baderkha/rqe/macros"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	defer func() {
+		delete(dialects, "duckdb")
+		delete(operationsMapped, "within_km")
+		delete(macros.Handlers, "double")
+		macros.Supported = macros.Supported[:len(macros.Supported)-1]
+	}()
+
+	duckdb := Dialect{Name: "duckdb", Placeholder: func(n int) string { return "$" + string(rune('0'+n)) }}
+	assert.NoError(t, RegisterDialect(duckdb))
+	assert.Equal(t, RegistrationError{Kind: "dialect", Name: "duckdb", Reason: "already registered"}, RegisterDialect(duckdb))
+	assert.Equal(t, RegistrationError{Kind: "dialect", Name: "postgres", Reason: "already registered"}, RegisterDialect(Dialect{Name: "postgres"}))
+	assert.Equal(t, []string{"bigquery", "duckdb", "mssql", "mysql", "oracle", "postgres"}, Dialects())
+	d, ok := LookupDialect("duckdb")
+	assert.True(t, ok)
+	assert.Equal(t, "duckdb", d.Name)
+
+	assert.NoError(t, RegisterOperator("within_km", OperationMeta{
+		IsMultiValue:    true,
+		MultiValueLimit: 3,
+		Format: func(col string, _ int) string {
+			return "ST_DWithin(" + col + ", ST_MakePoint(?, ?), ? * 1000)"
+		},
+	}))
+	assert.Equal(t, RegistrationError{Kind: "operator", Name: "eq", Reason: "already registered"}, RegisterOperator("eq", OperationMeta{Value: operationsMapped["eq"].Value}))
+	assert.IsType(t, RegistrationError{}, RegisterOperator("near by", OperationMeta{Value: operationsMapped["eq"].Value}))
+	assert.Contains(t, Operators(), "within_km")
+
+	assert.NoError(t, RegisterMacroFactory("double", func() macros.Macro {
+		return macroFunc(func(_ string, args ...any) ([]any, error) { return []any{args[0].(int64) * 2}, nil })
+	}))
+	assert.Equal(t, RegistrationError{Kind: "macro", Name: "age", Reason: "already registered"}, RegisterMacroFactory("age", func() macros.Macro { return nil }))
+	assert.Equal(t, []string{"age", "double"}, Macros())
+
+	q, err := Parse(`location within_km [1.5, 2.5, 10] and age gt double(2)`, validateColumn, WithDialect(d))
+	assert.NoError(t, err)
+	assert.Equal(t, "ST_DWithin(location, ST_MakePoint($1, $2), $3 * 1000) and age > $4", q.SQL)
+	assert.Equal(t, []any{1.5, 2.5, float64(10), int64(4)}, q.Args)
+}
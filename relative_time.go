@@ -0,0 +1,122 @@
+package rqe
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeTimeExpr matches `now`, `this_week` or `last_week`, optionally shifted by an amount and
+// a unit e.g. `now-7d`, `now+1h`, `this_week+2d`
+var relativeTimeExpr = regexp.MustCompile(`^(now|this_week|last_week)(?:([+-])(\d+)([smhdwMy]))?$`)
+
+// sundayRegions and saturdayRegions are the regions whose weeks do not start on monday
+var (
+	sundayRegions   = []string{"US", "CA", "MX", "BR", "JP", "KR", "TW", "HK", "IL", "IN", "PH", "ZA"}
+	saturdayRegions = []string{"EG", "SA", "IR", "AF", "DZ", "LY", "SY", "IQ", "JO", "KW", "QA", "OM", "BH", "YE"}
+)
+
+// weekStartOf returns the first day of the week of a locale (`en-US`, `fr_FR`, `de`), monday
+// unless its region starts the week on another day
+func weekStartOf(locale string) time.Weekday {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) < 2 {
+		return time.Monday
+	}
+	region := strings.ToUpper(parts[len(parts)-1])
+	switch {
+	case slices.Contains(sundayRegions, region):
+		return time.Sunday
+	case slices.Contains(saturdayRegions, region):
+		return time.Saturday
+	default:
+		return time.Monday
+	}
+}
+
+// rangeOperations are the operations relative times are resolved for on untyped columns
+var rangeOperations = map[string]struct{}{
+	"lt":         {},
+	"lte":        {},
+	"gt":         {},
+	"gte":        {},
+	"between":    {},
+	"nbetween":   {},
+	"from_until": {},
+}
+
+// resolvesRelativeTimes reports whether the values of the operation on col are resolved as relative
+// times : any non pattern operation on a TypeDate column, the range operations on an untyped one.
+// `word eq "now"` keeps its string.
+func (p *Parser) resolvesRelativeTimes(col, opName string) bool {
+	if _, search := searchOperations[opName]; search {
+		return false
+	}
+	switch p.schema[col].Type {
+	case TypeDate:
+		return true
+	case TypeAny:
+		_, ranged := rangeOperations[opName]
+		return ranged
+	}
+	return false
+}
+
+// relativeTime resolves a relative time literal with the parser's clock, week start and client zone
+func (p *Parser) relativeTime(val string) (time.Time, bool) {
+	now := p.now()
+	if p.clientZone != nil {
+		now = now.In(p.clientZone)
+	}
+	return resolveRelativeTime(val, now, p.weekStart)
+}
+
+// resolveRelativeTime converts a relative time literal into a concrete time based on now.
+// `this_week` and `last_week` are midnight of the first day of the current and previous week.
+// The second return is false when the value is not a relative time literal.
+//
+// Units : s (seconds), m (minutes), h (hours), d (days), w (weeks), M (months), y (years)
+func resolveRelativeTime(val string, now time.Time, weekStart time.Weekday) (time.Time, bool) {
+	match := relativeTimeExpr.FindStringSubmatch(val)
+	if match == nil {
+		return time.Time{}, false
+	}
+	switch match[1] {
+	case "this_week", "last_week":
+		offset := (int(now.Weekday()) - int(weekStart) + 7) % 7
+		now = time.Date(now.Year(), now.Month(), now.Day()-offset, 0, 0, 0, 0, now.Location())
+		if match[1] == "last_week" {
+			now = now.AddDate(0, 0, -7)
+		}
+	}
+	if match[2] == "" {
+		return now, true
+	}
+
+	amount, err := strconv.Atoi(match[3])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if match[2] == "-" {
+		amount = -amount
+	}
+
+	switch match[4] {
+	case "s":
+		return now.Add(time.Duration(amount) * time.Second), true
+	case "m":
+		return now.Add(time.Duration(amount) * time.Minute), true
+	case "h":
+		return now.Add(time.Duration(amount) * time.Hour), true
+	case "d":
+		return now.AddDate(0, 0, amount), true
+	case "w":
+		return now.AddDate(0, 0, 7*amount), true
+	case "M":
+		return now.AddDate(0, amount, 0), true
+	default: // y
+		return now.AddDate(amount, 0, 0), true
+	}
+}
@@ -0,0 +1,139 @@
+package rqe
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReportStats measures the size of a filter
+type ReportStats struct {
+	Conditions int `json:"conditions"`
+	// Values is the number of values bound or inlined
+	Values int `json:"values"`
+	// Depth is the nesting of logical operations, a single condition has a depth of 1
+	Depth int `json:"depth"`
+}
+
+// ReportError is a problem preventing the filter from being used
+type ReportError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Pos     int    `json:"pos,omitempty"`
+	// Pretty is the message with the offending line and a caret, see ParseError.Pretty
+	Pretty string `json:"pretty,omitempty"`
+}
+
+// FilterReport is everything known about a filter in one place, for "filter debugger" screens
+type FilterReport struct {
+	Filter string `json:"filter"`
+	Valid  bool   `json:"valid"`
+	// AST is the parsed expression tree, nil when the filter is empty or invalid
+	AST Expr `json:"ast"`
+	// Shape is the anonymized filter, see Anonymize
+	Shape   string        `json:"shape"`
+	SQL     string        `json:"sql"`
+	Args    []interface{} `json:"args"`
+	Columns []string      `json:"columns"`
+	Stats   ReportStats   `json:"stats"`
+	// Complexity is a rough cost of the filter : every condition, value and level of nesting adds one
+	Complexity int           `json:"complexity"`
+	Warnings   []string      `json:"warnings"`
+	Errors     []ReportError `json:"errors"`
+}
+
+// Report parses the filter with the Default parser (extended with opts) and describes it, see Parser.Report
+func Report(filter string, validateCol func(col string) bool, opts ...Option) FilterReport {
+	return withDefaults(opts).Report(filter, validateCol)
+}
+
+// Report parses and compiles the filter and gathers the result, its statistics, warnings
+// and errors in a single struct. Errors are reported instead of returned.
+func (p *Parser) Report(filter string, validateCol func(col string) bool) FilterReport {
+	report := FilterReport{Filter: filter, Args: make([]interface{}, 0), Columns: make([]string, 0), Warnings: make([]string, 0), Errors: make([]ReportError, 0)}
+
+	expr, err := p.ParseExpr(filter, validateCol)
+	if err != nil {
+		report.Errors = reportErrors(err, filter)
+		return report
+	}
+	report.Valid = true
+	if expr == nil {
+		return report
+	}
+
+	q := p.Compile(expr)
+	report.AST = expr
+	report.Shape = Anonymize(expr)
+	report.SQL, report.Args, report.Columns = q.SQL, q.Args, q.Columns
+	report.Stats = exprStats(expr)
+	report.Complexity = complexity(report.Stats)
+	report.Warnings = reportWarnings(expr)
+	return report
+}
+
+func reportErrors(err error, filter string) []ReportError {
+	var violations RuleViolationError
+	if errors.As(err, &violations) {
+		out := make([]ReportError, len(violations.Violations))
+		for i, v := range violations.Violations {
+			out[i] = ReportError{Message: v.Message}
+		}
+		return out
+	}
+	var parseErr ParseError
+	if errors.As(err, &parseErr) {
+		line, pos := parseErr.Position()
+		return []ReportError{{Message: err.Error(), Line: line, Pos: pos, Pretty: parseErr.Pretty(filter)}}
+	}
+	return []ReportError{{Message: err.Error()}}
+}
+
+// reportWarnings flags valid conditions that likely do not do what the client expects
+func reportWarnings(expr Expr) []string {
+	warnings := make([]string, 0)
+	Walk(expr, func(c *Condition) {
+		if _, excludes := nullExcludingOperations[c.Operator]; excludes && !c.IsNull() {
+			warnings = append(warnings, fmt.Sprintf("'%s %s' never matches rows where '%s' is null", c.Column, c.Operator, c.Column))
+		}
+		if c.Operator == "like" || c.Operator == "ilike" {
+			for _, v := range c.Values {
+				if s, ok := v.(string); ok && (strings.HasPrefix(s, "%") || strings.HasPrefix(s, "_")) {
+					warnings = append(warnings, fmt.Sprintf("'%s %s' starts with a wildcard and cannot use an index", c.Column, c.Operator))
+				}
+			}
+		}
+	})
+	return warnings
+}
+
+// complexity is the rough cost of a filter, every condition, value and level of nesting adds one
+func complexity(stats ReportStats) int {
+	return stats.Conditions + stats.Values + stats.Depth
+}
+
+// exprStats counts the conditions, values and nesting of the expression
+func exprStats(expr Expr) ReportStats {
+	switch e := expr.(type) {
+	case *Condition:
+		return ReportStats{Conditions: 1, Values: len(e.Values), Depth: 1}
+	case *Tuple:
+		return ReportStats{Conditions: 1, Values: len(e.Values), Depth: 1}
+	case *Not:
+		stats := exprStats(e.Expr)
+		stats.Depth++
+		return stats
+	case *Logical:
+		var stats ReportStats
+		for _, child := range e.Exprs {
+			childStats := exprStats(child)
+			stats.Conditions += childStats.Conditions
+			stats.Values += childStats.Values
+			stats.Depth = max(stats.Depth, childStats.Depth)
+		}
+		stats.Depth++
+		return stats
+	default:
+		return ReportStats{}
+	}
+}
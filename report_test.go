@@ -0,0 +1,40 @@
+package rqe
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	report := Report(`name like "%jo" and (age between [18, 30] or status ne "x")`, validateColumn, Postgres)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, "name LIKE $1 and (age BETWEEN $2 AND $3 or status <> $4)", report.SQL)
+	assert.Equal(t, []string{"name", "age", "status"}, report.Columns)
+	assert.Equal(t, ReportStats{Conditions: 3, Values: 4, Depth: 3}, report.Stats)
+	assert.Equal(t, 10, report.Complexity)
+	assert.Equal(t, "((age between ? or status ne ?) and name like ?)", report.Shape)
+	assert.Equal(t, []string{
+		"'name like' starts with a wildcard and cannot use an index",
+		"'status ne' never matches rows where 'status' is null",
+	}, report.Warnings)
+	_, err := json.Marshal(report)
+	assert.NoError(t, err)
+
+	report = Report("name eq 1 and\n  secret eq 2", func(col string) bool { return col != "secret" })
+	assert.False(t, report.Valid)
+	assert.Nil(t, report.AST)
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Line)
+	assert.Contains(t, report.Errors[0].Pretty, "hint: ")
+
+	report = Report(`name eq 1`, validateColumn, WithMandatoryColumns("tenant_id", "date"))
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Errors, 2)
+
+	report = Report(``, validateColumn)
+	assert.True(t, report.Valid)
+	assert.Equal(t, ReportStats{}, report.Stats)
+}
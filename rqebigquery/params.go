@@ -0,0 +1,21 @@
+// Package rqebigquery binds rqe queries compiled with rqe.BigQueryDialect to the BigQuery client
+//
+//	q, err := rqe.Parse(filter, validateCol, rqe.BigQuery)
+//	query := client.Query("SELECT * FROM `ds.users` WHERE " + q.SQL)
+//	query.Parameters = rqebigquery.Params(q.Args)
+package rqebigquery
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// Params names the arguments after the `@pN` parameters of rqe.BigQueryDialect
+func Params(args []interface{}) []bigquery.QueryParameter {
+	params := make([]bigquery.QueryParameter, len(args))
+	for i, arg := range args {
+		params[i] = bigquery.QueryParameter{Name: fmt.Sprintf("p%d", i+1), Value: arg}
+	}
+	return params
+}
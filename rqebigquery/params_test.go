@@ -0,0 +1,16 @@
+package rqebigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams(t *testing.T) {
+	assert.Equal(t, []bigquery.QueryParameter{
+		{Name: "p1", Value: "bob"},
+		{Name: "p2", Value: int64(18)},
+	}, Params([]interface{}{"bob", int64(18)}))
+	assert.Empty(t, Params(nil))
+}
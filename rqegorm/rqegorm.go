@@ -0,0 +1,72 @@
+// Package rqegorm plugs rqe filters into GORM queries
+//
+//	db.Scopes(rqegorm.Scope(r.URL.Query().Get("filter"), schema)).Find(&users)
+package rqegorm
+
+import (
+	"github.com/baderkha/rqe"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dialectNames are the rqe dialects of the GORM dialectors named differently,
+// others are looked up under their own name
+var dialectNames = map[string]string{
+	"sqlserver": rqe.MSSQLDialect.Name,
+}
+
+// Scope returns a GORM scope adding the filter to the query's conditions. The filter is checked
+// against the schema and compiled for the database's dialect, an invalid filter is added to the
+// query's errors so it fails without reaching the database. Conditions on aggregate columns
+// (rqe.Column.Aggregate) are added with Having, group the query yourself.
+func Scope(filter string, schema rqe.Schema, opts ...rqe.Option) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		q, err := parse(db, filter, schema, opts)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if q.SQL != "" {
+			db = db.Where(clause.Expr{SQL: q.SQL, Vars: whereArgs(q)})
+		}
+		if q.Having != "" {
+			db = db.Having(clause.Expr{SQL: q.Having, Vars: q.HavingArgs})
+		}
+		return db
+	}
+}
+
+// Where compiles the filter into a GORM clause expression for db's dialect, nil when the filter
+// is empty. Named arguments (rqe.WithNamedArgs) are not supported as GORM binds `?` itself.
+func Where(db *gorm.DB, filter string, schema rqe.Schema, opts ...rqe.Option) (clause.Expression, error) {
+	q, err := parse(db, filter, schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	if q.SQL == "" {
+		return nil, nil
+	}
+	return clause.Expr{SQL: q.SQL, Vars: whereArgs(q)}, nil
+}
+
+// whereArgs are the arguments of the WHERE clause, the HAVING ones come last
+func whereArgs(q rqe.ParsedQuery) []any {
+	return q.Args[:len(q.Args)-len(q.HavingArgs)]
+}
+
+// parse compiles the filter for db's dialect
+func parse(db *gorm.DB, filter string, schema rqe.Schema, opts []rqe.Option) (rqe.ParsedQuery, error) {
+	// GORM rewrites `?` into the driver's placeholders, only keep the operators of the dialect
+	dialect := rqe.Dialect{}
+	if db.Dialector != nil {
+		name := db.Dialector.Name()
+		if alias, ok := dialectNames[name]; ok {
+			name = alias
+		}
+		dialect, _ = rqe.LookupDialect(name)
+	}
+	dialect.Placeholder = nil
+
+	opts = append(append([]rqe.Option{}, opts...), rqe.WithSchema(schema), rqe.WithDialect(dialect))
+	return rqe.NewParser(opts...).Parse(filter, nil)
+}
@@ -0,0 +1,61 @@
+package rqegorm
+
+import (
+	"testing"
+
+	"github.com/baderkha/rqe"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+type user struct {
+	ID   int
+	Name string
+	Age  int
+}
+
+// postgresDialector only renames the dummy dialector so the postgres operators are used
+type postgresDialector struct {
+	tests.DummyDialector
+}
+
+func (postgresDialector) Name() string {
+	return "postgres"
+}
+
+var schema = rqe.Schema{
+	"name": {Capabilities: rqe.Filterable | rqe.Searchable},
+	"age":  {Capabilities: rqe.Filterable},
+}
+
+func TestScope(t *testing.T) {
+	db, err := gorm.Open(postgresDialector{}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+
+	stmt := db.Model(&user{}).Where("id > ?", 3).Scopes(Scope(`name ilike "jo%" or age gte 18`, schema)).Find(&[]user{}).Statement
+	assert.NoError(t, stmt.Error)
+	assert.Equal(t, "SELECT * FROM `users` WHERE id > ? AND (name ILIKE ? or age >= ?)", stmt.SQL.String())
+	assert.Equal(t, []any{3, "jo%", int64(18)}, stmt.Vars)
+
+	stmt = db.Model(&user{}).Scopes(Scope(``, schema)).Find(&[]user{}).Statement
+	assert.NoError(t, stmt.Error)
+	assert.Equal(t, "SELECT * FROM `users`", stmt.SQL.String())
+
+	err = db.Model(&user{}).Scopes(Scope(`password eq "x"`, schema)).Find(&[]user{}).Error
+	assert.IsType(t, rqe.InvalidColumnError{}, err)
+}
+
+func TestScopeHaving(t *testing.T) {
+	db, err := gorm.Open(postgresDialector{}, &gorm.Config{DryRun: true})
+	assert.NoError(t, err)
+	aggregates := rqe.Schema{
+		"name":        {Capabilities: rqe.Filterable},
+		"order_count": {Capabilities: rqe.Filterable, Expression: "COUNT(orders.id)", Aggregate: true},
+	}
+
+	stmt := db.Model(&user{}).Group("users.id").Scopes(Scope(`order_count gt 5 and name eq "jo"`, aggregates)).Find(&[]user{}).Statement
+	assert.NoError(t, stmt.Error)
+	assert.Equal(t, "SELECT * FROM `users` WHERE name = ? GROUP BY `users`.`id` HAVING COUNT(orders.id) > ?", stmt.SQL.String())
+	assert.Equal(t, []any{"jo", int64(5)}, stmt.Vars)
+}
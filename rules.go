@@ -0,0 +1,166 @@
+package rqe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Violation is a single broken rule of a filter
+type Violation struct {
+	Rule    string `json:"rule"`
+	Column  string `json:"column"`
+	Message string `json:"message"`
+}
+
+// Rule checks a parsed filter after parsing and returns the violations it finds, see WithRules
+type Rule func(expr Expr) []Violation
+
+// Requires makes filtering on column require every required column to be filtered as well.
+// A required column only counts when it restricts the whole filter (it is not under an `or`).
+func Requires(column string, required ...string) Rule {
+	return func(expr Expr) []Violation {
+		if !references(expr, column) {
+			return nil
+		}
+		var violations []Violation
+		for _, req := range required {
+			if len(conjunctConditions(expr, req)) == 0 {
+				violations = append(violations, Violation{
+					Rule:    "requires",
+					Column:  column,
+					Message: fmt.Sprintf("filtering on '%s' requires filtering on '%s'", column, req),
+				})
+			}
+		}
+		return violations
+	}
+}
+
+// Mandatory makes every column required in every filter, empty filters included.
+// Like Requires, a column only counts when it restricts the whole filter.
+func Mandatory(columns ...string) Rule {
+	return func(expr Expr) []Violation {
+		var violations []Violation
+		for _, col := range columns {
+			if len(conjunctConditions(expr, col)) == 0 {
+				violations = append(violations, Violation{
+					Rule:    "mandatory",
+					Column:  col,
+					Message: fmt.Sprintf("filtering on '%s' is mandatory", col),
+				})
+			}
+		}
+		return violations
+	}
+}
+
+// RequiresRange makes filtering on column require a bounded time range on rangeColumn,
+// both ends must be set and at most max apart
+func RequiresRange(column, rangeColumn string, max time.Duration) Rule {
+	return func(expr Expr) []Violation {
+		if !references(expr, column) {
+			return nil
+		}
+		lower, upper := timeBounds(expr, rangeColumn)
+		if lower == nil || upper == nil || upper.Sub(*lower) > max {
+			return []Violation{{
+				Rule:    "requires_range",
+				Column:  column,
+				Message: fmt.Sprintf("filtering on '%s' requires a range on '%s' of at most %s", column, rangeColumn, max),
+			}}
+		}
+		return nil
+	}
+}
+
+// MaxSpan caps the time range a filter may cover on a date column. Filters on the column must
+// bound it on both ends (between or a gte/lte pair) no more than max apart, open ended ranges are rejected
+func MaxSpan(column string, max time.Duration) Rule {
+	return func(expr Expr) []Violation {
+		if !references(expr, column) {
+			return nil
+		}
+		lower, upper := timeBounds(expr, column)
+		if lower == nil || upper == nil || upper.Sub(*lower) > max {
+			return []Violation{{
+				Rule:    "max_span",
+				Column:  column,
+				Message: fmt.Sprintf("range on '%s' must be bounded and span at most %s", column, max),
+			}}
+		}
+		return nil
+	}
+}
+
+// checkRules runs every rule of the parser and gathers the violations into a single error
+func (p *Parser) checkRules(ctx context.Context, expr Expr) error {
+	violations := Lint(expr, p.rules...)
+	for _, rule := range p.contextRules {
+		violations = append(violations, rule(ctx, expr)...)
+	}
+	if len(violations) > 0 {
+		return RuleViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// references reports whether any condition of the expression uses the column
+func references(expr Expr, column string) bool {
+	found := false
+	Walk(expr, func(c *Condition) {
+		found = found || c.Column == column
+	})
+	return found
+}
+
+// conjunctConditions returns the conditions on column that restrict the whole expression,
+// meaning they are only joined through `and` to the root
+func conjunctConditions(expr Expr, column string) []*Condition {
+	var conds []*Condition
+	for _, c := range conjuncts(expr) {
+		if c.Column == column {
+			conds = append(conds, c)
+		}
+	}
+	return conds
+}
+
+// timeBounds returns the tightest lower and upper time bound the expression puts on the column
+func timeBounds(expr Expr, column string) (lower, upper *time.Time) {
+	for _, c := range conjunctConditions(expr, column) {
+		times := make([]time.Time, 0, len(c.Values))
+		for _, v := range c.Values {
+			if t, ok := asTime(v); ok {
+				times = append(times, t)
+			}
+		}
+		if len(times) != len(c.Values) || len(times) == 0 {
+			continue
+		}
+		if slices.Contains([]string{"gt", "gte", "eq", "between", "from_until"}, c.Operator) && (lower == nil || times[0].After(*lower)) {
+			lower = &times[0]
+		}
+		last := times[len(times)-1]
+		if slices.Contains([]string{"lt", "lte", "eq", "between", "from_until"}, c.Operator) && (upper == nil || last.Before(*upper)) {
+			upper = &last
+		}
+	}
+	return lower, upper
+}
+
+// asTime converts time values and date strings
+func asTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		for _, layout := range asOfLayouts {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,100 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules(t *testing.T) {
+	parser := NewParser(WithRules(
+		Requires("amount", "date"),
+		RequiresRange("amount", "date", 90*24*time.Hour),
+	))
+
+	valid := []string{
+		`name eq "x"`,
+		`amount gt 5 and date between ["2024-01-01", "2024-03-01"]`,
+		`amount gt 5 and date gte "2024-01-01" and date lt "2024-02-01"`,
+		`amount gt 5 and date gte "now-30d" and date lte "now"`,
+	}
+	for _, filter := range valid {
+		_, err := parser.Parse(filter, validateColumn)
+		assert.NoError(t, err, filter)
+	}
+
+	_, err := parser.Parse(`amount gt 5`, validateColumn)
+	assert.IsType(t, RuleViolationError{}, err)
+	assert.Len(t, err.(RuleViolationError).Violations, 2)
+
+	invalid := []string{
+		`amount gt 5 and date gte "2024-01-01"`,
+		`amount gt 5 and date between ["2023-01-01", "2024-01-01"]`,
+		`amount gt 5 or date between ["2024-01-01", "2024-03-01"]`,
+	}
+	for _, filter := range invalid {
+		_, err := parser.Parse(filter, validateColumn)
+		assert.IsType(t, RuleViolationError{}, err, filter)
+	}
+}
+
+func TestMandatoryColumns(t *testing.T) {
+	parser := NewParser(WithMandatoryColumns("tenant_id", "created_at"))
+
+	_, err := parser.Parse(`tenant_id eq 1 and created_at gte "now-1d" and name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+
+	for filter, missing := range map[string]int{
+		``:                                  2,
+		`tenant_id eq 1`:                    1,
+		`tenant_id eq 1 or created_at gt 1`: 2,
+		`(tenant_id eq 1 and created_at gt 1) or name eq "x"`: 2,
+	} {
+		_, err = parser.Parse(filter, validateColumn)
+		assert.IsType(t, RuleViolationError{}, err, filter)
+		assert.Len(t, err.(RuleViolationError).Violations, missing, filter)
+	}
+}
+
+func TestMaxSpan(t *testing.T) {
+	parser := NewParser(WithMaxSpan("date", 31*24*time.Hour))
+
+	valid := []string{
+		`name eq "x"`,
+		`date eq "2024-01-01"`,
+		`date between ["2024-01-01", "2024-01-31"]`,
+		`date gte "2024-01-01" and date lte "2024-02-01"`,
+		`date gte "now-7d" and date lt "now"`,
+	}
+	for _, filter := range valid {
+		_, err := parser.Parse(filter, validateColumn)
+		assert.NoError(t, err, filter)
+	}
+
+	invalid := []string{
+		`date gte "2024-01-01"`,
+		`date between ["2024-01-01", "2024-03-01"]`,
+		`date gte "2024-01-01" and date lte "2024-06-01"`,
+		`date gte "2024-01-01" or date lte "2024-01-02"`,
+	}
+	for _, filter := range invalid {
+		_, err := parser.Parse(filter, validateColumn)
+		assert.IsType(t, RuleViolationError{}, err, filter)
+	}
+}
+
+func TestBuilderRules(t *testing.T) {
+	parser := NewParser(WithMandatoryColumns("tenant_id"), WithMaxSpan("date", 31*24*time.Hour))
+
+	// the rules see the fragments as a single filter
+	q, err := parser.Begin(validateColumn).Filter(`tenant_id eq 1`).Filter(`date gte "2024-01-01"`).Filter(`date lte "2024-01-31"`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = ?) AND (date >= ?) AND (date <= ?)", q.Where)
+
+	_, err = parser.Begin(validateColumn).Filter(`tenant_id eq 1`).Filter(`date gte "2024-01-01"`).Finish()
+	assert.Equal(t, RuleViolationError{Violations: []Violation{{Rule: "max_span", Column: "date", Message: "range on 'date' must be bounded and span at most 744h0m0s"}}}, err)
+
+	_, err = parser.Begin(validateColumn).Filter(`name eq "x"`).Finish()
+	assert.IsType(t, RuleViolationError{}, err)
+}
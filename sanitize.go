@@ -0,0 +1,74 @@
+package rqe
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Sanitizer transforms a literal value of an operation before it is bound as an argument
+type Sanitizer func(val any) any
+
+// TrimSpace removes leading and trailing white space from string values.
+// Other values are returned untouched.
+func TrimSpace(val any) any {
+	if s, ok := val.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return val
+}
+
+// StripWildcards removes the LIKE wildcards `%` and `_` from string values so
+// clients cannot widen a pattern search. Other values are returned untouched.
+func StripWildcards(val any) any {
+	if s, ok := val.(string); ok {
+		return strings.NewReplacer("%", "", "_", "").Replace(s)
+	}
+	return val
+}
+
+// validateString accepts string values only
+func validateString(val any) error {
+	if _, ok := val.(string); !ok {
+		return fmt.Errorf("%v is not a string", val)
+	}
+	return nil
+}
+
+// validatePrefix accepts non empty strings
+func validatePrefix(val any) error {
+	if err := validateString(val); err != nil {
+		return err
+	}
+	if val == "" {
+		return fmt.Errorf("prefix cannot be empty")
+	}
+	return nil
+}
+
+// maxEdits caps the distance of within_edits, larger ones match nearly everything
+const maxEdits = 5
+
+// normalizeEdits checks the `[value, distance]` pair of within_edits and binds the distance as an integer
+func normalizeEdits(vals []any) ([]any, error) {
+	if err := validateString(vals[0]); err != nil {
+		return nil, err
+	}
+	n, ok := vals[1].(float64)
+	if !ok || n != float64(int64(n)) || n < 0 || n > maxEdits {
+		return nil, fmt.Errorf("distance %v must be a whole number between 0 and %d", vals[1], maxEdits)
+	}
+	return []any{vals[0], int64(n)}, nil
+}
+
+// validateCIDR accepts strings holding a network prefix such as `10.0.0.0/8`
+func validateCIDR(val any) error {
+	s, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("%v is not a CIDR string", val)
+	}
+	if _, err := netip.ParsePrefix(s); err != nil {
+		return fmt.Errorf("%q is not a valid CIDR", s)
+	}
+	return nil
+}
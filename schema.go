@@ -0,0 +1,205 @@
+package rqe
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// ColumnCapability is what the API lets clients do with a column, combine them with `|`
+type ColumnCapability uint8
+
+const (
+	// Filterable columns can be compared in filters
+	Filterable ColumnCapability = 1 << iota
+	// Sortable columns can be ordered on
+	Sortable
+	// Searchable columns accept the pattern / text operations (`like`, `contains`, `prefix` ...)
+	Searchable
+	// Projectable columns can be requested in the fields list
+	Projectable
+)
+
+// AllCapabilities exposes a column for everything
+const AllCapabilities = Filterable | Sortable | Searchable | Projectable
+
+// searchOperations are the operations a column must be Searchable for
+var searchOperations = map[string]struct{}{
+	"sounds_like":  {},
+	"like":         {},
+	"ilike":        {},
+	"regex":        {},
+	"contains":     {},
+	"prefix":       {},
+	"within_edits": {},
+}
+
+// ColumnType is the type of a column's values
+type ColumnType string
+
+const (
+	// TypeAny accepts any literal
+	TypeAny    ColumnType = ""
+	TypeString ColumnType = "string"
+	TypeInt    ColumnType = "int"
+	TypeFloat  ColumnType = "float"
+	TypeBool   ColumnType = "bool"
+	TypeDate   ColumnType = "date"
+	// TypeEnum columns take a string of their Enum values
+	TypeEnum ColumnType = "enum"
+)
+
+// Column describes a column exposed by the API
+type Column struct {
+	Capabilities ColumnCapability
+	Type         ColumnType
+	// DBName is the column used in the generated SQL, the schema key when empty
+	DBName string
+	// Operators restricts the canonical operations the column accepts, any when empty
+	Operators []string
+	// Enum is the set of values the column accepts, any when empty
+	Enum []string
+	// Validators run on every literal of the column once coerced, e.g. rqe.MaxLength(64)
+	Validators []ValueValidator
+	// NotNull columns never hold null, comparing them against `null` is rejected
+	NotNull bool
+	// Expression makes the column virtual, it is compiled to this trusted SQL expression instead
+	// of a column, e.g. `CONCAT(first_name, ' ', last_name)` for a `full_name` column
+	Expression string
+	// Aggregate marks the Expression as an aggregate (`COUNT(orders.id)`), the conditions on the
+	// column are compiled into ParsedQuery.Having rather than the WHERE clause
+	Aggregate bool
+	// TimeZone is the zone the column stores naive times in, date values are converted to it
+	// from the client's zone (see WithClientTimeZone) and bound as `2006-01-02 15:04:05`
+	TimeZone *time.Location
+	// Layouts are the time layouts TypeDate values are accepted in (time.RFC3339, time.DateOnly,
+	// LayoutUnix), tried in order. Dates are then bound as UTC times whatever their layout.
+	// RFC 3339, date time and date only strings in the client's zone are accepted when empty.
+	Layouts []string
+	// Collation is appended to the column in its comparisons as `COLLATE <name>`, e.g.
+	// `utf8mb4_0900_ai_ci` for case and accent insensitive equality on MySQL. It is trusted SQL.
+	Collation string
+	// CaseInsensitive compares the column and its values through `LOWER()`
+	CaseInsensitive bool
+}
+
+// LayoutUnix is a Column layout accepting seconds since the Unix epoch, `created_at gte 1704153600`
+const LayoutUnix = "unix"
+
+// Schema is the single source of truth of what an API exposes, by column name. Columns that
+// are not declared cannot be filtered, sorted or projected. Use it with WithSchema.
+//
+//	schema := rqe.Schema{
+//		"name":       {Capabilities: rqe.Filterable | rqe.Searchable | rqe.Projectable},
+//		"created_at": {Capabilities: rqe.Filterable | rqe.Sortable | rqe.Projectable},
+//	}
+type Schema map[string]Column
+
+// Can reports whether the column is declared with the capability
+func (s Schema) Can(col string, capability ColumnCapability) bool {
+	c, ok := s[col]
+	return ok && c.Capabilities&capability == capability
+}
+
+// filterable reports whether the column can appear in a filter at all
+func (s Schema) filterable(col string) bool {
+	return s.Can(col, Filterable) || s.Can(col, Searchable)
+}
+
+// allows reports whether the operation can be used on the column, search operations need
+// Searchable while every other comparison needs Filterable
+func (s Schema) allows(col, operation string) bool {
+	return s.restricts(col, operation) && s.capable(col, operation)
+}
+
+// restricts reports whether the column's Operators let the operation through
+func (s Schema) restricts(col, operation string) bool {
+	ops := s[col].Operators
+	return len(ops) == 0 || slices.Contains(ops, operation)
+}
+
+// capable reports whether the column has the capability the operation needs, integer only
+// operations (`band`, `bor`) also need an integer or untyped column
+func (s Schema) capable(col, operation string) bool {
+	if t := s[col].Type; operationsMapped[operation].IntegerOnly && t != TypeAny && t != TypeInt {
+		return false
+	}
+	if _, search := searchOperations[operation]; search {
+		return s.Can(col, Searchable)
+	}
+	return s.Can(col, Filterable)
+}
+
+// Validator adapts the schema to the `validateCol` callbacks, a column is valid when it is filterable
+func (s Schema) Validator() func(col string) bool {
+	return s.filterable
+}
+
+// allowsNull reports whether the column can be compared against `null` with the operation
+func (p *Parser) allowsNull(col string, op OperationMeta) bool {
+	return op.NullValue != "" && !p.schema[col].NotNull
+}
+
+// dbName is the SQL column of an API column
+func (s Schema) dbName(col string) string {
+	if name := s[col].DBName; name != "" {
+		return name
+	}
+	return col
+}
+
+// ParseSort parses a sort list such as `name,-created_at` (`-` sorts descending), every
+// column must be Sortable
+func (s Schema) ParseSort(spec string) ([]Sort, error) {
+	sorts := make([]Sort, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sort := Sort{Column: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !s.Can(sort.Column, Sortable) {
+			return nil, SortColumnError{Column: sort.Column, Reason: "column is not sortable"}
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// ParseFields parses a comma separated projection such as `id,name`, every column must be Projectable
+func (s Schema) ParseFields(spec string) ([]string, error) {
+	fields := make([]string, 0)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !s.Can(field, Projectable) {
+			return nil, FieldColumnError{Column: field, Reason: "column is not projectable"}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// ParseSchema parses the filter against the schema instead of a validateCol callback, it
+// validates the columns, their operators and values with the default parser's configuration
+func ParseSchema(filter string, schema Schema, opts ...Option) (ParsedQuery, error) {
+	return withDefaults(append(opts, WithSchema(schema))).Parse(filter, nil)
+}
+
+// columnValidator combines validateCol with the schema and the deny-list, either may be missing
+func (p *Parser) columnValidator(validateCol func(col string) bool) func(col string) bool {
+	if p.schema == nil && len(p.deniedColumns) == 0 {
+		return validateCol
+	}
+	return func(col string) bool {
+		if p.denied(col) {
+			return false
+		}
+		if p.schema != nil {
+			return p.schema.filterable(col) && (validateCol == nil || validateCol(col))
+		}
+		return validateCol != nil && validateCol(col)
+	}
+}
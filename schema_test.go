@@ -0,0 +1,192 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var capabilitySchema = Schema{
+	"name":       {Capabilities: Filterable | Searchable | Projectable},
+	"bio":        {Capabilities: Searchable},
+	"created_at": {Capabilities: Filterable | Sortable},
+	"id":         {Capabilities: AllCapabilities},
+}
+
+func TestSchemaCapabilities(t *testing.T) {
+	p := NewParser(WithSchema(capabilitySchema))
+
+	q, err := p.Parse(`name eq "bob" and bio contains "go" and created_at gt 3`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ? and bio LIKE ? ESCAPE '!' and created_at > ?", q.SQL)
+
+	_, err = p.Parse(`password eq "x"`, nil)
+	assert.Equal(t, InvalidColumnError{Column: "password", Line: 1, Pos: 0}, err)
+
+	// searchable only columns cannot be compared, filterable only ones cannot be searched
+	_, err = p.Parse(`bio eq "x"`, nil)
+	assert.Equal(t, InvalidOperationError{Operation: "eq", Column: "bio", Line: 1, Pos: 3}, err)
+	_, err = p.Parse(`created_at like "2024%"`, nil)
+	assert.Equal(t, InvalidOperationError{Operation: "like", Column: "created_at", Line: 1, Pos: 10}, err)
+
+	// validateCol still applies on top of the schema
+	_, err = p.Parse(`name eq "bob"`, func(col string) bool { return col != "name" })
+	assert.IsType(t, InvalidColumnError{}, err)
+}
+
+func TestSchemaSortAndFields(t *testing.T) {
+	sorts, err := capabilitySchema.ParseSort("created_at, -id")
+	assert.NoError(t, err)
+	assert.Equal(t, []Sort{{Column: "created_at"}, {Column: "id", Desc: true}}, sorts)
+	_, err = capabilitySchema.ParseSort("-name")
+	assert.Equal(t, SortColumnError{Column: "name", Reason: "column is not sortable"}, err)
+
+	fields, err := capabilitySchema.ParseFields("id,name")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, fields)
+	_, err = capabilitySchema.ParseFields("id,created_at")
+	assert.Equal(t, FieldColumnError{Column: "created_at", Reason: "column is not projectable"}, err)
+
+	p := NewParser(WithSchema(capabilitySchema))
+	built, err := p.Begin(nil).Filter(`name eq "bob"`).OrderBy("created_at", true).Select("id", "name").Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "created_at DESC", built.OrderBy)
+	assert.Equal(t, []string{"id", "name"}, built.Fields)
+
+	_, err = p.Begin(nil).OrderBy("name", false).Finish()
+	assert.IsType(t, SortColumnError{}, err)
+	_, err = p.Begin(nil).Select("bio").Finish()
+	assert.IsType(t, FieldColumnError{}, err)
+}
+
+func TestParseSchema(t *testing.T) {
+	schema := Schema{
+		"id":         {Capabilities: Filterable, Type: TypeInt, NotNull: true},
+		"deleted_at": {Capabilities: Filterable, Type: TypeDate},
+	}
+
+	q, err := ParseSchema(`id gt 3 and deleted_at eq null`, schema, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "id > $1 and deleted_at IS NULL", q.SQL)
+
+	_, err = ParseSchema(`id eq null`, schema)
+	assert.Equal(t, InvalidOperationError{Operation: "eq null", Column: "id", Line: 1, Pos: 2}, err)
+	_, err = NewParser(WithSchema(schema)).ParseOData(`id eq null`, nil)
+	assert.IsType(t, InvalidOperationError{}, err)
+	_, err = ParseJSON([]byte(`{"id": null}`), schema)
+	assert.IsType(t, InvalidOperationError{}, err)
+
+	_, err = ParseSchema(`name eq "x"`, schema)
+	assert.Equal(t, InvalidColumnError{Column: "name", Line: 1, Pos: 0}, err)
+
+	// the validator adapts the schema to APIs taking a callback
+	validate := schema.Validator()
+	assert.True(t, validate("deleted_at"))
+	assert.False(t, validate("name"))
+}
+
+func TestSchemaColumnMapping(t *testing.T) {
+	schema := Schema{
+		"firstName": {Capabilities: AllCapabilities, DBName: "users.first_name"},
+		"age":       {Capabilities: Filterable},
+	}
+	p := NewParser(MSSQL, WithSchema(schema), WithHardened())
+
+	q, err := p.Parse(`firstName eq "Jo" and age gt 3`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[users].[first_name] = @p1 and [age] > @p2", q.SQL)
+	assert.Equal(t, []string{"firstName", "age"}, q.Columns)
+
+	_, err = p.Parse(`first_name eq "Jo"`, nil)
+	assert.Equal(t, InvalidColumnError{Column: "first_name", Line: 1, Pos: 0}, err)
+
+	built, err := p.Begin(nil).OrderBy("firstName", false).Select("firstName").Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "[users].[first_name] ASC", built.OrderBy)
+	assert.Equal(t, []string{"[users].[first_name]"}, built.Fields)
+}
+
+func TestSchemaOperators(t *testing.T) {
+	schema := Schema{
+		"status":     {Capabilities: Filterable, Operators: []string{"eq", "in"}},
+		"created_at": {Capabilities: Filterable, Operators: []string{"gt", "gte", "lt", "lte", "between"}},
+	}
+	p := NewParser(WithSchema(schema))
+
+	_, err := p.Parse(`status in ["open", "closed"] and created_at gte "2024-01-01"`, nil)
+	assert.NoError(t, err)
+
+	_, err = p.Parse(`created_at eq "2024-01-01"`, nil)
+	assert.Equal(t, OperatorNotAllowedError{Operation: "eq", Column: "created_at", Allowed: []string{"gt", "gte", "lt", "lte", "between"}, Line: 1, Pos: 10}, err)
+
+	_, err = p.ParseJSON([]byte(`{"status": {"ne": "open"}}`), nil)
+	assert.IsType(t, OperatorNotAllowedError{}, err)
+
+	// bitwise operations need an integer column
+	typed := NewParser(WithSchema(Schema{
+		"name":  {Capabilities: Filterable, Type: TypeString},
+		"flags": {Capabilities: Filterable, Type: TypeInt},
+	}))
+	_, err = typed.Parse(`name band 4`, nil)
+	assert.Equal(t, InvalidOperationError{Operation: "band", Column: "name", Line: 1, Pos: 4}, err)
+	_, err = typed.Parse(`flags bor 4`, nil)
+	assert.NoError(t, err)
+}
+
+func TestTableAlias(t *testing.T) {
+	schema := Schema{
+		"name":    {Capabilities: Filterable | Sortable},
+		"total":   {Capabilities: Filterable},
+		"country": {Capabilities: Filterable, DBName: "addresses.country"},
+	}
+	p := NewParser(Postgres, WithSchema(schema), WithTableAlias("u"), WithColumnTable("total", "o"), WithHardened())
+
+	q, err := p.Parse(`name eq "Jo" and total gt 10 and country eq "FR"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "u.name = $1 and o.total > $2 and addresses.country = $3", q.SQL)
+
+	built, err := p.Begin(nil).OrderBy("name", true).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "u.name DESC", built.OrderBy)
+
+	q, err = Parse(`name eq "Jo"`, validateColumn, MSSQL, WithTableAlias("u"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[u].[name] = @p1", q.SQL)
+}
+
+func TestVirtualColumns(t *testing.T) {
+	schema := Schema{
+		"full_name": {Capabilities: AllCapabilities, Expression: "CONCAT(first_name, ' ', last_name)"},
+		"age":       {Capabilities: Filterable},
+	}
+	p := NewParser(Postgres, WithSchema(schema), WithTableAlias("u"), WithHardened())
+
+	q, err := p.Parse(`full_name contains "Jo" and age gt 3`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT(first_name, ' ', last_name) LIKE $1 ESCAPE '!' and u.age > $2", q.SQL)
+	assert.Equal(t, []any{"%Jo%", int64(3)}, q.Args)
+
+	built, err := p.Begin(nil).OrderBy("full_name", false).Select("full_name").Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "CONCAT(first_name, ' ', last_name) ASC", built.OrderBy)
+	assert.Equal(t, []string{"CONCAT(first_name, ' ', last_name) AS full_name"}, built.Fields)
+}
+
+func TestColumnCollation(t *testing.T) {
+	schema := Schema{
+		"name":  {Capabilities: Filterable | Searchable, Collation: "utf8mb4_0900_ai_ci"},
+		"email": {Capabilities: Filterable, CaseInsensitive: true},
+	}
+	p := NewParser(MySQL, WithSchema(schema), WithHardened())
+
+	q, err := p.Parse(`name eq "Jose" and email in ["A@b.io", "c@d.io"] and name like "J%"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "name COLLATE utf8mb4_0900_ai_ci = ? and LOWER(email) IN (LOWER(?), LOWER(?)) and name COLLATE utf8mb4_0900_ai_ci LIKE ?", q.SQL)
+	assert.Equal(t, []any{"Jose", "A@b.io", "c@d.io", "J%"}, q.Args)
+
+	pred, err := p.Predicate(`email eq "A@B.io"`, nil)
+	assert.NoError(t, err)
+	ok, err := pred.Match(map[string]any{"email": "a@b.IO"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,45 @@
+package rqe
+
+import (
+	"context"
+	"slices"
+)
+
+// Scope builds a condition every filter of the parser must be restricted by, typically from
+// request scoped values of the context (tenant, owner ...), see WithScopes
+type Scope func(ctx context.Context) (Expr, error)
+
+// ScopeFromContext scopes filters to `column eq value`, value being stored in the context under
+// key. Parsing fails with a MissingScopeError when the context holds no value, so a request
+// cannot run unscoped.
+func ScopeFromContext(column string, key any) Scope {
+	return func(ctx context.Context) (Expr, error) {
+		v := ctx.Value(key)
+		if v == nil {
+			return nil, MissingScopeError{Column: column}
+		}
+		return &Condition{Column: column, Operator: "eq", Values: []any{v}}, nil
+	}
+}
+
+// applyScopes ANDs the scopes of the parser around the client's expression
+func (p *Parser) applyScopes(ctx context.Context, expr Expr) (Expr, error) {
+	if len(p.scopes) == 0 {
+		return expr, nil
+	}
+	exprs := make([]Expr, 0, len(p.scopes)+1)
+	for _, scope := range p.scopes {
+		scoped, err := scope(ctx)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, scoped)
+	}
+	if expr != nil {
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &Logical{Operator: "and", Exprs: slices.Clip(exprs)}, nil
+}
@@ -0,0 +1,56 @@
+package rqe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scopeKey struct{}
+
+func TestScopes(t *testing.T) {
+	p := NewParser(Postgres, WithScopes(ScopeFromContext("tenant_id", scopeKey{})))
+	ctx := context.WithValue(context.Background(), scopeKey{}, int64(7))
+
+	q, err := p.ParseContext(ctx, `name eq "Jo" or age gt 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant_id = $1 and (name = $2 or age > $3)", q.SQL)
+	assert.Equal(t, []any{int64(7), "Jo", int64(3)}, q.Args)
+
+	// empty filters are scoped too
+	q, err = p.ParseContext(ctx, ``, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant_id = $1", q.SQL)
+
+	_, err = p.Parse(`name eq "Jo"`, validateColumn)
+	assert.Equal(t, MissingScopeError{Column: "tenant_id"}, err)
+	_, err = p.ParseJSON([]byte(`{"name": "Jo"}`), validateColumn)
+	assert.Equal(t, MissingScopeError{Column: "tenant_id"}, err)
+
+	static := func(context.Context) (Expr, error) {
+		return &Condition{Column: "deleted_at", Operator: "eq", Values: []any{nil}}, nil
+	}
+	q, err = NewParser(WithScopes(static)).ParseOData(`age gt 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at IS NULL and age > ?", q.SQL)
+}
+
+func TestBuilderScopes(t *testing.T) {
+	p := NewParser(Postgres, WithScopes(ScopeFromContext("tenant_id", scopeKey{})))
+	ctx := context.WithValue(context.Background(), scopeKey{}, int64(7))
+
+	built, err := p.BeginContext(ctx, validateColumn).Filter(`a eq 1`).Filter(`b eq 2`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = $1) AND (a = $2) AND (b = $3)", built.Where)
+	assert.Equal(t, []any{int64(7), int64(1), int64(2)}, built.Args)
+	assert.Equal(t, []string{"a", "b"}, built.Columns)
+
+	// queries without filters are scoped too
+	built, err = p.BeginContext(ctx, validateColumn).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(tenant_id = $1)", built.Where)
+
+	_, err = p.Begin(validateColumn).Finish()
+	assert.Equal(t, MissingScopeError{Column: "tenant_id"}, err)
+}
@@ -0,0 +1,66 @@
+package rqe
+
+import "slices"
+
+// ShardKeys returns the values the filter pins the shard key column to, so a caller
+// can route the query to the shards owning them.
+//
+// The result is nil when the filter can match rows of any shard, for example when the
+// column is missing, compared with a range or only present in one branch of an `or`.
+func ShardKeys(expr Expr, column string) []any {
+	keys, _ := shardKeys(expr, column)
+	return keys
+}
+
+func shardKeys(expr Expr, column string) ([]any, bool) {
+	switch e := expr.(type) {
+	case *Condition:
+		if e.Column != column || e.IsNull() || (e.Operator != "eq" && e.Operator != "in") {
+			return nil, false
+		}
+		return slices.Clone(e.Values), true
+	case *Logical:
+		var keys []any
+		pinned := false
+		for _, child := range e.Exprs {
+			childKeys, childPinned := shardKeys(child, column)
+			switch {
+			case e.Operator == "or" && !childPinned:
+				return nil, false
+			case e.Operator == "or":
+				keys = appendUnique(keys, childKeys...)
+				pinned = true
+			case !childPinned:
+				continue
+			case !pinned:
+				keys, pinned = childKeys, true
+			default: // every pinned branch of an `and` must agree
+				keys = slices.DeleteFunc(keys, func(k any) bool { return !containsKey(childKeys, k) })
+			}
+		}
+		return keys, pinned
+	default:
+		return nil, false
+	}
+}
+
+func appendUnique(dst []any, vals ...any) []any {
+	for _, v := range vals {
+		if !containsKey(dst, v) {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+// containsKey reports whether keys holds key, numbers compare whatever their Go type
+// (`eq 4` decodes an int64, `in [4]` a float64)
+func containsKey(keys []any, key any) bool {
+	return slices.ContainsFunc(keys, func(k any) bool {
+		if x, ok := toFloat64(k); ok {
+			y, ok := toFloat64(key)
+			return ok && x == y
+		}
+		return k == key
+	})
+}
@@ -0,0 +1,36 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardKeys(t *testing.T) {
+	tests := map[string][]any{
+		`tenant_id eq 4`:                                 {int64(4)},
+		`tenant_id in [4, 5] and name eq "x"`:            {float64(4), float64(5)},
+		`tenant_id eq 4 or tenant_id eq 5`:               {int64(4), int64(5)},
+		`tenant_id in [4, 5] and tenant_id in [5, 6]`:    {float64(5)},
+		`(tenant_id eq 4 and a eq 1) or tenant_id eq 4`:  {int64(4)},
+		`tenant_id eq 4 and tenant_id in [4, 5]`:         {int64(4)},
+		`tenant_id eq 4 or tenant_id in [4, 5]`:          {int64(4), float64(5)},
+		`tenant_id eq 4 or name eq "x"`:                  nil,
+		`tenant_id gt 4`:                                 nil,
+		`tenant_id eq null`:                              nil,
+		`name eq "x"`:                                    nil,
+		`(name eq "x" or a eq 1) and tenant_id eq "abc"`: {"abc"},
+	}
+	parser := NewParser(WithShardKey("tenant_id"))
+	for filter, expected := range tests {
+		t.Run(filter, func(t *testing.T) {
+			q, err := parser.Parse(filter, validateColumn)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, q.ShardKeys)
+		})
+	}
+
+	q, err := Parse(`tenant_id eq 4`, validateColumn)
+	assert.NoError(t, err)
+	assert.Nil(t, q.ShardKeys)
+}
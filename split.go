@@ -0,0 +1,83 @@
+package rqe
+
+// SplitFilter is a filter divided between the tables (or stores) owning its columns
+type SplitFilter struct {
+	// Tables holds the part of the filter each table can evaluate on its own
+	Tables map[string]Expr
+	// Residual is what has to be evaluated after stitching the rows together,
+	// nil when every part of the filter could be pushed down
+	Residual Expr
+}
+
+// Split divides the filter so each table gets the predicates it can evaluate by itself.
+// tables maps every column to the table owning it.
+//
+// Only the operands of the top level `and` are pushed down, an operand goes to a table when
+// all of its columns belong to that table (`a eq 1 or a eq 2` is kept whole). Operands mixing
+// tables, using unknown columns or `asof` end up in the residual. Rows matching the filter are
+// exactly the rows matching every table predicate and the residual.
+func Split(expr Expr, tables map[string]string) SplitFilter {
+	split := SplitFilter{Tables: make(map[string]Expr)}
+	var residual []Expr
+
+	for _, operand := range andOperands(expr) {
+		table, ok := ownerTable(operand, tables)
+		if !ok {
+			residual = append(residual, operand)
+			continue
+		}
+		split.Tables[table] = joinAnd(split.Tables[table], operand)
+	}
+	for _, operand := range residual {
+		split.Residual = joinAnd(split.Residual, operand)
+	}
+	return split
+}
+
+// andOperands flattens the top level `and` of the expression
+func andOperands(expr Expr) []Expr {
+	if expr == nil {
+		return nil
+	}
+	l, ok := expr.(*Logical)
+	if !ok || l.Operator != "and" {
+		return []Expr{expr}
+	}
+	var operands []Expr
+	for _, child := range l.Exprs {
+		operands = append(operands, andOperands(child)...)
+	}
+	return operands
+}
+
+// ownerTable returns the single table owning every column of the expression
+func ownerTable(expr Expr, tables map[string]string) (string, bool) {
+	if _, ok := expr.(*AsOf); ok {
+		return "", false
+	}
+	owner, ok := "", true
+	Walk(expr, func(c *Condition) {
+		table, known := tables[c.Column]
+		switch {
+		case !known || (owner != "" && table != owner):
+			ok = false
+		default:
+			owner = table
+		}
+	})
+	return owner, ok && owner != ""
+}
+
+// joinAnd appends next to the `and` of expr, expr may be nil
+func joinAnd(expr Expr, next Expr) Expr {
+	switch e := expr.(type) {
+	case nil:
+		return next
+	case *Logical:
+		if e.Operator == "and" {
+			e.Exprs = append(e.Exprs, next)
+			return e
+		}
+	}
+	return &Logical{Operator: "and", Exprs: []Expr{expr, next}}
+}
@@ -0,0 +1,36 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tables := map[string]string{
+		"name":     "users",
+		"age":      "users",
+		"total":    "orders",
+		"status":   "orders",
+		"category": "products",
+	}
+	compile := NewParser().Compile
+
+	split := Split(mustParse(t, `name eq "x" and total gt 5 and (status eq "paid" or status eq "sent") and age gte 18 and (age lt 5 or total lt 1) and sku eq 3`), tables)
+	assert.Len(t, split.Tables, 2)
+	assert.Equal(t, "name = ? and age >= ?", compile(split.Tables["users"]).SQL)
+	assert.Equal(t, "total > ? and (status = ? or status = ?)", compile(split.Tables["orders"]).SQL)
+	assert.Equal(t, "(age < ? or total < ?) and sku = ?", compile(split.Residual).SQL)
+
+	split = Split(mustParse(t, `category eq "books"`), tables)
+	assert.Equal(t, "category = ?", compile(split.Tables["products"]).SQL)
+	assert.Nil(t, split.Residual)
+
+	split = Split(mustParse(t, `name eq "x" or total gt 5`), tables)
+	assert.Empty(t, split.Tables)
+	assert.Equal(t, "name = ? or total > ?", compile(split.Residual).SQL)
+
+	split = Split(nil, tables)
+	assert.Empty(t, split.Tables)
+	assert.Nil(t, split.Residual)
+}
@@ -0,0 +1,69 @@
+// Package sqlxadapter runs rqe filters through sqlx
+//
+//	q, err := rqe.Parse(filter, validateCol)
+//	err = sqlxadapter.Select(ctx, db, &users, "SELECT * FROM users", q)
+package sqlxadapter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/baderkha/rqe"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrHaving is returned for filters on aggregate columns (rqe.Column.Aggregate), their HAVING
+// clause must follow the query's GROUP BY which Where cannot place
+var ErrHaving = errors.New("sqlxadapter: filters with a HAVING clause are not supported")
+
+// Binder rebinds `?` queries for a driver, satisfied by *sqlx.DB and *sqlx.Tx
+type Binder interface {
+	Rebind(query string) string
+}
+
+// Bind returns the filter's SQL and arguments ready for db. Queries compiled with
+// rqe.WithNamedArgs go through sqlx.Named, others through sqlx.In so slice arguments (e.g. long
+// IN lists bound as a single slice by a custom operator) are expanded to one placeholder per
+// element. The result is rebound to db's placeholders, compile the filter with the default `?`
+// placeholders and not a dialect's.
+func Bind(db Binder, q rqe.ParsedQuery) (string, []any, error) {
+	var (
+		query string
+		args  []any
+		err   error
+	)
+	if q.Having != "" {
+		return "", nil, ErrHaving
+	}
+	if q.NamedArgs != nil {
+		query, args, err = sqlx.Named(q.SQL, q.NamedArgs)
+	} else {
+		query, args, err = sqlx.In(q.SQL, q.Args...)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return db.Rebind(query), args, nil
+}
+
+// Where appends the filter to query as its WHERE clause and binds it, see Bind.
+// query is returned as is when the filter is empty.
+func Where(db Binder, query string, q rqe.ParsedQuery) (string, []any, error) {
+	if q.SQL == "" && q.Having == "" {
+		return query, nil, nil
+	}
+	where, args, err := Bind(db, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return query + " WHERE " + where, args, nil
+}
+
+// Select runs query filtered by q and scans the rows into dest, see Where
+func Select(ctx context.Context, db sqlx.ExtContext, dest any, query string, q rqe.ParsedQuery) error {
+	query, args, err := Where(db, query, q)
+	if err != nil {
+		return err
+	}
+	return sqlx.SelectContext(ctx, db, dest, query, args...)
+}
@@ -0,0 +1,49 @@
+package sqlxadapter
+
+import (
+	"testing"
+
+	"github.com/baderkha/rqe"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func validateColumn(string) bool {
+	return true
+}
+
+func TestBind(t *testing.T) {
+	db := sqlx.NewDb(nil, "postgres")
+
+	q, err := rqe.Parse(`name eq "bob" and status in ["a", "b"]`, validateColumn)
+	assert.NoError(t, err)
+	query, args, err := Bind(db, q)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = $1 and status IN ($2, $3)", query)
+	assert.Equal(t, []any{"bob", "a", "b"}, args)
+
+	named, err := rqe.Parse(`name eq "bob" and age gt 3`, validateColumn, rqe.WithNamedArgs())
+	assert.NoError(t, err)
+	query, args, err = Bind(db, named)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = $1 and age > $2", query)
+	assert.Equal(t, []any{"bob", int64(3)}, args)
+
+	// slice arguments are expanded by sqlx.In
+	query, args, err = Bind(sqlx.NewDb(nil, "mysql"), rqe.ParsedQuery{SQL: "id IN (?) and age > ?", Args: []any{[]int{1, 2, 3}, 18}})
+	assert.NoError(t, err)
+	assert.Equal(t, "id IN (?, ?, ?) and age > ?", query)
+	assert.Equal(t, []any{1, 2, 3, 18}, args)
+
+	query, args, err = Where(db, "SELECT * FROM users", q)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE name = $1 and status IN ($2, $3)", query)
+	assert.Len(t, args, 3)
+
+	query, args, err = Where(db, "SELECT * FROM users", rqe.ParsedQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users", query)
+	assert.Empty(t, args)
+	_, _, err = Where(db, "SELECT * FROM users", rqe.ParsedQuery{Having: "COUNT(orders.id) > ?", Args: []any{1}, HavingArgs: []any{1}})
+	assert.ErrorIs(t, err, ErrHaving)
+}
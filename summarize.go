@@ -0,0 +1,117 @@
+package rqe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// summaryPhrases is how each operation reads in a sentence
+var summaryPhrases = map[string]string{
+	"eq":           "is",
+	"nseq":         "is",
+	"ne":           "is not",
+	"lt":           "is less than",
+	"lte":          "is at most",
+	"gt":           "is greater than",
+	"gte":          "is at least",
+	"in":           "is one of",
+	"nin":          "is none of",
+	"between":      "is between",
+	"nbetween":     "is not between",
+	"from_until":   "is from",
+	"band":         "has any of the flags",
+	"bor":          "has only the flags",
+	"sounds_like":  "sounds like",
+	"in_subnet":    "is in the network",
+	"like":         "matches",
+	"ilike":        "matches (ignoring case)",
+	"regex":        "matches the pattern",
+	"contains":     "contains",
+	"prefix":       "starts with",
+	"within_edits": "is close to",
+}
+
+// Summarize renders the filter as a human sentence, e.g. `name contains "smith" and age gte 25`
+// reads "name contains 'smith' and age is at least 25". labels replaces column names with the
+// label shown to users, columns without a label keep their name.
+func Summarize(expr Expr, labels map[string]string) string {
+	return summarize(expr, labels, false)
+}
+
+func summarize(expr Expr, labels map[string]string, nested bool) string {
+	label := func(col string) string {
+		if l, ok := labels[col]; ok {
+			return l
+		}
+		return col
+	}
+
+	switch e := expr.(type) {
+	case *Condition:
+		return summarizeCondition(e, label(e.Column))
+	case *Logical:
+		parts := make([]string, len(e.Exprs))
+		for i, child := range e.Exprs {
+			parts[i] = summarize(child, labels, true)
+		}
+		sentence := strings.Join(parts, " "+e.Operator+" ")
+		if nested {
+			return "(" + sentence + ")"
+		}
+		return sentence
+	case *Not:
+		return "not (" + summarize(e.Expr, labels, false) + ")"
+	case *Tuple:
+		return summarize(e.Expanded, labels, nested)
+	case *AsOf:
+		return "as of " + summaryValue(e.Time)
+	default:
+		return ""
+	}
+}
+
+func summarizeCondition(c *Condition, label string) string {
+	if c.IsNull() {
+		if c.Operator == "ne" {
+			return label + " is not empty"
+		}
+		return label + " is empty"
+	}
+
+	phrase := summaryPhrases[c.Operator]
+	switch c.Operator {
+	case "in", "nin", "band", "bor":
+		vals := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			vals[i] = summaryValue(v)
+		}
+		return fmt.Sprintf("%s %s %s", label, phrase, strings.Join(vals, ", "))
+	case "between", "nbetween":
+		return fmt.Sprintf("%s %s %s and %s", label, phrase, summaryValue(c.Values[0]), summaryValue(c.Values[1]))
+	case "from_until":
+		return fmt.Sprintf("%s %s %s until before %s", label, phrase, summaryValue(c.Values[0]), summaryValue(c.Values[1]))
+	case "within_edits":
+		return fmt.Sprintf("%s %s %s (at most %s edits away)", label, phrase, summaryValue(c.Values[0]), summaryValue(c.Values[1]))
+	default:
+		return fmt.Sprintf("%s %s %s", label, phrase, summaryValue(c.Values[0]))
+	}
+}
+
+// summaryValue formats a value for people, strings are quoted and times lose their zero parts
+func summaryValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return "'" + val + "'"
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		if h, m, s := val.Clock(); h == 0 && m == 0 && s == 0 && val.Nanosecond() == 0 {
+			return val.Format(time.DateOnly)
+		}
+		return val.Format(time.DateTime)
+	default:
+		return fmt.Sprint(val)
+	}
+}
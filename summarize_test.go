@@ -0,0 +1,26 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	labels := map[string]string{"created_at": "creation date", "status": "order status"}
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	parser := NewParser(WithClock(func() time.Time { return now }))
+
+	for filter, expected := range map[string]string{
+		`name contains "smith" and age gte 25`:                                           "name contains 'smith' and age is at least 25",
+		`status in ["paid", "sent"] or (deleted_at eq null and price between [1.5, 10])`: "order status is one of 'paid', 'sent' or (deleted_at is empty and price is between 1.5 and 10)",
+		`created_at gt "now-1d" and not (a ne null)`:                                     "creation date is greater than 2024-03-09 and not (a is not empty)",
+		`(a, b) gt [1, 2]`: "a is greater than 1 or (a is 1 and b is greater than 2)",
+	} {
+		expr, err := parser.ParseExpr(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, expected, Summarize(expr, labels), filter)
+	}
+	assert.Equal(t, "", Summarize(nil, labels))
+}
@@ -0,0 +1,97 @@
+package rqe
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Anonymize renders the canonical shape of a filter with every value replaced by `?`,
+// so it can be logged or aggregated without leaking what clients searched for
+func Anonymize(expr Expr) string {
+	switch e := expr.(type) {
+	case *Condition:
+		if e.IsNull() {
+			return e.Column + " " + e.Operator + " null"
+		}
+		return e.Column + " " + e.Operator + " ?"
+	case *Logical:
+		operands := make([]string, 0, len(e.Exprs))
+		for _, child := range flatten(e) {
+			operands = append(operands, Anonymize(child))
+		}
+		slices.Sort(operands)
+		return "(" + strings.Join(operands, " "+e.Operator+" ") + ")"
+	case *Not:
+		return notKeyword + " (" + Anonymize(e.Expr) + ")"
+	case *Tuple:
+		return "(" + strings.Join(e.Columns, ", ") + ") " + e.Operator + " ?"
+	case *AsOf:
+		return asOfKeyword + "(?)"
+	default:
+		return ""
+	}
+}
+
+// ShapeCount is how many times an anonymized filter shape was recorded
+type ShapeCount struct {
+	Shape string `json:"shape"`
+	Count int    `json:"count"`
+}
+
+// Collector records anonymized filter shapes and their frequencies, use it with
+// WithCollector to learn which filters clients run (and which columns deserve an index).
+// It is safe for concurrent use.
+type Collector struct {
+	mu         sync.Mutex
+	sampleRate float64
+	counts     map[string]int
+}
+
+// NewCollector creates a collector recording roughly sampleRate (0 to 1] of the filters
+func NewCollector(sampleRate float64) *Collector {
+	return &Collector{sampleRate: sampleRate, counts: make(map[string]int)}
+}
+
+// Record adds the shape of the expression, subject to sampling
+func (c *Collector) Record(expr Expr) {
+	if expr == nil || (c.sampleRate < 1 && rand.Float64() >= c.sampleRate) {
+		return
+	}
+	shape := Anonymize(expr)
+	c.mu.Lock()
+	c.counts[shape]++
+	c.mu.Unlock()
+}
+
+// Shapes returns the recorded shapes, most frequent first
+func (c *Collector) Shapes() []ShapeCount {
+	c.mu.Lock()
+	shapes := make([]ShapeCount, 0, len(c.counts))
+	for shape, count := range c.counts {
+		shapes = append(shapes, ShapeCount{Shape: shape, Count: count})
+	}
+	c.mu.Unlock()
+
+	slices.SortFunc(shapes, func(a, b ShapeCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Shape, b.Shape)
+	})
+	return shapes
+}
+
+// ExportJSON returns the recorded shapes as a JSON array, most frequent first
+func (c *Collector) ExportJSON() ([]byte, error) {
+	return json.Marshal(c.Shapes())
+}
+
+// Reset clears every recorded shape
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	c.counts = make(map[string]int)
+	c.mu.Unlock()
+}
@@ -0,0 +1,37 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(1)
+	parser := NewParser(WithCollector(collector))
+
+	for _, filter := range []string{
+		`name eq "john" and age gt 20`,
+		`age gt 99 and name eq "jane"`,
+		`deleted_at eq null`,
+		`bad eq`,
+	} {
+		_, _ = parser.Parse(filter, validateColumn)
+	}
+
+	assert.Equal(t, []ShapeCount{
+		{Shape: "(age gt ? and name eq ?)", Count: 2},
+		{Shape: "deleted_at eq null", Count: 1},
+	}, collector.Shapes())
+
+	out, err := collector.ExportJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"shape":"(age gt ? and name eq ?)","count":2},{"shape":"deleted_at eq null","count":1}]`, string(out))
+
+	collector.Reset()
+	assert.Empty(t, collector.Shapes())
+
+	none := NewCollector(0)
+	none.Record(mustParse(t, `a eq 1`))
+	assert.Empty(t, none.Shapes())
+}
@@ -0,0 +1,131 @@
+package rqe
+
+import (
+	"time"
+
+	"github.com/bzick/tokenizer"
+)
+
+// asOfKeyword starts the time travel construct `asof("2024-01-31")`
+const asOfKeyword = "asof"
+
+// asOfLayouts are the accepted layouts of an asof value besides relative times
+var asOfLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// Temporal describes how the filtered table keeps its history, enabling `asof(...)` filters
+type Temporal struct {
+	// SystemVersioned tables (SQL Server, MariaDB) are queried with `FOR SYSTEM_TIME AS OF ?`,
+	// the requested time is exposed on ParsedQuery.AsOf
+	SystemVersioned bool
+	// ValidFrom and ValidTo are the validity range columns of application versioned tables,
+	// `asof(t)` becomes `valid_from <= t and (valid_to > t or valid_to IS NULL)`
+	ValidFrom string
+	ValidTo   string
+}
+
+// AsOf requests the state of a system versioned table at a point in time
+type AsOf struct {
+	Time time.Time
+}
+
+func (*AsOf) expr() {}
+
+// parseAsOf parses `asof(value)`, the stream must be on the asof keyword
+func (fp *filterParser) parseAsOf() (Expr, error) {
+	stream := fp.stream
+	line, column := stream.CurrentToken().Line(), stream.CurrentToken().Offset()
+
+	if fp.temporal == nil {
+		return nil, UnexpectedTokenError{Token: asOfKeyword + " requires a temporal table", Line: line, Pos: column}
+	}
+	if fp.asOfSeen {
+		return nil, UnexpectedTokenError{Token: asOfKeyword + " can only be used once", Line: line, Pos: column}
+	}
+	fp.asOfSeen = true
+
+	stream.GoNext() // opening parenthesis, checked by the caller
+	if !stream.GoNextIfNextIs(tokenizer.TokenString) || stream.CurrentToken().StringKey() == TArray {
+		return nil, MissingValueError{Column: asOfKeyword, Line: line, Pos: column}
+	}
+	raw := stream.CurrentToken().ValueString()
+	at, ok := fp.parseTimeLiteral(raw[1 : len(raw)-1])
+	if !ok {
+		return nil, UnexpectedTokenError{Token: "invalid " + asOfKeyword + " time " + raw, Line: line, Pos: column}
+	}
+	if !stream.GoNextIfNextIs(TParenClose) {
+		return nil, UnmatchedParenthesisError{Type: "opening", Line: line, Pos: column + len(asOfKeyword)}
+	}
+	stream.GoNext()
+
+	if fp.temporal.SystemVersioned {
+		return &AsOf{Time: at}, nil
+	}
+	return &Logical{Operator: "and", Exprs: []Expr{
+		&Condition{Column: fp.temporal.ValidFrom, Operator: "lte", Values: []any{at}, Line: line, Pos: column},
+		&Logical{Operator: "or", Exprs: []Expr{
+			&Condition{Column: fp.temporal.ValidTo, Operator: "gt", Values: []any{at}, Line: line, Pos: column},
+			&Condition{Column: fp.temporal.ValidTo, Operator: "eq", Values: []any{nil}, Line: line, Pos: column},
+		}},
+	}}, nil
+}
+
+// parseTimeLiteral accepts relative times (`now-1d`) and the asOfLayouts
+func (p *Parser) parseTimeLiteral(val string) (time.Time, bool) {
+	if t, ok := p.relativeTime(val); ok {
+		return t, true
+	}
+	for _, layout := range asOfLayouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// containsAsOf reports whether an asof node is part of the expression
+func containsAsOf(expr Expr) bool {
+	switch e := expr.(type) {
+	case *AsOf:
+		return true
+	case *Not:
+		return containsAsOf(e.Expr)
+	case *Logical:
+		for _, child := range e.Exprs {
+			if containsAsOf(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractAsOf removes the asof node from the conjunction and returns its time
+func extractAsOf(expr Expr) (Expr, *time.Time) {
+	switch e := expr.(type) {
+	case *AsOf:
+		at := e.Time
+		return nil, &at
+	case *Logical:
+		var at *time.Time
+		exprs := make([]Expr, 0, len(e.Exprs))
+		for _, child := range e.Exprs {
+			rest, childAt := extractAsOf(child)
+			if childAt != nil {
+				at = childAt
+			}
+			if rest != nil {
+				exprs = append(exprs, rest)
+			}
+		}
+		switch len(exprs) {
+		case 0:
+			return nil, at
+		case 1:
+			return exprs[0], at
+		default:
+			return &Logical{Operator: e.Operator, Exprs: exprs}, at
+		}
+	default:
+		return expr, nil
+	}
+}
@@ -0,0 +1,70 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsOfSystemVersioned(t *testing.T) {
+	parser := NewParser(WithTemporal(Temporal{SystemVersioned: true}))
+	at := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	q, err := parser.Parse(`asof("2024-01-31") and name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", q.SQL)
+	assert.Equal(t, []interface{}{"x"}, q.Args)
+	assert.Equal(t, &at, q.AsOf)
+
+	q, err = parser.Parse(`asof("2024-01-31")`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "", q.SQL)
+	assert.Equal(t, &at, q.AsOf)
+
+	q, err = parser.Parse(`name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Nil(t, q.AsOf)
+
+	for _, filter := range []string{
+		`asof("2024-01-31") or name eq "x"`,
+		`asof("2024-01-31") and asof("2024-01-30")`,
+		`asof("yesterday")`,
+		`asof(5)`,
+		`asof("2024-01-31"`,
+	} {
+		_, err = parser.Parse(filter, validateColumn)
+		assert.Error(t, err, filter)
+	}
+
+	_, err = Parse(`asof("2024-01-31")`, validateColumn)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+}
+
+func TestAsOfValidityRange(t *testing.T) {
+	parser := NewParser(WithTemporal(Temporal{ValidFrom: "valid_from", ValidTo: "valid_to"}))
+	at := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	q, err := parser.Parse(`asof("2024-01-31T10:00:00Z") and asof eq 1`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(valid_from <= ? and (valid_to > ? or valid_to IS NULL)) and asof = ?", q.SQL)
+	assert.Equal(t, []interface{}{at, at, int64(1)}, q.Args)
+	assert.Nil(t, q.AsOf)
+}
+
+func TestBuilderAsOf(t *testing.T) {
+	parser := NewParser(WithTemporal(Temporal{SystemVersioned: true}))
+	at := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	q, err := parser.Begin(validateColumn).Filter(`asof("2024-01-31") and name eq "x"`).Filter(`age gt 1`).Finish()
+	assert.NoError(t, err)
+	assert.Equal(t, "(name = ?) AND (age > ?)", q.Where)
+	assert.Equal(t, &at, q.AsOf)
+
+	q, err = parser.Begin(validateColumn).Filter(`name eq "x"`).Finish()
+	assert.NoError(t, err)
+	assert.Nil(t, q.AsOf)
+
+	_, err = parser.Begin(validateColumn).Filter(`asof("2024-01-31")`).Filter(`asof("2024-01-30")`).Finish()
+	assert.IsType(t, UnexpectedTokenError{}, err)
+}
@@ -0,0 +1,50 @@
+package rqe
+
+import (
+	"time"
+)
+
+// storageLayout is how times are bound to columns stored in a time zone, a naive wall clock
+const storageLayout = "2006-01-02 15:04:05.999999999"
+
+// toStorageZone converts date and time values of the column from the client's time zone to the
+// column's storage zone (see Column.TimeZone), so `created_at gte "2024-01-02"` means midnight
+// for the client and not for the database. Values with an explicit offset keep it, anything that
+// is not a date is left untouched.
+func (p *Parser) toStorageZone(col string, vals []any) []any {
+	storage := p.schema[col].TimeZone
+	if storage == nil {
+		return vals
+	}
+	client := p.clientZone
+	if client == nil {
+		client = time.UTC
+	}
+	for i, v := range vals {
+		var t time.Time
+		switch val := v.(type) {
+		case time.Time:
+			t = val
+		case string:
+			parsed, ok := parseInZone(val, client)
+			if !ok {
+				continue
+			}
+			t = parsed
+		default:
+			continue
+		}
+		vals[i] = t.In(storage).Format(storageLayout)
+	}
+	return vals
+}
+
+// parseInZone parses the value with the asOfLayouts, layouts without an offset are read in loc
+func parseInZone(val string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range asOfLayouts {
+		if t, err := time.ParseInLocation(layout, val, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
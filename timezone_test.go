@@ -0,0 +1,39 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageTimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	p := NewParser(
+		WithClientTimeZone(newYork),
+		WithClock(func() time.Time { return now }),
+		WithSchema(Schema{
+			"created_at": {Capabilities: Filterable, TimeZone: tokyo},
+			"updated_at": {Capabilities: Filterable},
+			"name":       {Capabilities: Filterable, TimeZone: tokyo},
+		}),
+	)
+
+	q, err := p.Parse(`created_at between ["2024-01-02", "2024-01-02 18:30:00"] and created_at lt "2024-01-05T00:00:00Z"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"2024-01-02 14:00:00", "2024-01-03 08:30:00", "2024-01-05 09:00:00"}, q.Args)
+
+	q, err = p.Parse(`created_at gte "now-1h" and updated_at gte "2024-01-02" and name eq "bob"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"2024-03-01 20:00:00", "2024-01-02", "bob"}, q.Args)
+
+	// without a client zone literals are read as UTC
+	q, err = NewParser(WithSchema(Schema{"created_at": {Capabilities: Filterable, TimeZone: tokyo}})).Parse(`created_at gte "2024-01-02"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"2024-01-02 09:00:00"}, q.Args)
+}
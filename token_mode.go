@@ -0,0 +1,64 @@
+package rqe
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/bzick/tokenizer"
+)
+
+// TokenMode is how the string grammar treats tokens it does not expect, see WithTokenMode
+type TokenMode int
+
+const (
+	// StrictTokens fails on any unexpected token, input the tokenizer cannot read included. The default.
+	StrictTokens TokenMode = iota
+	// LenientTokens keeps the longest valid filter and ignores what follows it : trailing
+	// tokens, a dangling logical operation, an incomplete last condition (`a eq 1 and b`,
+	// `a eq 1 and not`), an unclosed group or input the tokenizer cannot read at all.
+	// Invalid columns and values of the kept filter are still reported, and so are syntax errors
+	// before its first complete condition.
+	LenientTokens
+)
+
+// unreadable fails on non blank filters the tokenizer produced no token for, e.g. control characters
+func (p *Parser) unreadable(filter string) error {
+	pos := strings.IndexFunc(filter, func(r rune) bool { return !unicode.IsSpace(r) })
+	if pos < 0 || p.tokenMode == LenientTokens {
+		return nil
+	}
+	r := []rune(filter[pos:])[0]
+	return UnexpectedTokenError{Token: string(r), Line: strings.Count(filter[:pos], "\n") + 1, Pos: pos}
+}
+
+// unreadableStart reports whether LenientTokens ignores the whole filter, it starts with
+// a token the grammar has no use for
+func (fp *filterParser) unreadableStart() bool {
+	return fp.tokenMode == LenientTokens && fp.stream.CurrentToken().Is(tokenizer.TokenUnknown)
+}
+
+// truncates reports whether LenientTokens drops the operand that failed with err, which is a
+// syntax error of an incomplete end of the filter. The filter parsed so far is kept.
+func (fp *filterParser) truncates(err error) bool {
+	if err == nil || fp.tokenMode != LenientTokens {
+		return false
+	}
+	switch err.(type) {
+	case UnexpectedTokenError, UnmatchedParenthesisError, MissingValueError, *LogicalTokenError:
+		fp.truncated = true
+		return true
+	}
+	return false
+}
+
+// leftover fails on the tokens the grammar did not consume
+func (fp *filterParser) leftover() error {
+	if !fp.stream.IsValid() || fp.tokenMode == LenientTokens {
+		return nil
+	}
+	tok := fp.stream.CurrentToken()
+	if tok.Is(TParenClose) {
+		return UnmatchedParenthesisError{Type: "closing", Line: tok.Line(), Pos: tok.Offset()}
+	}
+	return UnexpectedTokenError{Token: tok.ValueString(), Line: tok.Line(), Pos: tok.Offset()}
+}
@@ -0,0 +1,57 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenMode(t *testing.T) {
+	strict := NewParser()
+	lenient := NewParser(WithTokenMode(LenientTokens))
+
+	_, err := strict.Parse(`name eq "a" ; drop`, validateColumn)
+	assert.Equal(t, UnexpectedTokenError{Token: ";", Line: 1, Pos: 12}, err)
+	_, err = strict.Parse(`name eq "a" )`, validateColumn)
+	assert.Equal(t, UnmatchedParenthesisError{Type: "closing", Line: 1, Pos: 12}, err)
+	_, err = strict.Parse(`name eq "a" and`, validateColumn)
+	assert.IsType(t, &LogicalTokenError{}, err)
+	_, err = strict.Parse(" \n\x00", validateColumn)
+	assert.Equal(t, UnexpectedTokenError{Token: "\x00", Line: 2, Pos: 2}, err)
+	q, err := strict.Parse(" \t", validateColumn)
+	assert.NoError(t, err)
+	assert.Empty(t, q.SQL)
+
+	for _, filter := range []string{`name eq "a" ; drop`, `name eq "a" )`, `name eq "a" and`, `name eq "a" "b" and age gt 3`} {
+		q, err := lenient.Parse(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, "name = ?", q.SQL, filter)
+		assert.Equal(t, []any{"a"}, q.Args, filter)
+	}
+	// an incomplete last condition or an unclosed group is dropped
+	for _, filter := range []string{`name eq "a" and age`, `name eq "a" and not`, `name eq "a" and age eq`} {
+		q, err := lenient.Parse(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		assert.Equal(t, "name = ?", q.SQL, filter)
+		assert.Equal(t, []any{"a"}, q.Args, filter)
+	}
+	q, err = lenient.Parse(`name eq "a" or (age gt 3`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ? or age > ?", q.SQL)
+	q, err = lenient.Parse(`name eq "a" or (age gt 3 and`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ? or age > ?", q.SQL)
+	q, err = lenient.Parse(`name eq "a" and (age gt 3 and role`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ? and age > ?", q.SQL)
+
+	for _, filter := range []string{"\x00", "\x01", "\x01 name eq \"a\""} {
+		q, err = lenient.Parse(filter, validateColumn)
+		assert.NoError(t, err, filter)
+		assert.Empty(t, q.SQL, filter)
+	}
+
+	// errors inside the valid prefix are still reported
+	_, err = lenient.Parse(`name eq`, validateColumn)
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package rqe
+
+import (
+	"encoding/json"
+
+	"github.com/bzick/tokenizer"
+)
+
+// tupleOperators are the operations allowed on row values and their SQL form
+var tupleOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"lt":  "<",
+	"lte": "<=",
+	"gt":  ">",
+	"gte": ">=",
+	// overlaps takes a (start, end) pair of columns and a [start, end] range literal
+	"overlaps": "OVERLAPS",
+}
+
+// Tuple compares several columns at once as a row value, e.g. `(created_at, id) gt ["2024-01-01", 500]`
+// which is the building block of keyset (seek) pagination over compound sort keys.
+// A (start, end) pair can also be checked against a range with `(starts_at, ends_at) overlaps ["2024-01-01", "2024-01-07"]`.
+type Tuple struct {
+	Columns  []string
+	Operator string
+	Values   []any
+	// Expanded is the equivalent comparison written with single column conditions,
+	// for consumers without row value support
+	Expanded Expr
+	Line     int
+	Pos      int
+}
+
+func (*Tuple) expr() {}
+
+// parseTuple parses `(a, b) op [x, y]`, the stream must be on the first column
+func (fp *filterParser) parseTuple(line, column int) (Expr, error) {
+	stream := fp.stream
+	tuple := &Tuple{Line: line, Pos: column}
+
+	var positions [][2]int
+	for {
+		tok := stream.CurrentToken()
+		if !tok.IsKeyword() {
+			return nil, UnexpectedTokenError{Token: "tuple column", Line: tok.Line(), Pos: tok.Offset()}
+		}
+		if !fp.validateCol(tok.ValueString()) {
+			return nil, fp.columnError(tok.ValueString(), tok.Line(), tok.Offset())
+		}
+		tuple.Columns = append(tuple.Columns, tok.ValueString())
+		positions = append(positions, [2]int{tok.Line(), tok.Offset()})
+
+		if stream.NextToken().ValueString() != "," {
+			break
+		}
+		stream.GoNext().GoNext()
+	}
+	if !stream.GoNextIfNextIs(TParenClose) {
+		return nil, UnmatchedParenthesisError{Type: "opening", Line: line, Pos: column}
+	}
+
+	if !stream.GoNextIfNextIs(TEquality, tokenizer.TokenKeyword) {
+		return nil, UnexpectedTokenError{Token: "equality operation", Line: line, Pos: column}
+	}
+	opValue := stream.CurrentToken().ValueString()
+	tuple.Operator = fp.canonical(opValue)
+	if _, ok := tupleOperators[tuple.Operator]; !ok {
+		return nil, InvalidOperationError{Operation: opValue, Column: "tuple", Line: line, Pos: column}
+	}
+
+	if tuple.Operator == "overlaps" && len(tuple.Columns) != 2 {
+		return nil, ValueCountError{Operation: opValue, Column: "tuple", Expected: 2, Got: len(tuple.Columns), Line: line, Pos: column}
+	}
+
+	if !stream.GoNextIfNextIs(tokenizer.TokenString) || stream.CurrentToken().StringKey() != TArray {
+		return nil, MissingValueError{Column: "tuple", Line: line, Pos: column}
+	}
+	if err := json.Unmarshal([]byte(stream.CurrentToken().ValueString()), &tuple.Values); err != nil {
+		return nil, UnexpectedTokenError{Token: "invalid array argument", Line: line, Pos: column}
+	}
+	if len(tuple.Values) != len(tuple.Columns) {
+		return nil, ValueCountError{Operation: opValue, Column: "tuple", Expected: len(tuple.Columns), Got: len(tuple.Values), Line: line, Pos: column}
+	}
+	for i, col := range tuple.Columns {
+		line, column := positions[i][0], positions[i][1]
+		opName, op, err := fp.operation(col, opValue, tupleOperation(tuple.Operator, i), line, column)
+		if err != nil {
+			return nil, err
+		}
+		vals, err := fp.resolveValues(col, opValue, opName, op, []any{tuple.Values[i]}, false, line, column)
+		if err != nil {
+			return nil, err
+		}
+		tuple.Values[i] = fp.sanitizeValues(opName, op, vals)[0]
+	}
+	stream.GoNext()
+
+	tuple.Expanded = expandTuple(tuple)
+	if err := fp.conditions.add(line, column); err != nil {
+		return nil, err
+	}
+	return tuple, nil
+}
+
+// tupleOperation is the operation the i-th column of the tuple is checked with like a single
+// condition, the (start, end) columns of `overlaps` are compared with lt and gt
+func tupleOperation(operator string, i int) string {
+	if operator == "overlaps" {
+		return []string{"lt", "gt"}[i]
+	}
+	return operator
+}
+
+// overlapsDialects are the dialects with the SQL standard OVERLAPS predicate, the generic one
+// behaves as MySQL and expands it
+var overlapsDialects = map[string]struct{}{PostgresDialect.Name: {}}
+
+// rowValueDialects are the dialects comparing row values, `(a, b) > (?, ?)`. Oracle only
+// compares them for equality, SQL Server and BigQuery not at all.
+var rowValueDialects = map[string]map[string]struct{}{
+	"":                   {"eq": {}, "ne": {}, "lt": {}, "lte": {}, "gt": {}, "gte": {}},
+	PostgresDialect.Name: {"eq": {}, "ne": {}, "lt": {}, "lte": {}, "gt": {}, "gte": {}},
+	MySQLDialect.Name:    {"eq": {}, "ne": {}, "lt": {}, "lte": {}, "gt": {}, "gte": {}},
+	OracleDialect.Name:   {"eq": {}, "ne": {}},
+}
+
+// rowValue reports whether the tuple compiles to a row value comparison, it is compiled from
+// its Expanded form otherwise : when the dialect lacks the comparison, or when a column rewrites
+// its comparisons (ColumnCompiler, folding, collation, case insensitivity, digest, inline enum).
+func (p *Parser) rowValue(t *Tuple) bool {
+	if t.Operator == "overlaps" {
+		if _, ok := overlapsDialects[p.dialect.Name]; !ok {
+			return false
+		}
+	} else if _, ok := rowValueDialects[p.dialect.Name][t.Operator]; !ok {
+		return false
+	}
+	for _, col := range t.Columns {
+		if p.rewritesComparisons(col) {
+			return false
+		}
+	}
+	return true
+}
+
+// rewritesComparisons reports whether the comparisons of the column compile to more than
+// `column op ?`, which a row value comparison cannot express
+func (p *Parser) rewritesComparisons(col string) bool {
+	_, compiled := p.columnCompilers[col]
+	_, folded := p.folded[col]
+	_, digest := p.digests[col]
+	_, inline := p.inlineEnums[col]
+	schema := p.schema[col]
+	return compiled || folded || digest || inline || schema.Collation != "" || schema.CaseInsensitive
+}
+
+// expandTuple rewrites the row value comparison with single column conditions, e.g.
+// `(a, b) gt [x, y]` is `a gt x or (a eq x and b gt y)`
+func expandTuple(t *Tuple) Expr {
+	cond := func(i int, op string) Expr {
+		return &Condition{Column: t.Columns[i], Operator: op, Values: []any{t.Values[i]}, Line: t.Line, Pos: t.Pos}
+	}
+	join := func(op string, exprs []Expr) Expr {
+		if len(exprs) == 1 {
+			return exprs[0]
+		}
+		return &Logical{Operator: op, Exprs: exprs}
+	}
+
+	switch t.Operator {
+	case "overlaps":
+		// the ranges overlap when each one starts before the other ends
+		return join("and", []Expr{
+			&Condition{Column: t.Columns[0], Operator: "lt", Values: []any{t.Values[1]}, Line: t.Line, Pos: t.Pos},
+			&Condition{Column: t.Columns[1], Operator: "gt", Values: []any{t.Values[0]}, Line: t.Line, Pos: t.Pos},
+		})
+	case "eq", "ne":
+		exprs := make([]Expr, len(t.Columns))
+		for i := range t.Columns {
+			exprs[i] = cond(i, t.Operator)
+		}
+		if t.Operator == "eq" {
+			return join("and", exprs)
+		}
+		return join("or", exprs)
+	default:
+		strict := map[string]string{"lt": "lt", "lte": "lt", "gt": "gt", "gte": "gt"}[t.Operator]
+		branches := make([]Expr, len(t.Columns))
+		for i := range t.Columns {
+			op := strict
+			if i == len(t.Columns)-1 {
+				op = t.Operator
+			}
+			exprs := make([]Expr, 0, i+1)
+			for j := 0; j < i; j++ {
+				exprs = append(exprs, cond(j, "eq"))
+			}
+			branches[i] = join("and", append(exprs, cond(i, op)))
+		}
+		return join("or", branches)
+	}
+}
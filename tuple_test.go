@@ -0,0 +1,131 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTuple(t *testing.T) {
+	q, err := Parse(`(created_at, id) gt ["2024-01-01", 500] and status eq "active"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(created_at, id) > (?, ?) and status = ?", q.SQL)
+	assert.Equal(t, []interface{}{"2024-01-01", float64(500), "active"}, q.Args)
+	assert.Equal(t, []string{"created_at", "id", "status"}, q.Columns)
+
+	q, err = Parse(`(a, b, c) <= [1, 2, 3]`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(a, b, c) <= (?, ?, ?)", q.SQL)
+
+	expr := mustParse(t, `(a, b, c) gte [1, 2, 3]`)
+	expanded := NewParser().Compile(expr.(*Tuple).Expanded)
+	assert.Equal(t, "a > ? or (a = ? and b > ?) or (a = ? and b = ? and c >= ?)", expanded.SQL)
+
+	for _, filter := range []string{
+		`(a, b) gt [1]`,
+		`(a, b) in [1, 2]`,
+		`(a, b) gt 1`,
+		`(a, 5) gt [1, 2]`,
+		`(a, b gt [1, 2]`,
+	} {
+		_, err = Parse(filter, validateColumn)
+		assert.Error(t, err, filter)
+	}
+
+	_, err = Parse(`(a, secret) gt [1, 2]`, func(col string) bool { return col != "secret" })
+	assert.IsType(t, InvalidColumnError{}, err)
+}
+
+func TestTupleOverlaps(t *testing.T) {
+	q, err := Parse(`(starts_at, ends_at) overlaps ["2024-01-01", "2024-01-07"] and room eq 4`, validateColumn, Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, "(starts_at, ends_at) OVERLAPS ($1, $2) and room = $3", q.SQL)
+	assert.Equal(t, []interface{}{"2024-01-01", "2024-01-07", int64(4)}, q.Args)
+
+	expr := mustParse(t, `(starts_at, ends_at) overlaps ["2024-01-01", "2024-01-07"]`)
+	expanded := NewParser().Compile(expr.(*Tuple).Expanded)
+	assert.Equal(t, "starts_at < ? and ends_at > ?", expanded.SQL)
+	assert.Equal(t, []interface{}{"2024-01-07", "2024-01-01"}, expanded.Args)
+
+	// dialects without row values get the lexicographic comparison
+	q, err = Parse(`(a, b) gt [1, 2]`, validateColumn, MSSQL)
+	assert.NoError(t, err)
+	assert.Equal(t, "[a] > @p1 or ([a] = @p2 and [b] > @p3)", q.SQL)
+	assert.Equal(t, []interface{}{float64(1), float64(1), float64(2)}, q.Args)
+	q, err = Parse(`(a, b) eq [1, 2]`, validateColumn, Oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, `("A", "B") = (:1, :2)`, q.SQL)
+
+	// dialects without OVERLAPS get the explicit comparison, the generic one included
+	for _, dialect := range []Option{MySQL, WithDialect(Dialect{})} {
+		q, err = Parse(`(starts_at, ends_at) overlaps ["2024-01-01", "2024-01-07"]`, validateColumn, dialect)
+		assert.NoError(t, err)
+		assert.Equal(t, "starts_at < ? and ends_at > ?", q.SQL)
+		assert.Equal(t, []interface{}{"2024-01-07", "2024-01-01"}, q.Args)
+	}
+
+	for _, filter := range []string{
+		`(a, b, c) overlaps [1, 2, 3]`,
+		`(a, b) overlaps [1]`,
+		`a overlaps [1, 2]`,
+	} {
+		_, err = Parse(filter, validateColumn)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestTupleSchema(t *testing.T) {
+	p := NewParser(WithSchema(Schema{
+		"status":     {Capabilities: Filterable, Type: TypeInt, Operators: []string{"eq"}},
+		"id":         {Capabilities: Filterable, Type: TypeInt},
+		"created_at": {Capabilities: Filterable, Type: TypeDate},
+		"code":       {Capabilities: Filterable, Enum: []string{"a", "b"}},
+	}))
+
+	q, err := p.Parse(`(created_at, id) gt ["2024-01-02", 500]`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), int64(500)}, q.Args)
+
+	_, err = p.Parse(`(status, id) gt ["abc", 2]`, nil)
+	assert.Equal(t, OperatorNotAllowedError{Operation: "gt", Column: "status", Allowed: []string{"eq"}, Line: 1, Pos: 7}, err)
+	_, err = p.Parse(`(status, id) eq ["abc", 2]`, nil)
+	assert.Equal(t, InvalidValueError{Column: "status", Operation: "eq", Reason: "abc is not a valid int", Line: 1, Pos: 1}, err)
+	_, err = p.Parse(`(code, id) eq ["c", 2]`, nil)
+	assert.IsType(t, InvalidEnumValueError{}, err)
+}
+
+func TestTupleRewrittenColumns(t *testing.T) {
+	tests := map[string]struct {
+		opts     []Option
+		expected string
+	}{
+		"folded": {
+			opts:     []Option{WithFoldedColumns("name")},
+			expected: "LOWER(unaccent(name)) = LOWER(unaccent(?)) and id = ?",
+		},
+		"collation": {
+			opts:     []Option{WithSchema(Schema{"name": {Capabilities: Filterable, Collation: "utf8mb4_0900_ai_ci"}, "id": {Capabilities: Filterable}})},
+			expected: "name COLLATE utf8mb4_0900_ai_ci = ? and id = ?",
+		},
+		"case insensitive": {
+			opts:     []Option{WithSchema(Schema{"name": {Capabilities: Filterable, CaseInsensitive: true}, "id": {Capabilities: Filterable}})},
+			expected: "LOWER(name) = LOWER(?) and id = ?",
+		},
+		"digest": {
+			opts:     []Option{WithDigestColumns("name")},
+			expected: "name_md5 = ? and id = ?",
+		},
+		"inline enum": {
+			opts:     []Option{WithInlineEnum("id", 1, 2), WithSchema(Schema{"name": {Capabilities: Filterable}, "id": {Capabilities: Filterable, Type: TypeInt}})},
+			expected: "name = ? and id = 1",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := Parse(`(name, id) eq ["x", 1]`, validateColumn, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, q.SQL)
+		})
+	}
+}
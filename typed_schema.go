@@ -0,0 +1,204 @@
+package rqe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// schemaTag is the struct tag declaring a filterable field
+const schemaTag = "rqe"
+
+// tagCapabilities are the capabilities a struct tag can declare
+var tagCapabilities = map[string]ColumnCapability{
+	"filterable":  Filterable,
+	"sortable":    Sortable,
+	"searchable":  Searchable,
+	"projectable": Projectable,
+}
+
+// TypedSchema is a Schema derived from the struct tags of T, see NewSchema
+type TypedSchema[T any] struct {
+	Schema Schema
+
+	parser *Parser
+	// fields are the struct field indexes by API column
+	fields map[string][]int
+}
+
+// NewSchema builds the schema of T from its `rqe` struct tags, opts configure the parser used
+// by Parse and Match. A tag lists the field's capabilities and optionally restricts its operators
+// and SQL column, the API name is the json name of the field :
+//
+//	type User struct {
+//		Name string `json:"name" rqe:"filterable,searchable,ops=eq|in|like,column=user_name"`
+//		Age  int    `json:"age" rqe:"filterable,sortable"`
+//	}
+//
+// The rqe tag is required : fields without it are not exposed, even with a `db` or gorm tag, so
+// adding a field to a model never makes it filterable by accident. Column types are inferred from
+// the field types. The SQL column defaults to the field's `db` (sqlx) or gorm `column:` tag, `notnull` marks it NotNull,
+// `enum=a|b` restricts its values, `layouts=2006-01-02|unix` sets the accepted date layouts and
+// `collate=name` / `caseinsensitive` set its Collation / CaseInsensitive.
+func NewSchema[T any](opts ...Option) (*TypedSchema[T], error) {
+	schema, fields, err := structSchema(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	s := &TypedSchema[T]{Schema: schema, fields: fields}
+	s.parser = NewParser(append(slices.Clone(opts), WithSchema(s.Schema))...)
+	return s, nil
+}
+
+// SchemaFromStruct builds the schema of a struct (or a pointer to one) from its tags like
+// NewSchema, for code that does not need the typed Parse and Match :
+//
+//	schema, err := rqe.SchemaFromStruct(&User{})
+func SchemaFromStruct(v any) (Schema, error) {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil, InvalidSchemaError{Field: "nil", Reason: "schemas are built from structs"}
+	}
+	schema, _, err := structSchema(typ)
+	return schema, err
+}
+
+// structSchema reads the schema of the struct type with the struct field indexes by API column
+func structSchema(typ reflect.Type) (Schema, map[string][]int, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, nil, InvalidSchemaError{Field: typ.String(), Reason: "schemas are built from structs"}
+	}
+
+	schema, fields := make(Schema), make(map[string][]int)
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, ok := field.Tag.Lookup(schemaTag)
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		col := Column{Type: columnType(field.Type), DBName: dbColumn(field)}
+		for _, part := range strings.Split(tag, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "":
+			case "ops":
+				for _, op := range strings.Split(value, "|") {
+					if _, ok := operationsMapped[op]; !ok {
+						return nil, nil, InvalidSchemaError{Field: field.Name, Reason: fmt.Sprintf("unknown operator '%s'", op)}
+					}
+					col.Operators = append(col.Operators, op)
+				}
+			case "column":
+				col.DBName = value
+			case "layouts":
+				col.Layouts = strings.Split(value, "|")
+			case "collate":
+				col.Collation = value
+			case "caseinsensitive":
+				col.CaseInsensitive = true
+			case "notnull":
+				col.NotNull = true
+			case "enum":
+				col.Enum = strings.Split(value, "|")
+				if col.Type == TypeString {
+					col.Type = TypeEnum
+				}
+			default:
+				capability, ok := tagCapabilities[key]
+				if !ok {
+					return nil, nil, InvalidSchemaError{Field: field.Name, Reason: fmt.Sprintf("unknown tag option '%s'", key)}
+				}
+				col.Capabilities |= capability
+			}
+		}
+		if _, exists := schema[name]; exists {
+			return nil, nil, InvalidSchemaError{Field: field.Name, Reason: fmt.Sprintf("duplicate column '%s'", name)}
+		}
+		schema[name] = col
+		fields[name] = field.Index
+	}
+	return schema, fields, nil
+}
+
+// Parse converts the filter into a ParsedQuery against the schema
+func (s *TypedSchema[T]) Parse(filter string) (ParsedQuery, error) {
+	return s.parser.Parse(filter, nil)
+}
+
+// Match reports whether v matches the filter, see Predicate.Match
+func (s *TypedSchema[T]) Match(filter string, v *T) (bool, error) {
+	pred, err := s.parser.Predicate(filter, nil)
+	if err != nil {
+		return false, err
+	}
+	return pred.Match(s.record(v))
+}
+
+// record maps the exposed fields of v by API column, nil pointers are null
+func (s *TypedSchema[T]) record(v *T) map[string]any {
+	val := reflect.ValueOf(v).Elem()
+	record := make(map[string]any, len(s.fields))
+	for name, index := range s.fields {
+		field, err := val.FieldByIndexErr(index)
+		if err != nil {
+			continue
+		}
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		record[name] = field.Interface()
+	}
+	return record
+}
+
+// jsonName is the name of the field in its json tag, the field name otherwise
+func jsonName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// dbColumn is the SQL column of the field in its `db` or gorm `column:` tag, empty when it has none
+func dbColumn(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("db"), ","); name != "" && name != "-" {
+		return name
+	}
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(setting), "column:"); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// columnType infers the column type of a struct field
+func columnType(typ reflect.Type) ColumnType {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeFor[time.Time]() {
+		return TypeDate
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return TypeString
+	case reflect.Bool:
+		return TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeInt
+	case reflect.Float32, reflect.Float64:
+		return TypeFloat
+	default:
+		return TypeAny
+	}
+}
@@ -0,0 +1,101 @@
+package rqe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaUser struct {
+	Name      string     `json:"name" rqe:"filterable,searchable,ops=eq|in|like,column=user_name"`
+	Age       int        `json:"age" rqe:"filterable,sortable"`
+	CreatedAt time.Time  `json:"created_at" rqe:"filterable,projectable"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" rqe:"filterable"`
+	Password  string     `json:"-"`
+}
+
+func TestNewSchema(t *testing.T) {
+	s, err := NewSchema[schemaUser](Postgres)
+	assert.NoError(t, err)
+	assert.Equal(t, Schema{
+		"name":       {Capabilities: Filterable | Searchable, Type: TypeString, DBName: "user_name", Operators: []string{"eq", "in", "like"}},
+		"age":        {Capabilities: Filterable | Sortable, Type: TypeInt},
+		"created_at": {Capabilities: Filterable | Projectable, Type: TypeDate},
+		"deleted_at": {Capabilities: Filterable, Type: TypeDate},
+	}, s.Schema)
+
+	q, err := s.Parse(`name like "Jo%" and age gte 18`)
+	assert.NoError(t, err)
+	assert.Equal(t, "user_name LIKE $1 and age >= $2", q.SQL)
+
+	_, err = s.Parse(`name ne "bob"`)
+	assert.Equal(t, OperatorNotAllowedError{Operation: "ne", Column: "name", Allowed: []string{"eq", "in", "like"}, Line: 1, Pos: 4}, err)
+	assert.EqualError(t, err, "operation 'ne' is not allowed on column 'name', allowed are [eq, in, like] at line 1, offset 4")
+	_, err = s.Parse(`Password eq "x"`)
+	assert.IsType(t, InvalidColumnError{}, err)
+
+	match, err := s.Match(`name in ["John", "Jane"] and age gte 18 and deleted_at eq null`, &schemaUser{Name: "John", Age: 30})
+	assert.NoError(t, err)
+	assert.True(t, match)
+	deleted := time.Now()
+	match, err = s.Match(`deleted_at eq null`, &schemaUser{DeletedAt: &deleted})
+	assert.NoError(t, err)
+	assert.False(t, match)
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	type badOperator struct {
+		Name string `rqe:"filterable,ops=eq|equals"`
+	}
+	_, err := NewSchema[badOperator]()
+	assert.Equal(t, InvalidSchemaError{Field: "Name", Reason: "unknown operator 'equals'"}, err)
+
+	type badOption struct {
+		Name string `rqe:"indexed"`
+	}
+	_, err = NewSchema[badOption]()
+	assert.Equal(t, InvalidSchemaError{Field: "Name", Reason: "unknown tag option 'indexed'"}, err)
+
+	_, err = NewSchema[string]()
+	assert.IsType(t, InvalidSchemaError{}, err)
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	type account struct {
+		ID        int64     `json:"id" db:"account_id" rqe:"filterable,sortable,notnull"`
+		FirstName string    `json:"firstName" gorm:"column:first_name;size:64" rqe:"filterable,searchable"`
+		Email     string    `json:"email" db:"email_address" rqe:"filterable,column=contact_email"`
+		CreatedAt time.Time `json:"created_at" rqe:"filterable"`
+		Password  string    `json:"-" db:"password_hash"`
+	}
+
+	schema, err := SchemaFromStruct(&account{})
+	assert.NoError(t, err)
+	assert.Equal(t, Schema{
+		"id":         {Capabilities: Filterable | Sortable, Type: TypeInt, DBName: "account_id", NotNull: true},
+		"firstName":  {Capabilities: Filterable | Searchable, Type: TypeString, DBName: "first_name"},
+		"email":      {Capabilities: Filterable, Type: TypeString, DBName: "contact_email"},
+		"created_at": {Capabilities: Filterable, Type: TypeDate},
+	}, schema)
+
+	q, err := ParseSchema(`firstName eq "Jo" and id gt 3`, schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "first_name = ? and account_id > ?", q.SQL)
+
+	// db and gorm tags only name the column, the rqe tag exposes it
+	type dbOnly struct {
+		ID    int64  `json:"id" db:"account_id"`
+		Email string `json:"email" gorm:"column:email_address"`
+	}
+	schema, err = SchemaFromStruct(&dbOnly{})
+	assert.NoError(t, err)
+	assert.Empty(t, schema)
+	_, err = ParseSchema(`id eq 3`, schema)
+	assert.IsType(t, InvalidColumnError{}, err)
+
+	_, err = SchemaFromStruct(nil)
+	assert.IsType(t, InvalidSchemaError{}, err)
+	_, err = SchemaFromStruct(3)
+	assert.IsType(t, InvalidSchemaError{}, err)
+}
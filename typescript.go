@@ -0,0 +1,93 @@
+package rqe
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// typeScriptTypes are the TypeScript types of the column types, dates travel as ISO 8601 strings
+var typeScriptTypes = map[ColumnType]string{
+	TypeAny:    "string | number | boolean",
+	TypeString: "string",
+	TypeInt:    "number",
+	TypeFloat:  "number",
+	TypeBool:   "boolean",
+	TypeDate:   "string",
+	TypeEnum:   "string",
+}
+
+// TypeScript generates TypeScript definitions of the parser's schema so frontend query builders
+// stay in sync with the backend : the filterable fields, the operators each of them accepts
+// under the parser's dialect and capabilities, their value types and the sortable / projectable
+// fields. Every declaration is prefixed with name.
+func (p *Parser) TypeScript(name string) string {
+	columns := slices.Sorted(maps.Keys(p.schema))
+	var fields, sortable, projectable []string
+	for _, col := range columns {
+		if p.schema.filterable(col) {
+			fields = append(fields, col)
+		}
+		if p.schema.Can(col, Sortable) {
+			sortable = append(sortable, col)
+		}
+		if p.schema.Can(col, Projectable) {
+			projectable = append(projectable, col)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by rqe. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "export type %sField = %s;\n\n", name, typeScriptUnion(fields))
+
+	fmt.Fprintf(&sb, "export const %sOperators = {\n", name)
+	for _, col := range fields {
+		ops := p.columnOperations(col)
+		for i, op := range ops {
+			ops[i] = fmt.Sprintf("%q", op)
+		}
+		fmt.Fprintf(&sb, "  %q: [%s],\n", col, strings.Join(ops, ", "))
+	}
+	sb.WriteString("} as const;\n\n")
+	fmt.Fprintf(&sb, "export type %[1]sOperator<F extends %[1]sField> = (typeof %[1]sOperators)[F][number];\n\n", name)
+
+	fmt.Fprintf(&sb, "export interface %sValues {\n", name)
+	for _, col := range fields {
+		typ := typeScriptTypes[p.schema[col].Type]
+		if enum := p.schema[col].Enum; len(enum) > 0 {
+			typ = typeScriptUnion(enum)
+		}
+		fmt.Fprintf(&sb, "  %q: %s;\n", col, typ)
+	}
+	sb.WriteString("}\n\n")
+
+	fmt.Fprintf(&sb, "export type %sSortField = %s;\n", name, typeScriptUnion(sortable))
+	fmt.Fprintf(&sb, "export type %sProjectableField = %s;\n", name, typeScriptUnion(projectable))
+	return sb.String()
+}
+
+// columnOperations are the canonical operations the column accepts, sorted
+func (p *Parser) columnOperations(col string) []string {
+	ops := make([]string, 0, len(operationsMapped))
+	for _, name := range slices.Sorted(maps.Keys(operationsMapped)) {
+		op := operationsMapped[name]
+		if !p.schema.allows(col, name) || !p.dialect.supports(name) || !p.hasCapability(op.Requires) {
+			continue
+		}
+		ops = append(ops, name)
+	}
+	return ops
+}
+
+// typeScriptUnion renders the values as a union of string literals, never when empty
+func typeScriptUnion(values []string) string {
+	if len(values) == 0 {
+		return "never"
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, " | ")
+}
@@ -0,0 +1,42 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeScript(t *testing.T) {
+	p := NewParser(MySQL, WithSchema(Schema{
+		"name":   {Capabilities: Searchable | Projectable, Type: TypeString},
+		"age":    {Capabilities: Filterable | Sortable, Type: TypeInt},
+		"secret": {Capabilities: Projectable},
+	}))
+
+	assert.Equal(t, `// Code generated by rqe. DO NOT EDIT.
+
+export type UserField = "age" | "name";
+
+export const UserOperators = {
+  "age": ["band", "between", "bor", "eq", "from_until", "gt", "gte", "in", "lt", "lte", "nbetween", "ne", "nin", "nseq"],
+  "name": ["contains", "ilike", "like", "prefix", "regex", "sounds_like"],
+} as const;
+
+export type UserOperator<F extends UserField> = (typeof UserOperators)[F][number];
+
+export interface UserValues {
+  "age": number;
+  "name": string;
+}
+
+export type UserSortField = "age";
+export type UserProjectableField = "name" | "secret";
+`, p.TypeScript("User"))
+
+	// keys are quoted, qualified columns are not valid identifiers
+	assert.Contains(t, NewParser(WithSchema(Schema{"users.first_name": {Capabilities: Filterable}})).TypeScript("User"),
+		"  \"users.first_name\": string | number | boolean;\n")
+
+	// without a schema nothing is exposed
+	assert.Contains(t, NewParser().TypeScript("Empty"), `export type EmptyField = never;`)
+}
@@ -0,0 +1,77 @@
+package rqe
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// ValueValidator checks a literal of a column before it is bound, see Column.Validators
+type ValueValidator func(val any) error
+
+// MatchRegexp accepts string values matching re
+func MatchRegexp(re *regexp.Regexp) ValueValidator {
+	return func(val any) error {
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", val)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%q does not match %s", s, re)
+		}
+		return nil
+	}
+}
+
+// Between accepts numeric values within [lo, hi]
+func Between(lo, hi float64) ValueValidator {
+	return func(val any) error {
+		var f float64
+		switch v := val.(type) {
+		case int64:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			return fmt.Errorf("%v is not a number", val)
+		}
+		if f < lo || f > hi {
+			return fmt.Errorf("%v is not between %v and %v", val, lo, hi)
+		}
+		return nil
+	}
+}
+
+// MaxLength accepts string values of at most n characters
+func MaxLength(n int) ValueValidator {
+	return func(val any) error {
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", val)
+		}
+		if utf8.RuneCountInString(s) > n {
+			return fmt.Errorf("value is longer than %d characters", n)
+		}
+		return nil
+	}
+}
+
+// validateValues runs the column's Validators over the coerced values. Null values and the
+// values of the pattern operations are skipped, like in coerceValues.
+func (p *Parser) validateValues(col, opName string, vals []any, line, column int) error {
+	validators := p.schema[col].Validators
+	if _, search := searchOperations[opName]; search {
+		return nil
+	}
+	for _, v := range vals {
+		if v == nil {
+			continue
+		}
+		for _, validate := range validators {
+			if err := validate(v); err != nil {
+				return ValidationError{Column: col, Value: v, Reason: err.Error(), Line: line, Pos: column}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package rqe
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueValidators(t *testing.T) {
+	p := NewParser(WithSchema(Schema{
+		"code": {Capabilities: Filterable | Searchable, Validators: []ValueValidator{MaxLength(4), MatchRegexp(regexp.MustCompile(`^[A-Z]+$`))}},
+		"age":  {Capabilities: Filterable, Type: TypeInt, Validators: []ValueValidator{Between(0, 150)}},
+	}))
+
+	q, err := p.Parse(`code in ["AB", "CDE"] and age eq "42" and code prefix "a" and code eq null`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"AB", "CDE", int64(42), "a%"}, q.Args)
+
+	filter := `code eq "ABCDE"`
+	_, err = p.Parse(filter, nil)
+	assert.Equal(t, ValidationError{Column: "code", Value: "ABCDE", Reason: "value is longer than 4 characters", Line: 1, Pos: 0}, err)
+	assert.EqualError(t, err, "value ABCDE of column 'code' is invalid : [value is longer than 4 characters] at line 1, offset 0")
+	assert.Contains(t, err.(ValidationError).Pretty(filter), "value is longer than 4 characters")
+
+	_, err = p.Parse(`code eq "ab"`, nil)
+	assert.Equal(t, `"ab" does not match ^[A-Z]+$`, err.(ValidationError).Reason)
+
+	_, err = p.ParseJSON([]byte(`{"age": {"in": [20, 200]}}`), nil)
+	assert.Equal(t, "200 is not between 0 and 150", err.(ValidationError).Reason)
+
+	assert.Error(t, MaxLength(2)(12))
+	assert.Error(t, Between(0, 1)("1"))
+}
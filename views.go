@@ -0,0 +1,144 @@
+package rqe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bzick/tokenizer"
+)
+
+// includeKeyword invokes a saved view `include(adults, 21)`
+const includeKeyword = "include"
+
+// viewParamTypes are the types a view parameter can declare
+var viewParamTypes = map[string]bool{"int": true, "float": true, "string": true, "time": true}
+
+// ViewParam is a typed parameter of a saved view
+type ViewParam struct {
+	Name string
+	Type string
+}
+
+type view struct {
+	params []ViewParam
+	filter string
+}
+
+// Views is a registry of server curated filters that clients reuse by name with
+// `include(name, args...)`. Register every view before handing the registry to WithViews.
+type Views struct {
+	views map[string]view
+}
+
+// NewViews creates an empty view registry
+func NewViews() *Views {
+	return &Views{views: make(map[string]view)}
+}
+
+// Register adds a saved view. params declares its typed parameters in order, e.g.
+// `:min_age int, :status string`, which the filter references as `:min_age` / `:status`.
+// Supported types are int, float, string and time.
+//
+//	views.Register("adults", ":min_age int", "age gte :min_age")
+func (v *Views) Register(name string, params string, filter string) error {
+	var declared []ViewParam
+	for _, decl := range strings.Split(params, ",") {
+		decl = strings.TrimSpace(decl)
+		if decl == "" {
+			continue
+		}
+		parts := strings.Fields(decl)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], ":") || len(parts[0]) == 1 || !viewParamTypes[parts[1]] {
+			return InvalidViewError{View: name, Reason: fmt.Sprintf("invalid parameter declaration '%s'", decl)}
+		}
+		declared = append(declared, ViewParam{Name: parts[0][1:], Type: parts[1]})
+	}
+	v.views[name] = view{params: declared, filter: filter}
+	return nil
+}
+
+// parseInclude parses `include(name, args...)` and returns the view's expression with
+// its parameters bound, the stream must be on the include keyword
+func (fp *filterParser) parseInclude() (Expr, error) {
+	stream := fp.stream
+	line, column := stream.CurrentToken().Line(), stream.CurrentToken().Offset()
+
+	if fp.views == nil || fp.params != nil {
+		return nil, UnexpectedTokenError{Token: includeKeyword + " is not available", Line: line, Pos: column}
+	}
+
+	stream.GoNext() // opening parenthesis, checked by the caller
+	if !stream.GoNextIfNextIs(tokenizer.TokenKeyword) {
+		return nil, UnexpectedTokenError{Token: "view name", Line: line, Pos: column}
+	}
+	name := stream.CurrentToken().ValueString()
+	v, ok := fp.views.views[name]
+	if !ok {
+		return nil, InvalidViewError{View: name, Reason: "unknown view", Line: line, Pos: column}
+	}
+
+	args := make([]any, 0, len(v.params))
+	for stream.NextToken().ValueString() == "," {
+		stream.GoNext()
+		arg, ok := fp.parseScalar()
+		if !ok {
+			return nil, InvalidViewError{View: name, Reason: "arguments must be numbers or quoted strings", Line: line, Pos: column}
+		}
+		args = append(args, arg)
+	}
+	if !stream.GoNextIfNextIs(TParenClose) {
+		return nil, UnmatchedParenthesisError{Type: "opening", Line: line, Pos: column + len(includeKeyword)}
+	}
+	stream.GoNext()
+
+	if len(args) != len(v.params) {
+		return nil, InvalidViewError{View: name, Reason: fmt.Sprintf("expects %d arguments but got %d", len(v.params), len(args)), Line: line, Pos: column}
+	}
+	params := make(map[string]any, len(args))
+	for i, param := range v.params {
+		arg, ok := fp.coerceViewArg(param.Type, args[i])
+		if !ok {
+			return nil, InvalidViewError{View: name, Reason: fmt.Sprintf("argument :%s must be of type %s", param.Name, param.Type), Line: line, Pos: column}
+		}
+		params[param.Name] = arg
+	}
+
+	viewStream := newTokenizer().ParseString(v.filter)
+	defer viewStream.Close()
+
+	sub := &filterParser{Parser: fp.Parser, ctx: fp.ctx, stream: viewStream, validateCol: fp.validateCol, params: params, budget: fp.budget, conditions: fp.conditions, depth: fp.depth}
+	expr, err := sub.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if viewStream.IsValid() {
+		return nil, InvalidViewError{View: name, Reason: "unexpected token " + viewStream.CurrentToken().ValueString(), Line: line, Pos: column}
+	}
+	return expr, nil
+}
+
+// coerceViewArg checks the include argument against the declared parameter type
+func (p *Parser) coerceViewArg(typ string, arg any) (any, bool) {
+	switch typ {
+	case "int":
+		v, ok := arg.(int64)
+		return v, ok
+	case "float":
+		switch v := arg.(type) {
+		case float64:
+			return v, true
+		case int64:
+			return float64(v), true
+		}
+		return nil, false
+	case "time":
+		s, ok := arg.(string)
+		if !ok {
+			return nil, false
+		}
+		return p.parseTimeLiteral(s)
+	default:
+		v, ok := arg.(string)
+		return v, ok
+	}
+}
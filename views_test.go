@@ -0,0 +1,45 @@
+package rqe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViews(t *testing.T) {
+	views := NewViews()
+	assert.NoError(t, views.Register("adults", ":min_age int", "age gte :min_age"))
+	assert.NoError(t, views.Register("active_in", ":status string, :score float", `status eq :status and score gt :score`))
+	assert.NoError(t, views.Register("recent", "", `created_at gte "now-7d"`))
+	parser := NewParser(WithViews(views))
+
+	q, err := parser.Parse(`include(adults, 21) and name eq "x"`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "age >= ? and name = ?", q.SQL)
+	assert.Equal(t, []interface{}{int64(21), "x"}, q.Args)
+
+	q, err = parser.Parse(`include(active_in, "active", 2) or include(adults, 30)`, validateColumn)
+	assert.NoError(t, err)
+	assert.Equal(t, "(status = ? and score > ?) or age >= ?", q.SQL)
+	assert.Equal(t, []interface{}{"active", float64(2), int64(30)}, q.Args)
+
+	_, err = parser.Parse(`include(recent)`, validateColumn)
+	assert.NoError(t, err)
+
+	for _, filter := range []string{
+		`include(adults, "21")`,
+		`include(adults)`,
+		`include(adults, 1, 2)`,
+		`include(missing)`,
+		`include(adults, 21`,
+	} {
+		_, err = parser.Parse(filter, validateColumn)
+		assert.Error(t, err, filter)
+	}
+
+	_, err = Parse(`include(adults, 21)`, validateColumn)
+	assert.IsType(t, UnexpectedTokenError{}, err)
+
+	assert.Error(t, views.Register("bad", ":min_age integer", "age gte :min_age"))
+	assert.Error(t, views.Register("bad", "min_age int", "age gte :min_age"))
+}